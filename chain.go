@@ -8,10 +8,13 @@ import (
 	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"sort"
 
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashjson"
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
-	"github.com/btcsuite/btcd/wire"
 )
 
 // FutureGetBestBlockHashResult is a future promise to deliver the result of a
@@ -57,7 +60,7 @@ type FutureGetBlockResult chan *response
 
 // Receive waits for the response promised by the future and returns the raw
 // block requested from the server given its hash.
-func (r FutureGetBlockResult) Receive() (*wire.MsgBlock, error) {
+func (r FutureGetBlockResult) Receive() (*zcashwire.MsgBlock, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
@@ -77,7 +80,7 @@ func (r FutureGetBlockResult) Receive() (*wire.MsgBlock, error) {
 	}
 
 	// Deserialize the block and return it.
-	var msgBlock wire.MsgBlock
+	var msgBlock zcashwire.MsgBlock
 	err = msgBlock.Deserialize(bytes.NewReader(serializedBlock))
 	if err != nil {
 		return nil, err
@@ -104,7 +107,7 @@ func (c *Client) GetBlockAsync(blockHash *chainhash.Hash) FutureGetBlockResult {
 //
 // See GetBlockVerbose to retrieve a data structure with information about the
 // block instead.
-func (c *Client) GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+func (c *Client) GetBlock(blockHash *chainhash.Hash) (*zcashwire.MsgBlock, error) {
 	return c.GetBlockAsync(blockHash).Receive()
 }
 
@@ -250,6 +253,153 @@ func (c *Client) GetDifficulty() (float64, error) {
 	return c.GetDifficultyAsync().Receive()
 }
 
+// FutureGetBlockchainInfoResult is a future promise to deliver the result of a
+// GetBlockchainInfoAsync RPC invocation (or an applicable error).
+type FutureGetBlockchainInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns the state
+// of the block chain.
+func (r FutureGetBlockchainInfoResult) Receive() (*zcashjson.GetBlockchainInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getblockchaininfo result object.
+	var info zcashjson.GetBlockchainInfoResult
+	err = json.Unmarshal(res, &info)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetBlockchainInfoAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetBlockchainInfo for the blocking version and more details.
+func (c *Client) GetBlockchainInfoAsync() FutureGetBlockchainInfoResult {
+	cmd := btcjson.NewGetBlockChainInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetBlockchainInfo returns the state of the block chain, including the
+// activation heights and status of the network upgrades keyed by consensus
+// branch ID, the branch IDs of the best and next blocks and the value held by
+// each value pool.
+func (c *Client) GetBlockchainInfo() (*zcashjson.GetBlockchainInfoResult, error) {
+	return c.GetBlockchainInfoAsync().Receive()
+}
+
+// FutureGetCurrentNetResult is a future promise to deliver the result of a
+// GetCurrentNetAsync RPC invocation (or an applicable error).
+type FutureGetCurrentNetResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// parameters of the network the server is running on.
+func (r FutureGetCurrentNetResult) Receive() (*zcashcfg.Params, error) {
+	info, err := FutureGetBlockchainInfoResult(r).Receive()
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := zcashcfg.ParamsForName(info.Chain)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use the activation heights the server reports when they differ from
+	// the registered ones, such as on a regtest node started with
+	// -nuparams.
+	upgrades := make([]zcashcfg.NetworkUpgrade, 0, len(info.Upgrades))
+	for branch, upgrade := range info.Upgrades {
+		if upgrade.Status == zcashjson.UpgradeDisabled {
+			continue
+		}
+		upgrades = append(upgrades, zcashcfg.NetworkUpgrade{
+			BranchID:         branch,
+			ActivationHeight: upgrade.ActivationHeight,
+		})
+	}
+	sort.Sort(upgradesByHeight(upgrades))
+	if upgradesEqual(upgrades, params.Upgrades) {
+		return params, nil
+	}
+
+	custom := *params
+	custom.Upgrades = upgrades
+	return &custom, nil
+}
+
+// GetCurrentNetAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetCurrentNet for the blocking version and more details.
+func (c *Client) GetCurrentNetAsync() FutureGetCurrentNetResult {
+	return FutureGetCurrentNetResult(c.GetBlockchainInfoAsync())
+}
+
+// GetCurrentNet returns the parameters of the network the server is running
+// on, detected from the chain name reported by getblockchaininfo.  The
+// returned parameters are those registered with the zcashcfg package, unless
+// the server reports different network upgrade activation heights, in which
+// case a copy holding the reported heights is returned.
+func (c *Client) GetCurrentNet() (*zcashcfg.Params, error) {
+	return c.GetCurrentNetAsync().Receive()
+}
+
+// upgradeOrder is the order the network upgrades follow each other in.  Nodes
+// started with -nuparams often activate several upgrades at the same height,
+// of which the last one in this order is in effect.
+var upgradeOrder = map[zcashcfg.BranchID]int{
+	zcashcfg.BranchOverwinter: 1,
+	zcashcfg.BranchSapling:    2,
+	zcashcfg.BranchBlossom:    3,
+	zcashcfg.BranchHeartwood:  4,
+	zcashcfg.BranchCanopy:     5,
+	zcashcfg.BranchNU5:        6,
+	zcashcfg.BranchNU6:        7,
+}
+
+// upgradesByHeight implements sort.Interface to order network upgrades by
+// activation height.  Upgrades activating at the same height are ordered by
+// upgradeOrder, and unknown upgrades follow the known ones in the order of
+// their branch IDs.
+type upgradesByHeight []zcashcfg.NetworkUpgrade
+
+func (s upgradesByHeight) Len() int      { return len(s) }
+func (s upgradesByHeight) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s upgradesByHeight) Less(i, j int) bool {
+	if s[i].ActivationHeight != s[j].ActivationHeight {
+		return s[i].ActivationHeight < s[j].ActivationHeight
+	}
+	oi, knownI := upgradeOrder[s[i].BranchID]
+	oj, knownJ := upgradeOrder[s[j].BranchID]
+	switch {
+	case knownI && knownJ:
+		return oi < oj
+	case knownI != knownJ:
+		return knownI
+	}
+	return s[i].BranchID < s[j].BranchID
+}
+
+// upgradesEqual returns whether the two lists schedule the same network
+// upgrades at the same heights.
+func upgradesEqual(a, b []zcashcfg.NetworkUpgrade) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // FutureGetBlockHashResult is a future promise to deliver the result of a
 // GetBlockHashAsync RPC invocation (or an applicable error).
 type FutureGetBlockHashResult chan *response
@@ -293,7 +443,7 @@ type FutureGetBlockHeaderResult chan *response
 
 // Receive waits for the response promised by the future and returns the
 // blockheader requested from the server given its hash.
-func (r FutureGetBlockHeaderResult) Receive() (*wire.BlockHeader, error) {
+func (r FutureGetBlockHeaderResult) Receive() (*zcashwire.BlockHeader, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
@@ -312,7 +462,7 @@ func (r FutureGetBlockHeaderResult) Receive() (*wire.BlockHeader, error) {
 	}
 
 	// Deserialize the blockheader and return it.
-	var bh wire.BlockHeader
+	var bh zcashwire.BlockHeader
 	err = bh.Deserialize(bytes.NewReader(serializedBH))
 	if err != nil {
 		return nil, err
@@ -340,7 +490,7 @@ func (c *Client) GetBlockHeaderAsync(blockHash *chainhash.Hash) FutureGetBlockHe
 //
 // See GetBlockHeaderVerbose to retrieve a data structure with information about the
 // block instead.
-func (c *Client) GetBlockHeader(blockHash *chainhash.Hash) (*wire.BlockHeader, error) {
+func (c *Client) GetBlockHeader(blockHash *chainhash.Hash) (*zcashwire.BlockHeader, error) {
 	return c.GetBlockHeaderAsync(blockHash).Receive()
 }
 
@@ -0,0 +1,205 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashrpcclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashjson"
+)
+
+// testRPCHandler answers a JSON-RPC request of a test server with its result.
+// Returning an error answers it with an RPC error instead.
+type testRPCHandler func(method string, params []json.RawMessage) (interface{}, error)
+
+// newTestClient returns a client in HTTP POST mode connected to a test server
+// answering its requests with the passed handler, along with a function
+// shutting both down.
+func newTestClient(t *testing.T, handler testRPCHandler) (*Client, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+			ID     interface{}       `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		reply := map[string]interface{}{"id": req.ID}
+		result, err := handler(req.Method, req.Params)
+		if err != nil {
+			reply["result"] = nil
+			reply["error"] = map[string]interface{}{
+				"code":    -1,
+				"message": err.Error(),
+			}
+		} else {
+			reply["result"] = result
+			reply["error"] = nil
+		}
+		json.NewEncoder(w).Encode(reply)
+	}))
+
+	client, err := New(&ConnConfig{
+		Host:         strings.TrimPrefix(server.URL, "http://"),
+		User:         "user",
+		Pass:         "pass",
+		HTTPPostMode: true,
+		DisableTLS:   true,
+	}, nil)
+	if err != nil {
+		server.Close()
+		t.Fatalf("New: %v", err)
+	}
+	return client, func() {
+		client.Shutdown()
+		client.WaitForShutdown()
+		server.Close()
+	}
+}
+
+// nu returns the activation of the network upgrade at the passed height.
+func nu(branch zcashcfg.BranchID, height int32) zcashcfg.NetworkUpgrade {
+	return zcashcfg.NetworkUpgrade{
+		BranchID:         branch,
+		ActivationHeight: height,
+	}
+}
+
+func TestGetCurrentNet(t *testing.T) {
+	// All upgrades activating at the same height, as regtest nodes are
+	// commonly started with, are ordered the way they follow each other.
+	all := []zcashcfg.NetworkUpgrade{
+		nu(zcashcfg.BranchOverwinter, 1),
+		nu(zcashcfg.BranchSapling, 1),
+		nu(zcashcfg.BranchBlossom, 1),
+		nu(zcashcfg.BranchHeartwood, 1),
+		nu(zcashcfg.BranchCanopy, 1),
+		nu(zcashcfg.BranchNU5, 1),
+		nu(zcashcfg.BranchNU6, 1),
+	}
+
+	tests := []struct {
+		name     string
+		chain    string
+		upgrades []zcashcfg.NetworkUpgrade
+		disabled []zcashcfg.NetworkUpgrade
+		want     []zcashcfg.NetworkUpgrade
+		branches map[int32]zcashcfg.BranchID
+	}{
+		{
+			name:     "regtest at height 1",
+			chain:    "regtest",
+			upgrades: all,
+			want:     all,
+			branches: map[int32]zcashcfg.BranchID{
+				0: zcashcfg.BranchSprout,
+				1: zcashcfg.BranchNU6,
+				9: zcashcfg.BranchNU6,
+			},
+		},
+		{
+			name:  "regtest staggered",
+			chain: "regtest",
+			upgrades: []zcashcfg.NetworkUpgrade{
+				nu(zcashcfg.BranchNU5, 10),
+				nu(zcashcfg.BranchCanopy, 5),
+				nu(zcashcfg.BranchOverwinter, 1),
+				nu(zcashcfg.BranchSapling, 1),
+				nu(zcashcfg.BranchBlossom, 1),
+				nu(zcashcfg.BranchHeartwood, 5),
+			},
+			disabled: []zcashcfg.NetworkUpgrade{
+				nu(zcashcfg.BranchNU6, 0),
+			},
+			want: []zcashcfg.NetworkUpgrade{
+				nu(zcashcfg.BranchOverwinter, 1),
+				nu(zcashcfg.BranchSapling, 1),
+				nu(zcashcfg.BranchBlossom, 1),
+				nu(zcashcfg.BranchHeartwood, 5),
+				nu(zcashcfg.BranchCanopy, 5),
+				nu(zcashcfg.BranchNU5, 10),
+			},
+			branches: map[int32]zcashcfg.BranchID{
+				1:  zcashcfg.BranchBlossom,
+				5:  zcashcfg.BranchCanopy,
+				9:  zcashcfg.BranchCanopy,
+				10: zcashcfg.BranchNU5,
+			},
+		},
+		{
+			name:     "main",
+			chain:    "main",
+			upgrades: zcashcfg.MainNetParams.Upgrades,
+			want:     zcashcfg.MainNetParams.Upgrades,
+			branches: map[int32]zcashcfg.BranchID{
+				1687103: zcashcfg.BranchCanopy,
+				1687104: zcashcfg.BranchNU5,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		info := zcashjson.GetBlockchainInfoResult{
+			Chain:    test.chain,
+			Upgrades: make(map[zcashcfg.BranchID]zcashjson.NetworkUpgradeResult),
+		}
+		for _, upgrade := range test.upgrades {
+			info.Upgrades[upgrade.BranchID] = zcashjson.NetworkUpgradeResult{
+				Name:             upgrade.BranchID.String(),
+				ActivationHeight: upgrade.ActivationHeight,
+				Status:           zcashjson.UpgradeActive,
+			}
+		}
+		for _, upgrade := range test.disabled {
+			info.Upgrades[upgrade.BranchID] = zcashjson.NetworkUpgradeResult{
+				Name:   upgrade.BranchID.String(),
+				Status: zcashjson.UpgradeDisabled,
+			}
+		}
+		client, done := newTestClient(t, func(string, []json.RawMessage) (interface{}, error) {
+			return &info, nil
+		})
+
+		// The upgrades are decoded from a map, whose order varies, so
+		// the parameters are requested several times.
+		for i := 0; i < 20; i++ {
+			params, err := client.GetCurrentNet()
+			if err != nil {
+				t.Fatalf("%s: GetCurrentNet: %v", test.name, err)
+			}
+			if params.Name != test.chain {
+				t.Fatalf("%s: got network %s, want %s", test.name,
+					params.Name, test.chain)
+			}
+			if !upgradesEqual(params.Upgrades, test.want) {
+				t.Fatalf("%s: got upgrades %v, want %v", test.name,
+					params.Upgrades, test.want)
+			}
+			for height, want := range test.branches {
+				if got := params.BranchID(height); got != want {
+					t.Fatalf("%s: got branch %v at height %d, "+
+						"want %v", test.name, got, height,
+						want)
+				}
+			}
+			if test.chain == "main" && params != &zcashcfg.MainNetParams {
+				t.Fatalf("%s: got a copy of the registered "+
+					"parameters", test.name)
+			}
+		}
+		done()
+	}
+
+	if zcashcfg.RegressionNetParams.Upgrades != nil {
+		t.Errorf("the registered regtest parameters were modified")
+	}
+}
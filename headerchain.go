@@ -0,0 +1,101 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashrpcclient
+
+import (
+	"fmt"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashchain"
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// headerBatchSize is the number of block hashes and headers requested at once
+// by ValidateHeaderChain.
+const headerBatchSize = 100
+
+// ValidateHeaderChain fetches the headers of the blocks of the best chain of
+// the server from startHeight through endHeight and validates them as a
+// zcashchain.HeaderChain of the passed network.  The headers preceding the
+// range that the difficulty adjustment depends on are fetched and validated
+// as well, so the linkage, proof-of-work, timestamp and difficulty of every
+// header in the range is checked.  The hash of each header is also checked to
+// be the hash the server reports for its height.
+//
+// Validation stops at the first inconsistency, which is returned as a
+// zcashchain.RuleError along with the headers of the range validated before
+// it.  Note that a chain reorganization while the headers are fetched is also
+// reported as an inconsistency, so the validation should be retried before
+// concluding the server is misbehaving.
+func (c *Client) ValidateHeaderChain(net *zcashcfg.Params, startHeight, endHeight int32) ([]*zcashwire.BlockHeader, error) {
+	if startHeight < 0 || endHeight < startHeight {
+		return nil, fmt.Errorf("invalid height range %d to %d",
+			startHeight, endHeight)
+	}
+
+	from := startHeight - zcashchain.DifficultyContext(net)
+	if from < 0 {
+		from = 0
+	}
+	chain := zcashchain.NewHeaderChain(net, from)
+	headers := make([]*zcashwire.BlockHeader, 0, endHeight-startHeight+1)
+
+	for batchStart := from; batchStart <= endHeight; batchStart += headerBatchSize {
+		batchEnd := batchStart + headerBatchSize - 1
+		if batchEnd > endHeight {
+			batchEnd = endHeight
+		}
+
+		// Request the hashes of the batch at once, followed by their
+		// headers.
+		hashFutures := make([]FutureGetBlockHashResult, 0,
+			batchEnd-batchStart+1)
+		for height := batchStart; height <= batchEnd; height++ {
+			hashFutures = append(hashFutures,
+				c.GetBlockHashAsync(int64(height)))
+		}
+		hashes := make([]*chainhash.Hash, 0, len(hashFutures))
+		for _, f := range hashFutures {
+			hash, err := f.Receive()
+			if err != nil {
+				return headers, err
+			}
+			hashes = append(hashes, hash)
+		}
+		headerFutures := make([]FutureGetBlockHeaderResult, 0,
+			len(hashes))
+		for _, hash := range hashes {
+			headerFutures = append(headerFutures,
+				c.GetBlockHeaderAsync(hash))
+		}
+
+		for i, f := range headerFutures {
+			header, err := f.Receive()
+			if err != nil {
+				return headers, err
+			}
+
+			height := batchStart + int32(i)
+			if hash := header.BlockHash(); hash != *hashes[i] {
+				str := fmt.Sprintf("block %d header hashes to %v "+
+					"instead of the reported %v", height, hash,
+					hashes[i])
+				return headers, zcashchain.RuleError{
+					ErrorCode:   zcashchain.ErrHashMismatch,
+					Description: str,
+				}
+			}
+			if err := chain.Connect(header); err != nil {
+				return headers, err
+			}
+			if height >= startHeight {
+				headers = append(headers, header)
+			}
+		}
+	}
+
+	return headers, nil
+}
@@ -7,6 +7,7 @@ package zcashrpcclient
 import (
 	"bytes"
 	"container/list"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
@@ -101,6 +102,10 @@ type jsonRequest struct {
 	cmd            interface{}
 	marshalledJSON []byte
 	responseChan   chan *response
+
+	// cancel aborts the HTTP request issuing the command in HTTP POST
+	// mode.
+	cancel context.CancelFunc
 }
 
 // Client represents a Bitcoin RPC client which allows easy access to the
@@ -692,7 +697,7 @@ func (c *Client) handleSendPostMessage(details *sendPostDetails) {
 	log.Tracef("Sending command [%s] with id %d", jReq.method, jReq.id)
 	httpResponse, err := c.httpClient.Do(details.httpRequest)
 	if err != nil {
-		jReq.responseChan <- &response{err: err}
+		c.respondPost(jReq, &response{err: err})
 		return
 	}
 
@@ -701,7 +706,7 @@ func (c *Client) handleSendPostMessage(details *sendPostDetails) {
 	httpResponse.Body.Close()
 	if err != nil {
 		err = fmt.Errorf("error reading json reply: %v", err)
-		jReq.responseChan <- &response{err: err}
+		c.respondPost(jReq, &response{err: err})
 		return
 	}
 
@@ -714,12 +719,22 @@ func (c *Client) handleSendPostMessage(details *sendPostDetails) {
 		// response bytes.
 		err = fmt.Errorf("status code: %d, response: %q",
 			httpResponse.StatusCode, string(respBytes))
-		jReq.responseChan <- &response{err: err}
+		c.respondPost(jReq, &response{err: err})
 		return
 	}
 
 	res, err := resp.result()
-	jReq.responseChan <- &response{result: res, err: err}
+	c.respondPost(jReq, &response{result: res, err: err})
+}
+
+// respondPost delivers the response to a request issued in HTTP POST mode,
+// unless the request was already answered because it was cancelled or the
+// client was shut down.
+func (c *Client) respondPost(jReq *jsonRequest, r *response) {
+	jReq.cancel()
+	if c.removeRequest(jReq.id) != nil {
+		jReq.responseChan <- r
+	}
 }
 
 // sendPostHandler handles all outgoing messages when the client is running
@@ -746,10 +761,10 @@ cleanup:
 	for {
 		select {
 		case details := <-c.sendPostChan:
-			details.jsonRequest.responseChan <- &response{
+			c.respondPost(details.jsonRequest, &response{
 				result: nil,
 				err:    ErrClientShutdown,
-			}
+			})
 
 		default:
 			break cleanup
@@ -767,7 +782,8 @@ func (c *Client) sendPostRequest(httpReq *http.Request, jReq *jsonRequest) {
 	// Don't send the message if shutting down.
 	select {
 	case <-c.shutdown:
-		jReq.responseChan <- &response{result: nil, err: ErrClientShutdown}
+		c.respondPost(jReq, &response{result: nil, err: ErrClientShutdown})
+		return
 	default:
 	}
 
@@ -786,6 +802,73 @@ func newFutureError(err error) chan *response {
 	return responseChan
 }
 
+// WaitForResponse blocks until the response promised by the passed future is
+// received, after which the Receive function of the future returns it without
+// blocking.  The future may be any of the Future types returned by the Async
+// functions, such as FutureGetBlockCountResult, which all share the unexported
+// channel type of the parameter.  For example:
+//
+//	future := client.GetBlockCountAsync()
+//	if err := client.WaitForResponse(ctx, future); err != nil {
+//		return err
+//	}
+//	count, err := future.Receive()
+//
+// If the context is done before the response is received, the context's error
+// is returned and the request is cancelled.  A cancelled request is no longer
+// waited on, and in HTTP POST mode its HTTP request is aborted.  Receive then
+// returns the context's error as well, unless the response was already being
+// delivered when the request was cancelled, in which case Receive returns the
+// response.
+func (c *Client) WaitForResponse(ctx context.Context, future chan *response) error {
+	select {
+	case r := <-future:
+		// Put the response back for Receive.  The channel is
+		// buffered and only ever sent a single response.
+		future <- r
+		return nil
+
+	case <-ctx.Done():
+		// Prefer a response that arrived at the same time.
+		select {
+		case r := <-future:
+			future <- r
+			return nil
+		default:
+		}
+		c.cancelRequest(future, ctx.Err())
+		return ctx.Err()
+	}
+}
+
+// cancelRequest stops waiting for the reply to the request with the passed
+// response channel and answers it with the passed error.  In HTTP POST mode,
+// the HTTP request is aborted.  Requests that were already answered are left
+// untouched.
+func (c *Client) cancelRequest(responseChan chan *response, err error) {
+	var jReq *jsonRequest
+	c.requestLock.Lock()
+	for e := c.requestList.Front(); e != nil; e = e.Next() {
+		req := e.Value.(*jsonRequest)
+		if req.responseChan == responseChan {
+			jReq = req
+			delete(c.requestMap, req.id)
+			c.requestList.Remove(e)
+			break
+		}
+	}
+	c.requestLock.Unlock()
+	if jReq == nil {
+		return
+	}
+
+	log.Tracef("Cancelled command [%s] with id %d", jReq.method, jReq.id)
+	if jReq.cancel != nil {
+		jReq.cancel()
+	}
+	jReq.responseChan <- &response{err: err}
+}
+
 // receiveFuture receives from the passed futureResult channel to extract a
 // reply or any errors.  The examined errors include an error in the
 // futureResult and the error in the reply from the server.  This will block
@@ -820,6 +903,17 @@ func (c *Client) sendPost(jReq *jsonRequest) {
 	// Configure basic access authorization.
 	httpReq.SetBasicAuth(c.config.User, c.config.Pass)
 
+	// Track the request like those sent via websockets, so it is answered
+	// on shutdown and can be cancelled, which aborts the HTTP request.
+	ctx, cancel := context.WithCancel(context.Background())
+	httpReq = httpReq.WithContext(ctx)
+	jReq.cancel = cancel
+	if err := c.addRequest(jReq); err != nil {
+		cancel()
+		jReq.responseChan <- &response{err: err}
+		return
+	}
+
 	log.Tracef("Sending command [%s] with id %d", jReq.method, jReq.id)
 	c.sendPostRequest(httpReq, jReq)
 }
@@ -1002,9 +1096,13 @@ func (c *Client) Shutdown() {
 		return
 	}
 
-	// Send the ErrClientShutdown error to any pending requests.
+	// Send the ErrClientShutdown error to any pending requests, aborting
+	// those issued via HTTP POST.
 	for e := c.requestList.Front(); e != nil; e = e.Next() {
 		req := e.Value.(*jsonRequest)
+		if req.cancel != nil {
+			req.cancel()
+		}
 		req.responseChan <- &response{
 			result: nil,
 			err:    ErrClientShutdown,
@@ -1025,6 +1123,14 @@ func (c *Client) start() {
 	if c.config.HTTPPostMode {
 		c.wg.Add(1)
 		go c.sendPostHandler()
+		if c.ntfnHandlers != nil {
+			c.wg.Add(1)
+			if len(c.config.ZMQHosts) != 0 {
+				go c.zmqHandler()
+			} else {
+				go c.pollHandler()
+			}
+		}
 	} else {
 		c.wg.Add(3)
 		go func() {
@@ -1106,6 +1212,23 @@ type ConnConfig struct {
 	// flag can be set to true to use basic HTTP POST requests instead.
 	HTTPPostMode bool
 
+	// PollInterval enables the emulation of the block and transaction
+	// notifications in HTTP POST mode, where the server can not push them.
+	// When it is non-zero, the server is polled at this interval and the
+	// differences are delivered to the OnBlockConnected,
+	// OnBlockDisconnected, OnTxAccepted, OnTxAcceptedVerbose, and OnRecvTx
+	// notification handlers.  It has no effect in websocket mode.
+	PollInterval time.Duration
+
+	// ZMQHosts are the host:port addresses zcashd publishes block and
+	// transaction events to over ZeroMQ, as configured with its
+	// -zmqpubhashblock, -zmqpubhashtx, -zmqpubrawblock, and -zmqpubrawtx
+	// options.  When set in HTTP POST mode, the notification handlers
+	// supported by PollInterval are instead driven by the published
+	// events, and the server is only polled at PollInterval, if set, as a
+	// safeguard.  It has no effect in websocket mode.
+	ZMQHosts []string
+
 	// EnableBCInfoHacks is an option provided to enable compatiblity hacks
 	// when connecting to blockchain.info RPC server
 	EnableBCInfoHacks bool
@@ -1216,17 +1339,20 @@ func dial(config *ConnConfig) (*websocket.Conn, error) {
 // New creates a new RPC client based on the provided connection configuration
 // details.  The notification handlers parameter may be nil if you are not
 // interested in receiving notifications and will be ignored if the
-// configuration is set to run in HTTP POST mode.
+// configuration is set to run in HTTP POST mode without a poll interval or
+// ZMQ hosts.
 func New(config *ConnConfig, ntfnHandlers *NotificationHandlers) (*Client, error) {
 	// Either open a websocket connection or create an HTTP client depending
 	// on the HTTP POST mode.  Also, set the notification handlers to nil
-	// when running in HTTP POST mode.
+	// when running in HTTP POST mode without a source of notifications.
 	var wsConn *websocket.Conn
 	var httpClient *http.Client
 	connEstablished := make(chan struct{})
 	var start bool
 	if config.HTTPPostMode {
-		ntfnHandlers = nil
+		if config.PollInterval <= 0 && len(config.ZMQHosts) == 0 {
+			ntfnHandlers = nil
+		}
 		start = true
 
 		var err error
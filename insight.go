@@ -0,0 +1,395 @@
+// Copyright (c) 2016 arithmetric
+// Based on btcrpcclient by the btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashrpcclient
+
+import (
+	"encoding/json"
+
+	"github.com/arithmetric/zcashrpcclient/zcashjson"
+	"github.com/arithmetric/zcashrpcclient/zcashutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// NOTE: The RPCs in this file are only available from nodes run with the
+// -insightexplorer option, which maintains the address and spent indexes.
+
+// encodeAddresses returns the encoded form of the passed addresses.
+func encodeAddresses(addrs []zcashutil.Address) []string {
+	addrStrs := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		addrStrs = append(addrStrs, a.EncodeAddress())
+	}
+	return addrStrs
+}
+
+// FutureGetAddressBalanceResult is a future promise to deliver the result of a
+// GetAddressBalanceAsync RPC invocation (or an applicable error).
+type FutureGetAddressBalanceResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// balance of the addresses.
+func (r FutureGetAddressBalanceResult) Receive() (*zcashjson.GetAddressBalanceResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getaddressbalance result object.
+	var balance zcashjson.GetAddressBalanceResult
+	err = json.Unmarshal(res, &balance)
+	if err != nil {
+		return nil, err
+	}
+
+	return &balance, nil
+}
+
+// GetAddressBalanceAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressBalance for the blocking version and more details.
+func (c *Client) GetAddressBalanceAsync(addrs []zcashutil.Address) FutureGetAddressBalanceResult {
+	cmd := zcashjson.NewGetAddressBalanceCmd(encodeAddresses(addrs))
+	return c.sendCmd(cmd)
+}
+
+// GetAddressBalance returns the combined confirmed balance of the passed
+// transparent addresses and the total they have received, in zatoshis.
+func (c *Client) GetAddressBalance(addrs []zcashutil.Address) (*zcashjson.GetAddressBalanceResult, error) {
+	return c.GetAddressBalanceAsync(addrs).Receive()
+}
+
+// FutureGetAddressUtxosResult is a future promise to deliver the result of a
+// GetAddressUtxosAsync RPC invocation (or an applicable error).
+type FutureGetAddressUtxosResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// unspent outputs of the addresses.
+func (r FutureGetAddressUtxosResult) Receive() ([]zcashjson.AddressUtxo, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of getaddressutxos result objects.
+	var utxos []zcashjson.AddressUtxo
+	err = json.Unmarshal(res, &utxos)
+	if err != nil {
+		return nil, err
+	}
+
+	return utxos, nil
+}
+
+// GetAddressUtxosAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressUtxos for the blocking version and more details.
+func (c *Client) GetAddressUtxosAsync(addrs []zcashutil.Address) FutureGetAddressUtxosResult {
+	cmd := zcashjson.NewGetAddressUtxosCmd(encodeAddresses(addrs), nil)
+	return c.sendCmd(cmd)
+}
+
+// GetAddressUtxos returns the confirmed unspent outputs of the passed
+// transparent addresses.
+func (c *Client) GetAddressUtxos(addrs []zcashutil.Address) ([]zcashjson.AddressUtxo, error) {
+	return c.GetAddressUtxosAsync(addrs).Receive()
+}
+
+// FutureGetAddressUtxosChainInfoResult is a future promise to deliver the
+// result of a GetAddressUtxosChainInfoAsync RPC invocation (or an applicable
+// error).
+type FutureGetAddressUtxosChainInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// unspent outputs of the addresses along with the best block they were read
+// at.
+func (r FutureGetAddressUtxosChainInfoResult) Receive() (*zcashjson.GetAddressUtxosResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getaddressutxos result object.
+	var utxos zcashjson.GetAddressUtxosResult
+	err = json.Unmarshal(res, &utxos)
+	if err != nil {
+		return nil, err
+	}
+
+	return &utxos, nil
+}
+
+// GetAddressUtxosChainInfoAsync returns an instance of a type that can be used
+// to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetAddressUtxosChainInfo for the blocking version and more details.
+func (c *Client) GetAddressUtxosChainInfoAsync(addrs []zcashutil.Address) FutureGetAddressUtxosChainInfoResult {
+	chainInfo := true
+	cmd := zcashjson.NewGetAddressUtxosCmd(encodeAddresses(addrs), &chainInfo)
+	return c.sendCmd(cmd)
+}
+
+// GetAddressUtxosChainInfo returns the confirmed unspent outputs of the passed
+// transparent addresses along with the hash and height of the best block they
+// were read at.
+func (c *Client) GetAddressUtxosChainInfo(addrs []zcashutil.Address) (*zcashjson.GetAddressUtxosResult, error) {
+	return c.GetAddressUtxosChainInfoAsync(addrs).Receive()
+}
+
+// FutureGetAddressDeltasResult is a future promise to deliver the result of a
+// GetAddressDeltasAsync or GetAddressDeltasRangeAsync RPC invocation (or an
+// applicable error).
+type FutureGetAddressDeltasResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// balance changes of the addresses.
+func (r FutureGetAddressDeltasResult) Receive() ([]zcashjson.AddressDelta, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of getaddressdeltas result objects.
+	var deltas []zcashjson.AddressDelta
+	err = json.Unmarshal(res, &deltas)
+	if err != nil {
+		return nil, err
+	}
+
+	return deltas, nil
+}
+
+// GetAddressDeltasAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressDeltas for the blocking version and more details.
+func (c *Client) GetAddressDeltasAsync(addrs []zcashutil.Address) FutureGetAddressDeltasResult {
+	cmd := zcashjson.NewGetAddressDeltasCmd(encodeAddresses(addrs), nil, nil,
+		nil)
+	return c.sendCmd(cmd)
+}
+
+// GetAddressDeltas returns every confirmed change in the balance of the passed
+// transparent addresses.
+func (c *Client) GetAddressDeltas(addrs []zcashutil.Address) ([]zcashjson.AddressDelta, error) {
+	return c.GetAddressDeltasAsync(addrs).Receive()
+}
+
+// GetAddressDeltasRangeAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetAddressDeltasRange for the blocking version and more details.
+func (c *Client) GetAddressDeltasRangeAsync(addrs []zcashutil.Address, start, end int) FutureGetAddressDeltasResult {
+	cmd := zcashjson.NewGetAddressDeltasCmd(encodeAddresses(addrs), &start,
+		&end, nil)
+	return c.sendCmd(cmd)
+}
+
+// GetAddressDeltasRange returns the changes in the balance of the passed
+// transparent addresses made by blocks from the start height to the end
+// height, inclusive.
+func (c *Client) GetAddressDeltasRange(addrs []zcashutil.Address, start, end int) ([]zcashjson.AddressDelta, error) {
+	return c.GetAddressDeltasRangeAsync(addrs, start, end).Receive()
+}
+
+// FutureGetAddressDeltasChainInfoResult is a future promise to deliver the
+// result of a GetAddressDeltasChainInfoAsync RPC invocation (or an applicable
+// error).
+type FutureGetAddressDeltasChainInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// balance changes of the addresses along with the blocks at the ends of the
+// height range.
+func (r FutureGetAddressDeltasChainInfoResult) Receive() (*zcashjson.GetAddressDeltasResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getaddressdeltas result object.
+	var deltas zcashjson.GetAddressDeltasResult
+	err = json.Unmarshal(res, &deltas)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deltas, nil
+}
+
+// GetAddressDeltasChainInfoAsync returns an instance of a type that can be
+// used to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetAddressDeltasChainInfo for the blocking version and more details.
+func (c *Client) GetAddressDeltasChainInfoAsync(addrs []zcashutil.Address, start, end int) FutureGetAddressDeltasChainInfoResult {
+	chainInfo := true
+	cmd := zcashjson.NewGetAddressDeltasCmd(encodeAddresses(addrs), &start,
+		&end, &chainInfo)
+	return c.sendCmd(cmd)
+}
+
+// GetAddressDeltasChainInfo returns the changes in the balance of the passed
+// transparent addresses made by blocks from the start height to the end
+// height, inclusive, along with the hashes of the blocks at both ends.  The
+// hashes allow callers to detect a reorganization between requests.
+func (c *Client) GetAddressDeltasChainInfo(addrs []zcashutil.Address, start, end int) (*zcashjson.GetAddressDeltasResult, error) {
+	return c.GetAddressDeltasChainInfoAsync(addrs, start, end).Receive()
+}
+
+// FutureGetAddressTxIDsResult is a future promise to deliver the result of a
+// GetAddressTxIDsAsync or GetAddressTxIDsRangeAsync RPC invocation (or an
+// applicable error).
+type FutureGetAddressTxIDsResult chan *response
+
+// Receive waits for the response promised by the future and returns the hashes
+// of the transactions involving the addresses.
+func (r FutureGetAddressTxIDsResult) Receive() ([]*chainhash.Hash, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal the result as an array of strings.
+	var txHashStrs []string
+	err = json.Unmarshal(res, &txHashStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a slice of hashes from the string slice.
+	txHashes := make([]*chainhash.Hash, 0, len(txHashStrs))
+	for _, hashStr := range txHashStrs {
+		txHash, err := chainhash.NewHashFromStr(hashStr)
+		if err != nil {
+			return nil, err
+		}
+		txHashes = append(txHashes, txHash)
+	}
+
+	return txHashes, nil
+}
+
+// GetAddressTxIDsAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressTxIDs for the blocking version and more details.
+func (c *Client) GetAddressTxIDsAsync(addrs []zcashutil.Address) FutureGetAddressTxIDsResult {
+	cmd := zcashjson.NewGetAddressTxIDsCmd(encodeAddresses(addrs), nil, nil)
+	return c.sendCmd(cmd)
+}
+
+// GetAddressTxIDs returns the hashes of the confirmed transactions involving
+// the passed transparent addresses, ordered by height.
+func (c *Client) GetAddressTxIDs(addrs []zcashutil.Address) ([]*chainhash.Hash, error) {
+	return c.GetAddressTxIDsAsync(addrs).Receive()
+}
+
+// GetAddressTxIDsRangeAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetAddressTxIDsRange for the blocking version and more details.
+func (c *Client) GetAddressTxIDsRangeAsync(addrs []zcashutil.Address, start, end int) FutureGetAddressTxIDsResult {
+	cmd := zcashjson.NewGetAddressTxIDsCmd(encodeAddresses(addrs), &start,
+		&end)
+	return c.sendCmd(cmd)
+}
+
+// GetAddressTxIDsRange returns the hashes of the transactions involving the
+// passed transparent addresses in blocks from the start height to the end
+// height, inclusive, ordered by height.
+func (c *Client) GetAddressTxIDsRange(addrs []zcashutil.Address, start, end int) ([]*chainhash.Hash, error) {
+	return c.GetAddressTxIDsRangeAsync(addrs, start, end).Receive()
+}
+
+// FutureGetAddressMempoolResult is a future promise to deliver the result of a
+// GetAddressMempoolAsync RPC invocation (or an applicable error).
+type FutureGetAddressMempoolResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// balance changes of the addresses by mempool transactions.
+func (r FutureGetAddressMempoolResult) Receive() ([]zcashjson.AddressMempoolDelta, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of getaddressmempool result objects.
+	var deltas []zcashjson.AddressMempoolDelta
+	err = json.Unmarshal(res, &deltas)
+	if err != nil {
+		return nil, err
+	}
+
+	return deltas, nil
+}
+
+// GetAddressMempoolAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressMempool for the blocking version and more details.
+func (c *Client) GetAddressMempoolAsync(addrs []zcashutil.Address) FutureGetAddressMempoolResult {
+	cmd := zcashjson.NewGetAddressMempoolCmd(encodeAddresses(addrs))
+	return c.sendCmd(cmd)
+}
+
+// GetAddressMempool returns the changes in the balance of the passed
+// transparent addresses made by transactions in the mempool.
+func (c *Client) GetAddressMempool(addrs []zcashutil.Address) ([]zcashjson.AddressMempoolDelta, error) {
+	return c.GetAddressMempoolAsync(addrs).Receive()
+}
+
+// FutureGetSpentInfoResult is a future promise to deliver the result of a
+// GetSpentInfoAsync RPC invocation (or an applicable error).
+type FutureGetSpentInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns the input
+// spending the output.
+func (r FutureGetSpentInfoResult) Receive() (*zcashjson.GetSpentInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getspentinfo result object.
+	var spent zcashjson.GetSpentInfoResult
+	err = json.Unmarshal(res, &spent)
+	if err != nil {
+		return nil, err
+	}
+
+	return &spent, nil
+}
+
+// GetSpentInfoAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetSpentInfo for the blocking version and more details.
+func (c *Client) GetSpentInfoAsync(txHash *chainhash.Hash, index uint32) FutureGetSpentInfoResult {
+	hash := ""
+	if txHash != nil {
+		hash = txHash.String()
+	}
+
+	cmd := zcashjson.NewGetSpentInfoCmd(hash, index)
+	return c.sendCmd(cmd)
+}
+
+// GetSpentInfo returns the transaction input that spends the passed
+// transparent output and the height of the block it was mined in.  An error is
+// returned when the output is unspent.
+func (c *Client) GetSpentInfo(txHash *chainhash.Hash, index uint32) (*zcashjson.GetSpentInfoResult, error) {
+	return c.GetSpentInfoAsync(txHash, index).Receive()
+}
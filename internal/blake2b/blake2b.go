@@ -0,0 +1,206 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package blake2b implements the BLAKE2b hash function as defined in RFC 7693
+// with support for the personalization parameter used throughout the Zcash
+// protocol.
+//
+// Zcash personalizes nearly every BLAKE2b invocation (transaction digests,
+// F4Jumble, Equihash) and the standard library does not expose that
+// parameter, so this package provides the minimal implementation needed by
+// the zcashrpcclient packages.
+package blake2b
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+const (
+	// BlockSize is the block size of BLAKE2b in bytes.
+	BlockSize = 128
+
+	// MaxSize is the maximum digest size of BLAKE2b in bytes.
+	MaxSize = 64
+
+	// PersonalSize is the size of the personalization parameter in bytes.
+	PersonalSize = 16
+)
+
+var iv = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b,
+	0xa54ff53a5f1d36f1, 0x510e527fade682d1, 0x9b05688c2b3e6c1f,
+	0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+var sigma = [12][16]byte{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+}
+
+// digest represents the partial evaluation of a BLAKE2b hash.
+type digest struct {
+	h      [8]uint64
+	t      [2]uint64
+	block  [BlockSize]byte
+	offset int
+	size   int
+	init   [8]uint64
+}
+
+// New returns a new hash.Hash computing a BLAKE2b digest of the given size
+// with the provided personalization.  The personalization may be at most
+// PersonalSize bytes long and is zero padded when shorter.
+func New(size int, personal []byte) (hash.Hash, error) {
+	if size < 1 || size > MaxSize {
+		return nil, errors.New("blake2b: invalid digest size")
+	}
+	if len(personal) > PersonalSize {
+		return nil, errors.New("blake2b: personalization too long")
+	}
+
+	var p [PersonalSize]byte
+	copy(p[:], personal)
+
+	d := &digest{size: size}
+	d.init = iv
+	d.init[0] ^= 0x01010000 ^ uint64(size)
+	d.init[6] ^= binary.LittleEndian.Uint64(p[0:8])
+	d.init[7] ^= binary.LittleEndian.Uint64(p[8:16])
+	d.Reset()
+	return d, nil
+}
+
+// Sum256 returns the 32-byte BLAKE2b digest of data using the provided
+// personalization.  It panics if the personalization is too long, which is
+// always a programming error since every personalization used by Zcash is a
+// constant.
+func Sum256(personal, data []byte) [32]byte {
+	h, err := New(32, personal)
+	if err != nil {
+		panic(err)
+	}
+	h.Write(data)
+
+	var sum [32]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// Reset resets the hash to its initial state.
+func (d *digest) Reset() {
+	d.h = d.init
+	d.t[0], d.t[1] = 0, 0
+	d.offset = 0
+}
+
+// Size returns the number of bytes Sum will return.
+func (d *digest) Size() int { return d.size }
+
+// BlockSize returns the hash's underlying block size.
+func (d *digest) BlockSize() int { return BlockSize }
+
+// Write adds more data to the running hash.  It never returns an error.
+func (d *digest) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		// The final block must be processed with the finalization
+		// flag set, so a full buffer is only compressed once more
+		// data is known to follow it.
+		if d.offset == BlockSize {
+			d.incrementCounter(BlockSize)
+			d.compress(false)
+			d.offset = 0
+		}
+		c := copy(d.block[d.offset:], p)
+		d.offset += c
+		p = p[c:]
+	}
+	return n, nil
+}
+
+// Sum appends the current hash to b and returns the resulting slice.  It does
+// not change the underlying hash state.
+func (d *digest) Sum(b []byte) []byte {
+	dd := *d
+	for i := dd.offset; i < BlockSize; i++ {
+		dd.block[i] = 0
+	}
+	dd.incrementCounter(uint64(dd.offset))
+	dd.compress(true)
+
+	var out [MaxSize]byte
+	for i, v := range dd.h {
+		binary.LittleEndian.PutUint64(out[i*8:], v)
+	}
+	return append(b, out[:dd.size]...)
+}
+
+// incrementCounter adds n to the 128-bit byte counter.
+func (d *digest) incrementCounter(n uint64) {
+	d.t[0] += n
+	if d.t[0] < n {
+		d.t[1]++
+	}
+}
+
+// rotr64 rotates x right by n bits.
+func rotr64(x uint64, n uint) uint64 {
+	return x>>n | x<<(64-n)
+}
+
+// compress runs the BLAKE2b compression function over the buffered block.
+func (d *digest) compress(final bool) {
+	var m [16]uint64
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint64(d.block[i*8:])
+	}
+
+	var v [16]uint64
+	copy(v[0:8], d.h[:])
+	copy(v[8:16], iv[:])
+	v[12] ^= d.t[0]
+	v[13] ^= d.t[1]
+	if final {
+		v[14] = ^v[14]
+	}
+
+	g := func(a, b, c, dd int, x, y uint64) {
+		v[a] = v[a] + v[b] + x
+		v[dd] = rotr64(v[dd]^v[a], 32)
+		v[c] = v[c] + v[dd]
+		v[b] = rotr64(v[b]^v[c], 24)
+		v[a] = v[a] + v[b] + y
+		v[dd] = rotr64(v[dd]^v[a], 16)
+		v[c] = v[c] + v[dd]
+		v[b] = rotr64(v[b]^v[c], 63)
+	}
+
+	for i := 0; i < 12; i++ {
+		s := &sigma[i]
+		g(0, 4, 8, 12, m[s[0]], m[s[1]])
+		g(1, 5, 9, 13, m[s[2]], m[s[3]])
+		g(2, 6, 10, 14, m[s[4]], m[s[5]])
+		g(3, 7, 11, 15, m[s[6]], m[s[7]])
+		g(0, 5, 10, 15, m[s[8]], m[s[9]])
+		g(1, 6, 11, 12, m[s[10]], m[s[11]])
+		g(2, 7, 8, 13, m[s[12]], m[s[13]])
+		g(3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+
+	for i := 0; i < 8; i++ {
+		d.h[i] ^= v[i] ^ v[i+8]
+	}
+}
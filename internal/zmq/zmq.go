@@ -0,0 +1,467 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package zmq implements the subset of the ZeroMQ message transport protocol
+// (ZMTP 3.0) needed to receive the notifications zcashd publishes.
+//
+// zcashd publishes block and transaction events from a PUB socket over TCP
+// using the NULL security mechanism, so only a SUB socket over TCP with that
+// mechanism is provided, which avoids depending on the libzmq C library.  A
+// PUB socket is also provided to stand in for zcashd when testing the
+// subscribers in-process.
+package zmq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// greetingSize is the size of the greeting each peer sends when a
+	// connection is established.
+	greetingSize = 64
+
+	// majorVersion and minorVersion are the version of the protocol sent
+	// in the greeting.
+	majorVersion = 3
+	minorVersion = 0
+
+	// mechanismNull is the name of the NULL security mechanism.
+	mechanismNull = "NULL"
+
+	// These flags are set in the first byte of each frame.
+	flagMore    = 0x01
+	flagLong    = 0x02
+	flagCommand = 0x04
+
+	// maxFrameSize is the size of the largest frame accepted from a peer.
+	// It accommodates the largest Zcash block.
+	maxFrameSize = 32 * 1024 * 1024
+
+	// commandReady is the name of the command completing the handshake of
+	// the NULL mechanism.
+	commandReady = "READY"
+
+	// propertySocketType is the metadata property of the READY command
+	// naming the socket type of a peer.
+	propertySocketType = "Socket-Type"
+
+	// These bytes prefix the messages a subscriber sends to subscribe and
+	// unsubscribe to a topic.
+	subscribe   = 0x01
+	unsubscribe = 0x00
+)
+
+// ErrProtocol describes an error where a peer violated the protocol.
+var ErrProtocol = errors.New("zmq: protocol violation")
+
+// These are the socket types that are able to communicate with each other.
+const (
+	socketTypeSub = "SUB"
+	socketTypePub = "PUB"
+)
+
+// writeGreeting writes the greeting announcing the version of the protocol and
+// the NULL mechanism.
+func writeGreeting(w io.Writer) error {
+	var greeting [greetingSize]byte
+	greeting[0] = 0xff
+	greeting[9] = 0x7f
+	greeting[10] = majorVersion
+	greeting[11] = minorVersion
+	copy(greeting[12:32], mechanismNull)
+	_, err := w.Write(greeting[:])
+	return err
+}
+
+// readGreeting reads the greeting of the peer and checks it is compatible.
+func readGreeting(r io.Reader) error {
+	var greeting [greetingSize]byte
+	if _, err := io.ReadFull(r, greeting[:]); err != nil {
+		return err
+	}
+	if greeting[0] != 0xff || greeting[9]&0x01 == 0 {
+		return ErrProtocol
+	}
+	if greeting[10] < majorVersion {
+		return fmt.Errorf("zmq: unsupported protocol version %d.%d",
+			greeting[10], greeting[11])
+	}
+	mechanism := string(bytes.TrimRight(greeting[12:32], "\x00"))
+	if mechanism != mechanismNull {
+		return fmt.Errorf("zmq: unsupported security mechanism %q",
+			mechanism)
+	}
+	return nil
+}
+
+// writeFrame writes a frame with the passed flags and body.
+func writeFrame(w io.Writer, flags byte, body []byte) error {
+	var header [9]byte
+	n := 2
+	if len(body) > 255 {
+		header[0] = flags | flagLong
+		binary.BigEndian.PutUint64(header[1:], uint64(len(body)))
+		n = 9
+	} else {
+		header[0] = flags
+		header[1] = byte(len(body))
+	}
+	if _, err := w.Write(header[:n]); err != nil {
+		return err
+	}
+	_, err := w.Write(body)
+	return err
+}
+
+// readFrame reads a frame and returns its flags and body.
+func readFrame(r io.Reader) (byte, []byte, error) {
+	var header [9]byte
+	if _, err := io.ReadFull(r, header[:1]); err != nil {
+		return 0, nil, err
+	}
+	flags := header[0]
+
+	var size uint64
+	if flags&flagLong != 0 {
+		if _, err := io.ReadFull(r, header[1:9]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(header[1:9])
+	} else {
+		if _, err := io.ReadFull(r, header[1:2]); err != nil {
+			return 0, nil, err
+		}
+		size = uint64(header[1])
+	}
+	if size > maxFrameSize {
+		return 0, nil, fmt.Errorf("zmq: frame of %d bytes exceeds the "+
+			"maximum of %d", size, maxFrameSize)
+	}
+
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return flags, body, nil
+}
+
+// writeReady writes the READY command announcing the socket type.
+func writeReady(w io.Writer, socketType string) error {
+	var body bytes.Buffer
+	body.WriteByte(byte(len(commandReady)))
+	body.WriteString(commandReady)
+	body.WriteByte(byte(len(propertySocketType)))
+	body.WriteString(propertySocketType)
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(socketType)))
+	body.Write(size[:])
+	body.WriteString(socketType)
+	return writeFrame(w, flagCommand, body.Bytes())
+}
+
+// readReady reads the READY command of the peer and returns its socket type.
+func readReady(r io.Reader) (string, error) {
+	flags, body, err := readFrame(r)
+	if err != nil {
+		return "", err
+	}
+	if flags&flagCommand == 0 || len(body) < 1 {
+		return "", ErrProtocol
+	}
+	nameLen := int(body[0])
+	if len(body) < 1+nameLen || string(body[1:1+nameLen]) != commandReady {
+		return "", ErrProtocol
+	}
+
+	// Parse the metadata properties for the socket type.
+	var socketType string
+	props := body[1+nameLen:]
+	for len(props) > 0 {
+		nameLen := int(props[0])
+		if len(props) < 1+nameLen+4 {
+			return "", ErrProtocol
+		}
+		name := string(props[1 : 1+nameLen])
+		props = props[1+nameLen:]
+		valueLen := binary.BigEndian.Uint32(props)
+		props = props[4:]
+		if uint64(len(props)) < uint64(valueLen) {
+			return "", ErrProtocol
+		}
+		if name == propertySocketType {
+			socketType = string(props[:valueLen])
+		}
+		props = props[valueLen:]
+	}
+	return socketType, nil
+}
+
+// handshake exchanges the greetings and READY commands with the peer over the
+// passed connection and checks the socket type of the peer is the expected
+// one.
+func handshake(conn net.Conn, r io.Reader, socketType, peerType string,
+	timeout time.Duration) error {
+
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	if err := writeGreeting(conn); err != nil {
+		return err
+	}
+	if err := readGreeting(r); err != nil {
+		return err
+	}
+	if err := writeReady(conn, socketType); err != nil {
+		return err
+	}
+	peer, err := readReady(r)
+	if err != nil {
+		return err
+	}
+	if peer != peerType {
+		return fmt.Errorf("zmq: %s socket is incompatible with %s",
+			peer, socketType)
+	}
+	return nil
+}
+
+// Conn is a SUB socket connected to a publisher.
+type Conn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// Subscribe connects to the publisher at the passed TCP address and
+// subscribes to the messages of the passed topics.  Messages are matched to a
+// topic by the prefix of their first frame.  The timeout limits establishing
+// the connection.
+func Subscribe(address string, topics []string, timeout time.Duration) (*Conn, error) {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(conn)
+	err = handshake(conn, r, socketTypeSub, socketTypePub, timeout)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	for _, topic := range topics {
+		msg := append([]byte{subscribe}, topic...)
+		if err := writeFrame(conn, 0, msg); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return &Conn{conn: conn, r: r}, nil
+}
+
+// ReadMessage blocks until a message is received and returns its frames.
+func (c *Conn) ReadMessage() ([][]byte, error) {
+	var parts [][]byte
+	for {
+		flags, body, err := readFrame(c.r)
+		if err != nil {
+			return nil, err
+		}
+
+		// Commands are not part of messages, and no command is
+		// expected after the handshake.
+		if flags&flagCommand != 0 {
+			if len(parts) != 0 {
+				return nil, ErrProtocol
+			}
+			continue
+		}
+
+		parts = append(parts, body)
+		if flags&flagMore == 0 {
+			return parts, nil
+		}
+	}
+}
+
+// Close closes the connection.  A blocked ReadMessage returns an error.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// subscriber is a connection accepted by a Publisher.
+type subscriber struct {
+	conn   net.Conn
+	mtx    sync.Mutex
+	topics [][]byte
+}
+
+// matches returns whether the subscriber is subscribed to the passed topic.
+func (s *subscriber) matches(topic []byte) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, prefix := range s.topics {
+		if bytes.HasPrefix(topic, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Publisher is a PUB socket listening for subscribers.
+type Publisher struct {
+	listener net.Listener
+	mtx      sync.Mutex
+	subs     map[*subscriber]struct{}
+	wg       sync.WaitGroup
+}
+
+// Listen creates a publisher listening on the passed TCP address.
+func Listen(address string) (*Publisher, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	p := &Publisher{
+		listener: listener,
+		subs:     make(map[*subscriber]struct{}),
+	}
+	p.wg.Add(1)
+	go p.acceptHandler()
+	return p, nil
+}
+
+// Addr returns the address the publisher is listening on.
+func (p *Publisher) Addr() net.Addr {
+	return p.listener.Addr()
+}
+
+// acceptHandler accepts subscribers until the listener is closed.  It must be
+// run as a goroutine.
+func (p *Publisher) acceptHandler() {
+	defer p.wg.Done()
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		p.wg.Add(1)
+		go p.subscriberHandler(conn)
+	}
+}
+
+// subscriberHandler completes the handshake with a subscriber and tracks its
+// subscriptions until it disconnects.  It must be run as a goroutine.
+func (p *Publisher) subscriberHandler(conn net.Conn) {
+	defer p.wg.Done()
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	err := handshake(conn, r, socketTypePub, socketTypeSub, 0)
+	if err != nil {
+		return
+	}
+
+	sub := &subscriber{conn: conn}
+	p.mtx.Lock()
+	p.subs[sub] = struct{}{}
+	p.mtx.Unlock()
+	defer func() {
+		p.mtx.Lock()
+		delete(p.subs, sub)
+		p.mtx.Unlock()
+	}()
+
+	for {
+		flags, body, err := readFrame(r)
+		if err != nil {
+			return
+		}
+		if flags&flagCommand != 0 || len(body) == 0 {
+			continue
+		}
+
+		sub.mtx.Lock()
+		switch body[0] {
+		case subscribe:
+			sub.topics = append(sub.topics, body[1:])
+		case unsubscribe:
+			for i, topic := range sub.topics {
+				if bytes.Equal(topic, body[1:]) {
+					sub.topics = append(sub.topics[:i],
+						sub.topics[i+1:]...)
+					break
+				}
+			}
+		}
+		sub.mtx.Unlock()
+	}
+}
+
+// Subscribers returns the number of subscribers a message of the passed topic
+// would be delivered to.  Since subscriptions are received asynchronously, it
+// can be polled to wait for subscribers before publishing.
+func (p *Publisher) Subscribers(topic []byte) int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	var n int
+	for sub := range p.subs {
+		if sub.matches(topic) {
+			n++
+		}
+	}
+	return n
+}
+
+// Publish sends a message with the passed frames to the subscribers of the
+// topic of its first frame.  Like a PUB socket, the message is not delivered
+// to subscribers which have not subscribed yet, and subscribers which can not
+// be written to are disconnected.
+func (p *Publisher) Publish(parts ...[]byte) error {
+	if len(parts) == 0 {
+		return errors.New("zmq: message has no frames")
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for sub := range p.subs {
+		if !sub.matches(parts[0]) {
+			continue
+		}
+
+		var msg bytes.Buffer
+		for i, part := range parts {
+			var flags byte
+			if i < len(parts)-1 {
+				flags = flagMore
+			}
+			writeFrame(&msg, flags, part)
+		}
+		if _, err := sub.conn.Write(msg.Bytes()); err != nil {
+			sub.conn.Close()
+			delete(p.subs, sub)
+		}
+	}
+	return nil
+}
+
+// Close stops the publisher and disconnects its subscribers.
+func (p *Publisher) Close() error {
+	err := p.listener.Close()
+	p.mtx.Lock()
+	for sub := range p.subs {
+		sub.conn.Close()
+	}
+	p.mtx.Unlock()
+	p.wg.Wait()
+	return err
+}
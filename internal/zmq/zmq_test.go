@@ -0,0 +1,109 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zmq
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+// waitSubscribers waits until the publisher has the passed number of
+// subscribers to the topic.
+func waitSubscribers(t *testing.T, p *Publisher, topic string, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Subscribers([]byte(topic)) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d %s subscribers", n,
+				topic)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	p, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer p.Close()
+
+	conn, err := Subscribe(p.Addr().String(),
+		[]string{"hashblock", "rawtx"}, time.Second)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer conn.Close()
+	waitSubscribers(t, p, "rawtx", 1)
+	if n := p.Subscribers([]byte("hashtx")); n != 0 {
+		t.Errorf("Subscribers(hashtx): got %d, want 0", n)
+	}
+
+	// A body larger than a short frame is sent in a long frame.
+	rawTx := bytes.Repeat([]byte{0xab}, 300)
+	messages := [][][]byte{
+		{[]byte("hashtx"), make([]byte, 32), {0, 0, 0, 0}},
+		{[]byte("hashblock"), make([]byte, 32), {0, 0, 0, 0}},
+		{[]byte("rawtx"), rawTx, {1, 0, 0, 0}},
+	}
+	for _, msg := range messages {
+		if err := p.Publish(msg...); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	// The message of the topic not subscribed to is not delivered.
+	for _, want := range messages[1:] {
+		parts, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage: %v", err)
+		}
+		if len(parts) != len(want) {
+			t.Fatalf("ReadMessage: got %d frames, want %d",
+				len(parts), len(want))
+		}
+		for i := range parts {
+			if !bytes.Equal(parts[i], want[i]) {
+				t.Errorf("%s frame %d: got %x, want %x", want[0],
+					i, parts[i], want[i])
+			}
+		}
+	}
+
+	if err := p.Publish(); err == nil {
+		t.Errorf("Publish without frames: got no error")
+	}
+
+	// Closing the publisher disconnects the subscriber.
+	p.Close()
+	if _, err := conn.ReadMessage(); err == nil {
+		t.Errorf("ReadMessage after Close: got no error")
+	}
+}
+
+func TestSubscribeHandshake(t *testing.T) {
+	// A peer which is not a publisher is refused.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handshake(conn, conn, socketTypeSub, socketTypeSub, time.Second)
+	}()
+
+	conn, err := Subscribe(listener.Addr().String(), []string{"rawtx"},
+		time.Second)
+	if err == nil {
+		conn.Close()
+		t.Fatalf("Subscribe to a SUB socket: got no error")
+	}
+}
@@ -5,10 +5,15 @@
 package zcashrpcclient
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
-	"errors"
+	"fmt"
 
+	"github.com/arithmetric/zcashrpcclient/zcashjson"
+	"github.com/arithmetric/zcashrpcclient/zcashtxscript"
+	"github.com/arithmetric/zcashrpcclient/zcashutil"
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcutil"
@@ -288,20 +293,20 @@ func (c *Client) GetNetworkHashPS3(blocks, height int) (int64, error) {
 	return c.GetNetworkHashPS3Async(blocks, height).Receive()
 }
 
-// FutureGetWork is a future promise to deliver the result of a
-// GetWorkAsync RPC invocation (or an applicable error).
-type FutureGetWork chan *response
+// FutureGetBlockTemplateResult is a future promise to deliver the result of a
+// GetBlockTemplateAsync RPC invocation (or an applicable error).
+type FutureGetBlockTemplateResult chan *response
 
-// Receive waits for the response promised by the future and returns the hash
-// data to work on.
-func (r FutureGetWork) Receive() (*btcjson.GetWorkResult, error) {
+// Receive waits for the response promised by the future and returns the block
+// template.
+func (r FutureGetBlockTemplateResult) Receive() (*zcashjson.GetBlockTemplateResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
 	}
 
-	// Unmarshal result as a getwork result object.
-	var result btcjson.GetWorkResult
+	// Unmarshal result as a getblocktemplate result object.
+	var result zcashjson.GetBlockTemplateResult
 	err = json.Unmarshal(res, &result)
 	if err != nil {
 		return nil, err
@@ -310,61 +315,235 @@ func (r FutureGetWork) Receive() (*btcjson.GetWorkResult, error) {
 	return &result, nil
 }
 
-// GetWorkAsync returns an instance of a type that can be used to get the result
-// of the RPC at some future time by invoking the Receive function on the
-// returned instance.
+// GetBlockTemplateAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
 //
-// See GetWork for the blocking version and more details.
-func (c *Client) GetWorkAsync() FutureGetWork {
-	cmd := btcjson.NewGetWorkCmd(nil)
+// See GetBlockTemplate for the blocking version and more details.
+func (c *Client) GetBlockTemplateAsync(request *btcjson.TemplateRequest) FutureGetBlockTemplateResult {
+	cmd := btcjson.NewGetBlockTemplateCmd(request)
 	return c.sendCmd(cmd)
 }
 
-// GetWork returns hash data to work on.
+// GetBlockTemplate returns a template of the next block to mine.  The request
+// may be nil, or set a long poll ID to wait until the template changes.
 //
-// See GetWorkSubmit to submit the found solution.
-func (c *Client) GetWork() (*btcjson.GetWorkResult, error) {
-	return c.GetWorkAsync().Receive()
+// The template holds the transactions to include, including the coinbase
+// transaction paying the funding streams, along with the roots of the header
+// fields which commit to them.  The funding stream outputs of the coinbase
+// transaction are found by passing the template and the result of
+// GetBlockSubsidy to TemplateFundingStreams.  See SubmitBlock to submit a
+// block built from the template once an Equihash solution meeting its target
+// is found.
+func (c *Client) GetBlockTemplate(request *btcjson.TemplateRequest) (*zcashjson.GetBlockTemplateResult, error) {
+	return c.GetBlockTemplateAsync(request).Receive()
 }
 
-// FutureGetWorkSubmit is a future promise to deliver the result of a
-// GetWorkSubmitAsync RPC invocation (or an applicable error).
-type FutureGetWorkSubmit chan *response
+// FutureGetBlockSubsidyResult is a future promise to deliver the result of a
+// GetBlockSubsidyAsync RPC invocation (or an applicable error).
+type FutureGetBlockSubsidyResult chan *response
 
-// Receive waits for the response promised by the future and returns whether
-// or not the submitted block header was accepted.
-func (r FutureGetWorkSubmit) Receive() (bool, error) {
+// Receive waits for the response promised by the future and returns the block
+// subsidy.
+func (r FutureGetBlockSubsidyResult) Receive() (*zcashjson.GetBlockSubsidyResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	// Unmarshal result as a boolean.
-	var accepted bool
-	err = json.Unmarshal(res, &accepted)
+	// Unmarshal result as a getblocksubsidy result object.
+	var result zcashjson.GetBlockSubsidyResult
+	err = json.Unmarshal(res, &result)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	return accepted, nil
+	return &result, nil
 }
 
-// GetWorkSubmitAsync returns an instance of a type that can be used to get the
-// result of the RPC at some future time by invoking the Receive function on the
-// returned instance.
+// GetBlockSubsidyAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
 //
-// See GetWorkSubmit for the blocking version and more details.
-func (c *Client) GetWorkSubmitAsync(data string) FutureGetWorkSubmit {
-	cmd := btcjson.NewGetWorkCmd(&data)
+// See GetBlockSubsidy for the blocking version and more details.
+func (c *Client) GetBlockSubsidyAsync(height int) FutureGetBlockSubsidyResult {
+	cmd := zcashjson.NewGetBlockSubsidyCmd(&height)
 	return c.sendCmd(cmd)
 }
 
-// GetWorkSubmit submits a block header which is a solution to previously
-// requested data and returns whether or not the solution was accepted.
-//
-// See GetWork to request data to work on.
-func (c *Client) GetWorkSubmit(data string) (bool, error) {
-	return c.GetWorkSubmitAsync(data).Receive()
+// GetBlockSubsidy returns how the subsidy of the block at the passed height is
+// split between the miner, the funding streams and the lockbox streams.  It
+// can be passed to TemplateFundingStreams along with a block template for the
+// same height to find the coinbase outputs paying the funding streams.
+func (c *Client) GetBlockSubsidy(height int) (*zcashjson.GetBlockSubsidyResult, error) {
+	return c.GetBlockSubsidyAsync(height).Receive()
+}
+
+// TemplateCoinbaseTx decodes the coinbase transaction of the passed block
+// template.
+func TemplateCoinbaseTx(template *zcashjson.GetBlockTemplateResult) (*zcashwire.MsgTx, error) {
+	serialized, err := hex.DecodeString(template.CoinbaseTxn.Data)
+	if err != nil {
+		return nil, err
+	}
+	var tx zcashwire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(serialized)); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// CoinbaseFundingStream describes the payment of a funding stream by the
+// coinbase transaction of a block template.  A stream paid to a transparent
+// address is paid by the output at Index, which has the Amount and Script of
+// the stream.  Lockbox streams are paid into the lockbox value pool instead of
+// to an output, and streams paid to a shielded address are paid by a shielded
+// output, so Index is -1 and Script is nil for them.
+type CoinbaseFundingStream struct {
+	Recipient string
+	Lockbox   bool
+	Amount    btcutil.Amount
+	Index     int
+	Script    []byte
+}
+
+// TemplateFundingStreams decodes the coinbase transaction of the passed block
+// template and returns it along with the payments of the funding streams and
+// lockbox streams the passed block subsidy, which must be the result of
+// GetBlockSubsidy for the height of the template, describes.  An error is
+// returned when the coinbase transaction does not pay a transparent funding
+// stream.
+func TemplateFundingStreams(template *zcashjson.GetBlockTemplateResult, subsidy *zcashjson.GetBlockSubsidyResult) (*zcashwire.MsgTx, []CoinbaseFundingStream, error) {
+	tx, err := TemplateCoinbaseTx(template)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	streams := make([]CoinbaseFundingStream, 0,
+		len(subsidy.FundingStreams)+len(subsidy.LockboxStreams))
+	paid := make(map[int]struct{})
+	for _, fs := range subsidy.FundingStreams {
+		stream := CoinbaseFundingStream{
+			Recipient: fs.Recipient,
+			Amount:    fs.ValueZat,
+			Index:     -1,
+		}
+		addr, err := zcashutil.DecodeAddress(fs.Address)
+		if err != nil {
+			return nil, nil, fmt.Errorf("funding stream %s: %v",
+				fs.Recipient, err)
+		}
+
+		// Shielded recipients are paid by a shielded output, which has
+		// no payment script to look for.
+		script, err := zcashtxscript.PayToAddrScript(addr)
+		if err != nil {
+			streams = append(streams, stream)
+			continue
+		}
+
+		// Streams with the same recipient and amount are each paid by
+		// their own output.
+		for i, out := range tx.TxOut {
+			_, ok := paid[i]
+			if !ok && out.Value == int64(fs.ValueZat) &&
+				bytes.Equal(out.PkScript, script) {
+
+				paid[i] = struct{}{}
+				stream.Index = i
+				stream.Script = out.PkScript
+				break
+			}
+		}
+		if stream.Index == -1 {
+			return nil, nil, fmt.Errorf("coinbase transaction does "+
+				"not pay funding stream %s", fs.Recipient)
+		}
+		streams = append(streams, stream)
+	}
+	for _, ls := range subsidy.LockboxStreams {
+		streams = append(streams, CoinbaseFundingStream{
+			Recipient: ls.Recipient,
+			Lockbox:   true,
+			Amount:    ls.ValueZat,
+			Index:     -1,
+		})
+	}
+
+	return tx, streams, nil
+}
+
+// SubmitBlockError describes a block rejected by the server.  Its value is the
+// BIP 22 reason returned by submitblock, which is either one of the generic
+// reasons or the reason the block failed validation.  The reasons zcashd is
+// known to return are defined as constants so they can be compared against.
+type SubmitBlockError string
+
+// These constants define the BIP 22 reasons a block may be rejected for.
+const (
+	// SubmitBlockDuplicate indicates the block was already accepted.
+	SubmitBlockDuplicate SubmitBlockError = "duplicate"
+
+	// SubmitBlockDuplicateInvalid indicates the block is already known to
+	// be invalid.
+	SubmitBlockDuplicateInvalid SubmitBlockError = "duplicate-invalid"
+
+	// SubmitBlockDuplicateInconclusive indicates the block is already
+	// known but is not part of the best chain.
+	SubmitBlockDuplicateInconclusive SubmitBlockError = "duplicate-inconclusive"
+
+	// SubmitBlockInconclusive indicates the block was accepted but does
+	// not extend the best chain.
+	SubmitBlockInconclusive SubmitBlockError = "inconclusive"
+
+	// SubmitBlockRejected indicates the block was rejected without a more
+	// specific reason.
+	SubmitBlockRejected SubmitBlockError = "rejected"
+
+	// SubmitBlockHighHash indicates the block hash does not meet the
+	// target.
+	SubmitBlockHighHash SubmitBlockError = "high-hash"
+
+	// SubmitBlockInvalidSolution indicates the Equihash solution of the
+	// block is invalid.
+	SubmitBlockInvalidSolution SubmitBlockError = "invalid-solution"
+
+	// SubmitBlockBadDiffBits indicates the difficulty bits of the block
+	// are incorrect.
+	SubmitBlockBadDiffBits SubmitBlockError = "bad-diffbits"
+
+	// SubmitBlockBadMerkleRoot indicates the merkle root of the block does
+	// not commit to its transactions.
+	SubmitBlockBadMerkleRoot SubmitBlockError = "bad-txnmrklroot"
+
+	// SubmitBlockBadBlockCommitments indicates the block commitments hash
+	// of the block is incorrect.
+	SubmitBlockBadBlockCommitments SubmitBlockError = "bad-block-commitments-hash"
+
+	// SubmitBlockBadPrevBlock indicates the previous block of the block is
+	// unknown or invalid.
+	SubmitBlockBadPrevBlock SubmitBlockError = "bad-prevblk"
+
+	// SubmitBlockTimeTooOld indicates the block time is not after the
+	// median time of the previous blocks.
+	SubmitBlockTimeTooOld SubmitBlockError = "time-too-old"
+
+	// SubmitBlockTimeTooNew indicates the block time is too far in the
+	// future.
+	SubmitBlockTimeTooNew SubmitBlockError = "time-too-new"
+
+	// SubmitBlockBadCoinbaseAmount indicates the coinbase transaction pays
+	// more than the block subsidy and fees.
+	SubmitBlockBadCoinbaseAmount SubmitBlockError = "bad-cb-amount"
+
+	// SubmitBlockFundingStreamMissing indicates the coinbase transaction
+	// does not pay a funding stream.
+	SubmitBlockFundingStreamMissing SubmitBlockError = "cb-funding-stream-missing"
+)
+
+// Error satisfies the error interface and prints the rejection reason.
+func (e SubmitBlockError) Error() string {
+	return "block rejected: " + string(e)
 }
 
 // FutureSubmitBlockResult is a future promise to deliver the result of a
@@ -372,7 +551,8 @@ func (c *Client) GetWorkSubmit(data string) (bool, error) {
 type FutureSubmitBlockResult chan *response
 
 // Receive waits for the response promised by the future and returns an error if
-// any occurred when submitting the block.
+// any occurred when submitting the block.  A block rejected by the server is
+// reported as a SubmitBlockError.
 func (r FutureSubmitBlockResult) Receive() error {
 	res, err := receiveFuture(r)
 	if err != nil {
@@ -386,11 +566,10 @@ func (r FutureSubmitBlockResult) Receive() error {
 			return err
 		}
 
-		return errors.New(result)
+		return SubmitBlockError(result)
 	}
 
 	return nil
-
 }
 
 // SubmitBlockAsync returns an instance of a type that can be used to get the
@@ -398,24 +577,25 @@ func (r FutureSubmitBlockResult) Receive() error {
 // returned instance.
 //
 // See SubmitBlock for the blocking version and more details.
-func (c *Client) SubmitBlockAsync(block *btcutil.Block, options *btcjson.SubmitBlockOptions) FutureSubmitBlockResult {
+func (c *Client) SubmitBlockAsync(block *zcashwire.MsgBlock, options *btcjson.SubmitBlockOptions) FutureSubmitBlockResult {
 	blockHex := ""
 	if block != nil {
-		blockBytes, err := block.Bytes()
-		if err != nil {
+		// Serialize the block, including the Equihash solution of its
+		// header, and convert to hex string.
+		buf := bytes.NewBuffer(make([]byte, 0, block.SerializeSize()))
+		if err := block.Serialize(buf); err != nil {
 			return newFutureError(err)
 		}
-
-		blockHex = hex.EncodeToString(blockBytes)
+		blockHex = hex.EncodeToString(buf.Bytes())
 	}
 
 	cmd := btcjson.NewSubmitBlockCmd(blockHex, options)
 	return c.sendCmd(cmd)
 }
 
-// SubmitBlock attempts to submit a new block into the bitcoin network.
-func (c *Client) SubmitBlock(block *btcutil.Block, options *btcjson.SubmitBlockOptions) error {
+// SubmitBlock attempts to submit a new block into the Zcash network.  A block
+// rejected by the server is reported as a SubmitBlockError, which may be
+// compared against the SubmitBlock reason constants.
+func (c *Client) SubmitBlock(block *zcashwire.MsgBlock, options *btcjson.SubmitBlockOptions) error {
 	return c.SubmitBlockAsync(block, options).Receive()
 }
-
-// TODO(davec): Implement GetBlockTemplate
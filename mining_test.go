@@ -0,0 +1,109 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashrpcclient
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashjson"
+	"github.com/arithmetric/zcashrpcclient/zcashtxscript"
+	"github.com/arithmetric/zcashrpcclient/zcashutil"
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func TestTemplateFundingStreams(t *testing.T) {
+	grants, err := zcashutil.NewAddressScriptHashFromHash(
+		bytes.Repeat([]byte{1}, 20), &zcashcfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewAddressScriptHashFromHash: %v", err)
+	}
+	miner, err := zcashutil.NewAddressPubKeyHash(
+		bytes.Repeat([]byte{2}, 20), &zcashcfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: %v", err)
+	}
+	grantsScript, err := zcashtxscript.PayToAddrScript(grants)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %v", err)
+	}
+	minerScript, err := zcashtxscript.PayToAddrScript(miner)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %v", err)
+	}
+
+	// The coinbase transaction pays the miner and the same stream twice.
+	tx := zcashwire.NewMsgTx(zcashwire.NU5TxVersion)
+	tx.ConsensusBranchID = uint32(zcashcfg.BranchNU6)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex},
+		SignatureScript:  []byte{0x51, 0x00},
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	tx.AddTxOut(wire.NewTxOut(125000000, minerScript))
+	tx.AddTxOut(wire.NewTxOut(12500000, grantsScript))
+	tx.AddTxOut(wire.NewTxOut(12500000, grantsScript))
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	template := &zcashjson.GetBlockTemplateResult{
+		CoinbaseTxn: zcashjson.GetBlockTemplateResultTx{
+			Data: hex.EncodeToString(buf.Bytes()),
+		},
+	}
+
+	stream := zcashjson.FundingStreamResult{
+		Recipient: "Zcash Community Grants NU6",
+		ValueZat:  12500000,
+		Address:   grants.EncodeAddress(),
+	}
+	subsidy := &zcashjson.GetBlockSubsidyResult{
+		FundingStreams: []zcashjson.FundingStreamResult{stream, stream},
+		LockboxStreams: []zcashjson.FundingStreamResult{{
+			Recipient: "Lockbox NU6",
+			ValueZat:  18750000,
+		}},
+	}
+	coinbase, streams, err := TemplateFundingStreams(template, subsidy)
+	if err != nil {
+		t.Fatalf("TemplateFundingStreams: %v", err)
+	}
+	if coinbase.TxHash() != tx.TxHash() {
+		t.Errorf("got coinbase %v, want %v", coinbase.TxHash(),
+			tx.TxHash())
+	}
+
+	// Each stream is paid by its own output.
+	want := []CoinbaseFundingStream{
+		{stream.Recipient, false, 12500000, 1, grantsScript},
+		{stream.Recipient, false, 12500000, 2, grantsScript},
+		{"Lockbox NU6", true, 18750000, -1, nil},
+	}
+	if len(streams) != len(want) {
+		t.Fatalf("got %d streams, want %d", len(streams), len(want))
+	}
+	for i := range want {
+		got := streams[i]
+		if got.Recipient != want[i].Recipient ||
+			got.Lockbox != want[i].Lockbox ||
+			got.Amount != want[i].Amount ||
+			got.Index != want[i].Index ||
+			!bytes.Equal(got.Script, want[i].Script) {
+
+			t.Errorf("stream %d: got %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	// A third payment of the stream is missing.
+	subsidy.FundingStreams = append(subsidy.FundingStreams, stream)
+	if _, _, err := TemplateFundingStreams(template, subsidy); err == nil {
+		t.Errorf("TemplateFundingStreams with a missing stream: got " +
+			"no error")
+	}
+}
@@ -0,0 +1,354 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashrpcclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/arithmetric/zcashrpcclient/zcashjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+const (
+	// defaultOperationPollInterval is the default delay before the first
+	// poll of newly tracked operations.
+	defaultOperationPollInterval = 250 * time.Millisecond
+
+	// defaultMaxOperationPollInterval is the default upper bound the
+	// delay between polls backs off to while operations are pending.
+	defaultMaxOperationPollInterval = 10 * time.Second
+)
+
+var (
+	// ErrOperationNotFound is an error to describe the condition where
+	// the server no longer knows about a tracked operation, for example
+	// because its result was already retrieved with z_getoperationresult.
+	ErrOperationNotFound = errors.New("operation not found")
+
+	// ErrTrackerStopped is an error to describe the condition where an
+	// operation can not be waited on because the tracker has been stopped.
+	ErrTrackerStopped = errors.New("the operation tracker has been stopped")
+)
+
+// OperationError describes an asynchronous operation that finished without
+// succeeding.  Code and Message are those reported by the server and are
+// empty for cancelled operations.
+type OperationError struct {
+	OperationID string
+	Status      zcashjson.ZOperationStatus
+	Code        int
+	Message     string
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e OperationError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("operation %s %s", e.OperationID, e.Status)
+	}
+	return fmt.Sprintf("operation %s %s: %s (code %d)", e.OperationID,
+		e.Status, e.Message, e.Code)
+}
+
+// operationOutcome is the final result of a tracked operation.
+type operationOutcome struct {
+	txid *chainhash.Hash
+	err  error
+}
+
+// FutureOperationResult is a future promise to deliver the final result of an
+// operation tracked by an OperationTracker.
+type FutureOperationResult chan *operationOutcome
+
+// Receive waits for the operation promised by the future to finish and
+// returns the hash of the transaction it created.  Operations that fail or
+// are cancelled return an OperationError.
+func (r FutureOperationResult) Receive() (*chainhash.Hash, error) {
+	outcome := <-r
+	return outcome.txid, outcome.err
+}
+
+// OperationTrackerConfig describes the polling behavior of an
+// OperationTracker.
+type OperationTrackerConfig struct {
+	// PollInterval is the delay before polling newly tracked operations.
+	// While operations remain pending the delay doubles after every poll.
+	// Zero uses a default of 250 milliseconds.
+	PollInterval time.Duration
+
+	// MaxPollInterval is the upper bound of the delay between polls.
+	// Zero uses a default of 10 seconds.
+	MaxPollInterval time.Duration
+}
+
+// OperationTracker waits on the asynchronous operations started by RPCs such
+// as z_sendmany.  It polls z_getoperationstatus for every tracked operation in
+// a single request, backing off while operations remain pending, and resolves
+// each operation's futures once it reaches a final state.
+//
+// An OperationTracker must be stopped with Stop once it is no longer needed.
+type OperationTracker struct {
+	client      *Client
+	minInterval time.Duration
+	maxInterval time.Duration
+
+	mtx     sync.Mutex
+	waiters map[string][]chan *operationOutcome
+	stopped bool
+
+	track chan struct{}
+	quit  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewOperationTracker returns a new OperationTracker that polls the server
+// the client is connected to.  Passing nil for config uses the default
+// polling intervals.
+func (c *Client) NewOperationTracker(config *OperationTrackerConfig) *OperationTracker {
+	t := &OperationTracker{
+		client:      c,
+		minInterval: defaultOperationPollInterval,
+		maxInterval: defaultMaxOperationPollInterval,
+		waiters:     make(map[string][]chan *operationOutcome),
+		track:       make(chan struct{}, 1),
+		quit:        make(chan struct{}),
+	}
+	if config != nil {
+		if config.PollInterval > 0 {
+			t.minInterval = config.PollInterval
+		}
+		if config.MaxPollInterval > 0 {
+			t.maxInterval = config.MaxPollInterval
+		}
+	}
+	if t.maxInterval < t.minInterval {
+		t.maxInterval = t.minInterval
+	}
+
+	t.wg.Add(1)
+	go t.pollHandler()
+	return t
+}
+
+// Track starts tracking the passed operation and returns a future that
+// resolves once it finishes.  An operation may be tracked more than once.
+func (t *OperationTracker) Track(operationID string) FutureOperationResult {
+	return FutureOperationResult(t.addWaiter(operationID))
+}
+
+// addWaiter registers a new channel to be notified when the operation
+// finishes and wakes the poll handler.
+func (t *OperationTracker) addWaiter(operationID string) chan *operationOutcome {
+	waiter := make(chan *operationOutcome, 1)
+
+	t.mtx.Lock()
+	if t.stopped {
+		t.mtx.Unlock()
+		waiter <- &operationOutcome{err: ErrTrackerStopped}
+		return waiter
+	}
+	t.waiters[operationID] = append(t.waiters[operationID], waiter)
+	t.mtx.Unlock()
+
+	// Restart polling at the minimum interval.  The channel is buffered
+	// so a pending wake up is never lost.
+	select {
+	case t.track <- struct{}{}:
+	default:
+	}
+	return waiter
+}
+
+// removeWaiter stops notifying the channel about the operation.
+func (t *OperationTracker) removeWaiter(operationID string, waiter chan *operationOutcome) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	waiters := t.waiters[operationID]
+	for i, w := range waiters {
+		if w == waiter {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(t.waiters, operationID)
+	} else {
+		t.waiters[operationID] = waiters
+	}
+}
+
+// WaitForOperation tracks the passed operation and blocks until it finishes,
+// returning the hash of the transaction it created.  If the context is done
+// first, the operation is no longer waited on and the context's error is
+// returned.
+func (t *OperationTracker) WaitForOperation(ctx context.Context, operationID string) (*chainhash.Hash, error) {
+	waiter := t.addWaiter(operationID)
+	select {
+	case outcome := <-waiter:
+		return outcome.txid, outcome.err
+	case <-ctx.Done():
+		t.removeWaiter(operationID, waiter)
+		return nil, ctx.Err()
+	}
+}
+
+// Stop stops polling and resolves the futures of every pending operation with
+// ErrTrackerStopped.  The operations themselves continue on the server.
+func (t *OperationTracker) Stop() {
+	t.mtx.Lock()
+	if t.stopped {
+		t.mtx.Unlock()
+		return
+	}
+	t.stopped = true
+	close(t.quit)
+	t.mtx.Unlock()
+
+	t.wg.Wait()
+	t.resolveAll(ErrTrackerStopped)
+}
+
+// pendingOperations returns the IDs of every operation being waited on.
+func (t *OperationTracker) pendingOperations() []string {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	ids := make([]string, 0, len(t.waiters))
+	for id := range t.waiters {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// resolve delivers the outcome of the operation to everything waiting on it.
+func (t *OperationTracker) resolve(operationID string, outcome *operationOutcome) {
+	t.mtx.Lock()
+	waiters := t.waiters[operationID]
+	delete(t.waiters, operationID)
+	t.mtx.Unlock()
+
+	for _, waiter := range waiters {
+		waiter <- outcome
+	}
+}
+
+// resolveAll fails every pending operation with the passed error.
+func (t *OperationTracker) resolveAll(err error) {
+	for _, id := range t.pendingOperations() {
+		t.resolve(id, &operationOutcome{err: err})
+	}
+}
+
+// pollHandler polls the server for the status of the pending operations until
+// the tracker is stopped or the client shuts down.  It must be run as a
+// goroutine.
+func (t *OperationTracker) pollHandler() {
+	defer t.wg.Done()
+
+	interval := t.minInterval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-t.track:
+			// Newly tracked operations are polled promptly.
+			interval = t.minInterval
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(interval)
+			continue
+
+		case <-timer.C:
+
+		case <-t.client.shutdown:
+			t.resolveAll(ErrClientShutdown)
+			return
+
+		case <-t.quit:
+			return
+		}
+
+		ids := t.pendingOperations()
+		if len(ids) > 0 {
+			if err := t.poll(ids); err != nil {
+				log.Warnf("Failed to poll operation status: %v",
+					err)
+			}
+		}
+
+		interval *= 2
+		if interval > t.maxInterval {
+			interval = t.maxInterval
+		}
+		timer.Reset(interval)
+	}
+}
+
+// poll requests the status of the passed operations and resolves those that
+// have finished.
+func (t *OperationTracker) poll(ids []string) error {
+	results, err := t.client.ZGetOperationStatus(ids...)
+	if err != nil {
+		if err == ErrClientShutdown {
+			t.resolveAll(err)
+		}
+		return err
+	}
+
+	seen := make(map[string]struct{}, len(results))
+	for i := range results {
+		result := &results[i]
+		seen[result.ID] = struct{}{}
+		if outcome := operationOutcomeFromResult(result); outcome != nil {
+			t.resolve(result.ID, outcome)
+		}
+	}
+
+	for _, id := range ids {
+		if _, ok := seen[id]; !ok {
+			t.resolve(id, &operationOutcome{err: ErrOperationNotFound})
+		}
+	}
+	return nil
+}
+
+// operationOutcomeFromResult returns the outcome of a finished operation, or
+// nil when the operation is still queued or executing.
+func operationOutcomeFromResult(result *zcashjson.ZGetOperationStatusResult) *operationOutcome {
+	if !result.Status.IsTerminal() {
+		return nil
+	}
+
+	if result.Status.IsSuccess() {
+		if result.Result == nil {
+			return &operationOutcome{err: fmt.Errorf("operation %s "+
+				"succeeded without a result", result.ID)}
+		}
+		txid, err := chainhash.NewHashFromStr(result.Result.TxID)
+		if err != nil {
+			return &operationOutcome{err: err}
+		}
+		return &operationOutcome{txid: txid}
+	}
+
+	opErr := OperationError{
+		OperationID: result.ID,
+		Status:      result.Status,
+	}
+	if result.Error != nil {
+		opErr.Code = result.Error.Code
+		opErr.Message = result.Error.Message
+	}
+	return &operationOutcome{err: opErr}
+}
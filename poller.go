@@ -0,0 +1,396 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashrpcclient
+
+import (
+	"time"
+
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+)
+
+// polledBlock describes a block of the best chain seen by the poller.
+type polledBlock struct {
+	hash     chainhash.Hash
+	prevHash chainhash.Hash
+	height   int32
+	time     time.Time
+}
+
+// pollState houses the state of the server seen by the previous poll, which
+// the next poll is compared against.
+type pollState struct {
+	// blocks holds the most recent blocks of the best chain, in ascending
+	// order of height.  It is empty until the first successful poll.
+	blocks []polledBlock
+
+	// mempool holds the transactions of the memory pool.  It is nil until
+	// the first successful poll.
+	mempool map[chainhash.Hash]struct{}
+
+	// skipped holds the transactions published by ZMQ hosts which were not
+	// in the memory pool, which are those of connected blocks.  It is
+	// cleared whenever a block is published.
+	skipped map[chainhash.Hash]struct{}
+
+	// sinceBlock is the block listsinceblock is queried from, and received
+	// holds the wallet receives it last returned, keyed by the transaction
+	// and block hashes.  Both are nil until the first successful poll.
+	sinceBlock *chainhash.Hash
+	received   map[string]struct{}
+}
+
+// tip returns the most recent block of the best chain seen by the poller.
+func (s *pollState) tip() *polledBlock {
+	return &s.blocks[len(s.blocks)-1]
+}
+
+// pollHandler polls the server for changes to the best chain, the memory pool,
+// and the wallet at the configured interval, and invokes the notification
+// handlers for them.  It must be run as a goroutine.
+//
+// The handlers are run from this goroutine, so unlike in websocket mode they
+// may issue blocking requests on the client.
+func (c *Client) pollHandler() {
+	ticker := time.NewTicker(c.config.PollInterval)
+	defer ticker.Stop()
+
+	var state pollState
+out:
+	for {
+		c.poll(&state)
+
+		select {
+		case <-ticker.C:
+		case <-c.shutdown:
+			break out
+		}
+	}
+	c.wg.Done()
+	log.Tracef("RPC client poll handler done for %s", c.config.Host)
+}
+
+// poll compares the server against the state seen by the previous poll and
+// invokes the notification handlers for the differences.  The first poll only
+// records the state of the server.
+func (c *Client) poll(state *pollState) {
+	handlers := c.ntfnHandlers
+	if handlers.OnBlockConnected != nil ||
+		handlers.OnBlockDisconnected != nil {
+
+		if err := c.pollBlocks(state); err != nil {
+			log.Warnf("Unable to poll for blocks: %v", err)
+		}
+	}
+	if handlers.OnTxAccepted != nil || handlers.OnTxAcceptedVerbose != nil {
+		if err := c.pollMempool(state); err != nil {
+			log.Warnf("Unable to poll the memory pool: %v", err)
+		}
+	}
+	if handlers.OnRecvTx != nil {
+		if err := c.pollReceived(state); err != nil {
+			log.Warnf("Unable to poll for received transactions: %v",
+				err)
+		}
+	}
+}
+
+// pollBlock returns the block with the passed hash.  It is not required to be
+// part of the best chain.
+func (c *Client) pollBlock(hash *chainhash.Hash) (*polledBlock, error) {
+	header, err := c.GetBlockHeaderVerbose(hash)
+	if err != nil {
+		return nil, err
+	}
+	block := &polledBlock{
+		hash:   *hash,
+		height: header.Height,
+		time:   time.Unix(header.Time, 0),
+	}
+	if header.PreviousHash != "" {
+		err := chainhash.Decode(&block.prevHash, header.PreviousHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return block, nil
+}
+
+// inBestChain returns whether the passed block is part of the best chain with
+// the passed height.
+func (c *Client) inBestChain(block *polledBlock, bestHeight int32) (bool, error) {
+	if block.height > bestHeight {
+		return false, nil
+	}
+	hash, err := c.GetBlockHash(int64(block.height))
+	if err != nil {
+		return false, err
+	}
+	return *hash == block.hash, nil
+}
+
+// pollBlocks notifies the blocks connected to and disconnected from the best
+// chain since the previous poll.  When the best chain was reorganized, the
+// blocks of the old chain are disconnected from its tip down to the fork
+// point before the blocks of the new chain are connected.
+func (c *Client) pollBlocks(state *pollState) error {
+	bestHash, err := c.GetBestBlockHash()
+	if err != nil {
+		return err
+	}
+	if len(state.blocks) != 0 && state.tip().hash == *bestHash {
+		return nil
+	}
+	best, err := c.pollBlock(bestHash)
+	if err != nil {
+		return err
+	}
+	if len(state.blocks) == 0 {
+		state.blocks = append(state.blocks, *best)
+		return nil
+	}
+
+	// Find the most recent remembered block that is still part of the
+	// best chain.
+	fork := len(state.blocks) - 1
+	for ; fork >= 0; fork-- {
+		ok, err := c.inBestChain(&state.blocks[fork], best.height)
+		if err != nil {
+			return err
+		}
+		if ok {
+			break
+		}
+	}
+
+	// When none is, the fork is older than the remembered blocks, so the
+	// old chain is followed back until it meets the best chain.  Stale
+	// blocks are still known to the server.
+	for fork < 0 && state.blocks[0].height > 0 &&
+		len(state.blocks) <= maxReorgLength {
+
+		block, err := c.pollBlock(&state.blocks[0].prevHash)
+		if err != nil {
+			return err
+		}
+		state.blocks = append([]polledBlock{*block}, state.blocks...)
+		ok, err := c.inBestChain(block, best.height)
+		if err != nil {
+			return err
+		}
+		if ok {
+			fork = 0
+		}
+	}
+
+	// Fetch the blocks of the new chain before notifying anything, so an
+	// error leaves the state to be compared again by the next poll.
+	connected := []polledBlock{*best}
+	for connected[0].height > 0 {
+		if fork >= 0 && connected[0].prevHash == state.blocks[fork].hash {
+			break
+		}
+		if fork < 0 && connected[0].height <= state.blocks[0].height {
+			break
+		}
+		block, err := c.pollBlock(&connected[0].prevHash)
+		if err != nil {
+			return err
+		}
+		connected = append([]polledBlock{*block}, connected...)
+	}
+
+	if c.ntfnHandlers.OnBlockDisconnected != nil {
+		for i := len(state.blocks) - 1; i > fork; i-- {
+			block := &state.blocks[i]
+			c.ntfnHandlers.OnBlockDisconnected(&block.hash,
+				block.height, block.time)
+		}
+	}
+	state.blocks = append(state.blocks[:fork+1], connected...)
+	if c.ntfnHandlers.OnBlockConnected != nil {
+		for i := range connected {
+			block := &connected[i]
+			c.ntfnHandlers.OnBlockConnected(&block.hash,
+				block.height, block.time)
+		}
+	}
+
+	// The deepest reorganization zcashd accepts forks from the oldest of
+	// the remembered blocks.
+	if len(state.blocks) > maxReorgLength+1 {
+		state.blocks = append([]polledBlock(nil),
+			state.blocks[len(state.blocks)-maxReorgLength-1:]...)
+	}
+	return nil
+}
+
+// pollMempool notifies the transactions accepted to the memory pool since the
+// previous poll.
+func (c *Client) pollMempool(state *pollState) error {
+	hashes, err := c.GetRawMempool()
+	if err != nil {
+		return err
+	}
+	for _, hash := range state.updateMempool(hashes) {
+		c.notifyTxAccepted(hash, nil)
+	}
+	return nil
+}
+
+// updateMempool replaces the memory pool of the state with the passed
+// transactions and returns those which were not part of it.  None are returned
+// by the first update.
+func (s *pollState) updateMempool(hashes []*chainhash.Hash) []*chainhash.Hash {
+	mempool := make(map[chainhash.Hash]struct{}, len(hashes))
+	for _, hash := range hashes {
+		mempool[*hash] = struct{}{}
+	}
+	previous := s.mempool
+	s.mempool = mempool
+	if previous == nil {
+		return nil
+	}
+
+	var accepted []*chainhash.Hash
+	for _, hash := range hashes {
+		if _, ok := previous[*hash]; !ok {
+			accepted = append(accepted, hash)
+		}
+	}
+	return accepted
+}
+
+// notifyTxAccepted invokes the handlers for a transaction accepted to the
+// memory pool.  The transaction is fetched when it is not passed, and it is
+// skipped when it left the memory pool before it could be fetched.
+func (c *Client) notifyTxAccepted(hash *chainhash.Hash, tx *zcashwire.MsgTx) {
+	if c.ntfnHandlers.OnTxAccepted != nil {
+		if tx == nil {
+			var err error
+			tx, err = c.GetRawTransaction(hash)
+			if err != nil {
+				log.Debugf("Unable to fetch accepted transaction "+
+					"%v: %v", hash, err)
+				return
+			}
+		}
+		var amount int64
+		for _, txOut := range tx.TxOut {
+			amount += txOut.Value
+		}
+		c.ntfnHandlers.OnTxAccepted(hash, btcutil.Amount(amount))
+	}
+	if c.ntfnHandlers.OnTxAcceptedVerbose != nil {
+		txDetails, err := c.GetRawTransactionVerbose(hash)
+		if err != nil {
+			log.Debugf("Unable to fetch accepted transaction %v: %v",
+				hash, err)
+			return
+		}
+		c.ntfnHandlers.OnTxAcceptedVerbose(txDetails)
+	}
+}
+
+// isReceiveCategory returns whether the category of a wallet transaction
+// listed by listsinceblock describes funds received by the wallet.
+func isReceiveCategory(category string) bool {
+	switch category {
+	case "receive", "generate", "immature":
+		return true
+	}
+	return false
+}
+
+// pollReceived notifies the transactions which paid the wallet since the
+// previous poll.  Like in websocket mode, a transaction is notified without
+// block details when it enters the memory pool and again with them when it is
+// mined.
+func (c *Client) pollReceived(state *pollState) error {
+	// The first poll lists the wallet from the best block, so only the
+	// receives in the memory pool are recorded instead of its history.
+	sinceBlock := state.sinceBlock
+	if sinceBlock == nil {
+		var err error
+		sinceBlock, err = c.GetBestBlockHash()
+		if err != nil {
+			return err
+		}
+	}
+	since, err := c.ListSinceBlock(sinceBlock)
+	if err != nil {
+		return err
+	}
+	lastBlock, err := chainhash.NewHashFromStr(since.LastBlock)
+	if err != nil {
+		return err
+	}
+
+	received := make(map[string]struct{})
+	var notify []*btcjson.ListTransactionsResult
+	for i := range since.Transactions {
+		tx := &since.Transactions[i]
+		if !isReceiveCategory(tx.Category) {
+			continue
+		}
+		key := tx.TxID + tx.BlockHash
+		if _, ok := received[key]; ok {
+			continue
+		}
+		received[key] = struct{}{}
+		if state.received != nil {
+			if _, ok := state.received[key]; !ok {
+				notify = append(notify, tx)
+			}
+		}
+	}
+
+	// Fetch the received transactions before notifying anything, so an
+	// error leaves them to be notified by the next poll.
+	msgTxs := make([]*zcashwire.MsgTx, 0, len(notify))
+	details := make([]*btcjson.BlockDetails, 0, len(notify))
+	for _, tx := range notify {
+		txHash, err := chainhash.NewHashFromStr(tx.TxID)
+		if err != nil {
+			return err
+		}
+		msgTx, err := c.GetRawTransaction(txHash)
+		if err != nil {
+			return err
+		}
+		msgTxs = append(msgTxs, msgTx)
+
+		if tx.BlockHash == "" {
+			details = append(details, nil)
+			continue
+		}
+		blockHash, err := chainhash.NewHashFromStr(tx.BlockHash)
+		if err != nil {
+			return err
+		}
+		header, err := c.GetBlockHeaderVerbose(blockHash)
+		if err != nil {
+			return err
+		}
+		blockDetails := &btcjson.BlockDetails{
+			Height: header.Height,
+			Hash:   tx.BlockHash,
+			Time:   tx.BlockTime,
+		}
+		if tx.BlockIndex != nil {
+			blockDetails.Index = int(*tx.BlockIndex)
+		}
+		details = append(details, blockDetails)
+	}
+
+	state.sinceBlock = lastBlock
+	state.received = received
+	for i, msgTx := range msgTxs {
+		c.ntfnHandlers.OnRecvTx(msgTx, details[i])
+	}
+	return nil
+}
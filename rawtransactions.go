@@ -8,10 +8,13 @@ import (
 	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"sort"
 
+	"github.com/arithmetric/zcashrpcclient/zcashjson"
+	"github.com/arithmetric/zcashrpcclient/zcashutil"
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
-	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
 )
 
@@ -64,7 +67,7 @@ type FutureGetRawTransactionResult chan *response
 
 // Receive waits for the response promised by the future and returns a
 // transaction given its hash.
-func (r FutureGetRawTransactionResult) Receive() (*btcutil.Tx, error) {
+func (r FutureGetRawTransactionResult) Receive() (*zcashwire.MsgTx, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
@@ -84,11 +87,11 @@ func (r FutureGetRawTransactionResult) Receive() (*btcutil.Tx, error) {
 	}
 
 	// Deserialize the transaction and return it.
-	var msgTx wire.MsgTx
+	var msgTx zcashwire.MsgTx
 	if err := msgTx.Deserialize(bytes.NewReader(serializedTx)); err != nil {
 		return nil, err
 	}
-	return btcutil.NewTx(&msgTx), nil
+	return &msgTx, nil
 }
 
 // GetRawTransactionAsync returns an instance of a type that can be used to get
@@ -110,7 +113,7 @@ func (c *Client) GetRawTransactionAsync(txHash *chainhash.Hash) FutureGetRawTran
 //
 // See GetRawTransactionVerbose to obtain additional information about the
 // transaction.
-func (c *Client) GetRawTransaction(txHash *chainhash.Hash) (*btcutil.Tx, error) {
+func (c *Client) GetRawTransaction(txHash *chainhash.Hash) (*zcashwire.MsgTx, error) {
 	return c.GetRawTransactionAsync(txHash).Receive()
 }
 
@@ -206,7 +209,7 @@ type FutureCreateRawTransactionResult chan *response
 // Receive waits for the response promised by the future and returns a new
 // transaction spending the provided inputs and sending to the provided
 // addresses.
-func (r FutureCreateRawTransactionResult) Receive() (*wire.MsgTx, error) {
+func (r FutureCreateRawTransactionResult) Receive() (*zcashwire.MsgTx, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
@@ -226,7 +229,7 @@ func (r FutureCreateRawTransactionResult) Receive() (*wire.MsgTx, error) {
 	}
 
 	// Deserialize the transaction and return it.
-	var msgTx wire.MsgTx
+	var msgTx zcashwire.MsgTx
 	if err := msgTx.Deserialize(bytes.NewReader(serializedTx)); err != nil {
 		return nil, err
 	}
@@ -239,7 +242,7 @@ func (r FutureCreateRawTransactionResult) Receive() (*wire.MsgTx, error) {
 //
 // See CreateRawTransaction for the blocking version and more details.
 func (c *Client) CreateRawTransactionAsync(inputs []btcjson.TransactionInput,
-	amounts map[btcutil.Address]btcutil.Amount, lockTime *int64) FutureCreateRawTransactionResult {
+	amounts map[zcashutil.Address]btcutil.Amount, lockTime *int64) FutureCreateRawTransactionResult {
 
 	convertedAmts := make(map[string]float64, len(amounts))
 	for addr, amount := range amounts {
@@ -252,7 +255,7 @@ func (c *Client) CreateRawTransactionAsync(inputs []btcjson.TransactionInput,
 // CreateRawTransaction returns a new transaction spending the provided inputs
 // and sending to the provided addresses.
 func (c *Client) CreateRawTransaction(inputs []btcjson.TransactionInput,
-	amounts map[btcutil.Address]btcutil.Amount, lockTime *int64) (*wire.MsgTx, error) {
+	amounts map[zcashutil.Address]btcutil.Amount, lockTime *int64) (*zcashwire.MsgTx, error) {
 
 	return c.CreateRawTransactionAsync(inputs, amounts, lockTime).Receive()
 }
@@ -285,7 +288,7 @@ func (r FutureSendRawTransactionResult) Receive() (*chainhash.Hash, error) {
 // the returned instance.
 //
 // See SendRawTransaction for the blocking version and more details.
-func (c *Client) SendRawTransactionAsync(tx *wire.MsgTx, allowHighFees bool) FutureSendRawTransactionResult {
+func (c *Client) SendRawTransactionAsync(tx *zcashwire.MsgTx, allowHighFees bool) FutureSendRawTransactionResult {
 	txHex := ""
 	if tx != nil {
 		// Serialize the transaction and convert to hex string.
@@ -302,7 +305,7 @@ func (c *Client) SendRawTransactionAsync(tx *wire.MsgTx, allowHighFees bool) Fut
 
 // SendRawTransaction submits the encoded transaction to the server which will
 // then relay it to the network.
-func (c *Client) SendRawTransaction(tx *wire.MsgTx, allowHighFees bool) (*chainhash.Hash, error) {
+func (c *Client) SendRawTransaction(tx *zcashwire.MsgTx, allowHighFees bool) (*chainhash.Hash, error) {
 	return c.SendRawTransactionAsync(tx, allowHighFees).Receive()
 }
 
@@ -313,7 +316,7 @@ type FutureSignRawTransactionResult chan *response
 
 // Receive waits for the response promised by the future and returns the
 // signed transaction as well as whether or not all inputs are now signed.
-func (r FutureSignRawTransactionResult) Receive() (*wire.MsgTx, bool, error) {
+func (r FutureSignRawTransactionResult) Receive() (*zcashwire.MsgTx, bool, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, false, err
@@ -333,7 +336,7 @@ func (r FutureSignRawTransactionResult) Receive() (*wire.MsgTx, bool, error) {
 	}
 
 	// Deserialize the transaction and return it.
-	var msgTx wire.MsgTx
+	var msgTx zcashwire.MsgTx
 	if err := msgTx.Deserialize(bytes.NewReader(serializedTx)); err != nil {
 		return nil, false, err
 	}
@@ -346,7 +349,7 @@ func (r FutureSignRawTransactionResult) Receive() (*wire.MsgTx, bool, error) {
 // the returned instance.
 //
 // See SignRawTransaction for the blocking version and more details.
-func (c *Client) SignRawTransactionAsync(tx *wire.MsgTx) FutureSignRawTransactionResult {
+func (c *Client) SignRawTransactionAsync(tx *zcashwire.MsgTx) FutureSignRawTransactionResult {
 	txHex := ""
 	if tx != nil {
 		// Serialize the transaction and convert to hex string.
@@ -368,7 +371,7 @@ func (c *Client) SignRawTransactionAsync(tx *wire.MsgTx) FutureSignRawTransactio
 // private keys for the passed transaction which needs to be signed and uses the
 // default signature hash type.  Use one of the SignRawTransaction# variants to
 // specify that information if needed.
-func (c *Client) SignRawTransaction(tx *wire.MsgTx) (*wire.MsgTx, bool, error) {
+func (c *Client) SignRawTransaction(tx *zcashwire.MsgTx) (*zcashwire.MsgTx, bool, error) {
 	return c.SignRawTransactionAsync(tx).Receive()
 }
 
@@ -377,7 +380,7 @@ func (c *Client) SignRawTransaction(tx *wire.MsgTx) (*wire.MsgTx, bool, error) {
 // function on the returned instance.
 //
 // See SignRawTransaction2 for the blocking version and more details.
-func (c *Client) SignRawTransaction2Async(tx *wire.MsgTx, inputs []btcjson.RawTxInput) FutureSignRawTransactionResult {
+func (c *Client) SignRawTransaction2Async(tx *zcashwire.MsgTx, inputs []btcjson.RawTxInput) FutureSignRawTransactionResult {
 	txHex := ""
 	if tx != nil {
 		// Serialize the transaction and convert to hex string.
@@ -402,7 +405,7 @@ func (c *Client) SignRawTransaction2Async(tx *wire.MsgTx, inputs []btcjson.RawTx
 //
 // See SignRawTransaction if the RPC server already knows the input
 // transactions.
-func (c *Client) SignRawTransaction2(tx *wire.MsgTx, inputs []btcjson.RawTxInput) (*wire.MsgTx, bool, error) {
+func (c *Client) SignRawTransaction2(tx *zcashwire.MsgTx, inputs []btcjson.RawTxInput) (*zcashwire.MsgTx, bool, error) {
 	return c.SignRawTransaction2Async(tx, inputs).Receive()
 }
 
@@ -411,7 +414,7 @@ func (c *Client) SignRawTransaction2(tx *wire.MsgTx, inputs []btcjson.RawTxInput
 // function on the returned instance.
 //
 // See SignRawTransaction3 for the blocking version and more details.
-func (c *Client) SignRawTransaction3Async(tx *wire.MsgTx,
+func (c *Client) SignRawTransaction3Async(tx *zcashwire.MsgTx,
 	inputs []btcjson.RawTxInput,
 	privKeysWIF []string) FutureSignRawTransactionResult {
 
@@ -447,9 +450,9 @@ func (c *Client) SignRawTransaction3Async(tx *wire.MsgTx,
 // See SignRawTransaction if the RPC server already knows the input
 // transactions and private keys or SignRawTransaction2 if it already knows the
 // private keys.
-func (c *Client) SignRawTransaction3(tx *wire.MsgTx,
+func (c *Client) SignRawTransaction3(tx *zcashwire.MsgTx,
 	inputs []btcjson.RawTxInput,
-	privKeysWIF []string) (*wire.MsgTx, bool, error) {
+	privKeysWIF []string) (*zcashwire.MsgTx, bool, error) {
 
 	return c.SignRawTransaction3Async(tx, inputs, privKeysWIF).Receive()
 }
@@ -459,7 +462,7 @@ func (c *Client) SignRawTransaction3(tx *wire.MsgTx,
 // function on the returned instance.
 //
 // See SignRawTransaction4 for the blocking version and more details.
-func (c *Client) SignRawTransaction4Async(tx *wire.MsgTx,
+func (c *Client) SignRawTransaction4Async(tx *zcashwire.MsgTx,
 	inputs []btcjson.RawTxInput, privKeysWIF []string,
 	hashType SigHashType) FutureSignRawTransactionResult {
 
@@ -497,9 +500,9 @@ func (c *Client) SignRawTransaction4Async(tx *wire.MsgTx,
 // desired.  Otherwise, see SignRawTransaction if the RPC server already knows
 // the input transactions and private keys, SignRawTransaction2 if it already
 // knows the private keys, or SignRawTransaction3 if it does not know both.
-func (c *Client) SignRawTransaction4(tx *wire.MsgTx,
+func (c *Client) SignRawTransaction4(tx *zcashwire.MsgTx,
 	inputs []btcjson.RawTxInput, privKeysWIF []string,
-	hashType SigHashType) (*wire.MsgTx, bool, error) {
+	hashType SigHashType) (*zcashwire.MsgTx, bool, error) {
 
 	return c.SignRawTransaction4Async(tx, inputs, privKeysWIF,
 		hashType).Receive()
@@ -511,7 +514,7 @@ type FutureSearchRawTransactionsResult chan *response
 
 // Receive waits for the response promised by the future and returns the
 // found raw transactions.
-func (r FutureSearchRawTransactionsResult) Receive() ([]*wire.MsgTx, error) {
+func (r FutureSearchRawTransactionsResult) Receive() ([]*zcashwire.MsgTx, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
@@ -525,7 +528,7 @@ func (r FutureSearchRawTransactionsResult) Receive() ([]*wire.MsgTx, error) {
 	}
 
 	// Decode and deserialize each transaction.
-	msgTxns := make([]*wire.MsgTx, 0, len(searchRawTxnsResult))
+	msgTxns := make([]*zcashwire.MsgTx, 0, len(searchRawTxnsResult))
 	for _, hexTx := range searchRawTxnsResult {
 		// Decode the serialized transaction hex to raw bytes.
 		serializedTx, err := hex.DecodeString(hexTx)
@@ -534,7 +537,7 @@ func (r FutureSearchRawTransactionsResult) Receive() ([]*wire.MsgTx, error) {
 		}
 
 		// Deserialize the transaction and add it to the result slice.
-		var msgTx wire.MsgTx
+		var msgTx zcashwire.MsgTx
 		err = msgTx.Deserialize(bytes.NewReader(serializedTx))
 		if err != nil {
 			return nil, err
@@ -550,7 +553,7 @@ func (r FutureSearchRawTransactionsResult) Receive() ([]*wire.MsgTx, error) {
 // function on the returned instance.
 //
 // See SearchRawTransactions for the blocking version and more details.
-func (c *Client) SearchRawTransactionsAsync(address btcutil.Address, skip, count int, reverse bool, filterAddrs []string) FutureSearchRawTransactionsResult {
+func (c *Client) SearchRawTransactionsAsync(address zcashutil.Address, skip, count int, reverse bool, filterAddrs []string) FutureSearchRawTransactionsResult {
 	addr := address.EncodeAddress()
 	verbose := btcjson.Int(0)
 	cmd := btcjson.NewSearchRawTransactionsCmd(addr, verbose, &skip, &count,
@@ -561,11 +564,13 @@ func (c *Client) SearchRawTransactionsAsync(address btcutil.Address, skip, count
 // SearchRawTransactions returns transactions that involve the passed address.
 //
 // NOTE: Chain servers do not typically provide this capability unless it has
-// specifically been enabled.
+// specifically been enabled.  zcashd does not implement it; see
+// NewAddressHistory to page through the history of addresses using its
+// address index instead.
 //
 // See SearchRawTransactionsVerbose to retrieve a list of data structures with
 // information about the transactions instead of the transactions themselves.
-func (c *Client) SearchRawTransactions(address btcutil.Address, skip, count int, reverse bool, filterAddrs []string) ([]*wire.MsgTx, error) {
+func (c *Client) SearchRawTransactions(address zcashutil.Address, skip, count int, reverse bool, filterAddrs []string) ([]*zcashwire.MsgTx, error) {
 	return c.SearchRawTransactionsAsync(address, skip, count, reverse, filterAddrs).Receive()
 }
 
@@ -597,7 +602,7 @@ func (r FutureSearchRawTransactionsVerboseResult) Receive() ([]*btcjson.SearchRa
 // function on the returned instance.
 //
 // See SearchRawTransactionsVerbose for the blocking version and more details.
-func (c *Client) SearchRawTransactionsVerboseAsync(address btcutil.Address, skip,
+func (c *Client) SearchRawTransactionsVerboseAsync(address zcashutil.Address, skip,
 	count int, includePrevOut, reverse bool, filterAddrs *[]string) FutureSearchRawTransactionsVerboseResult {
 
 	addr := address.EncodeAddress()
@@ -618,9 +623,245 @@ func (c *Client) SearchRawTransactionsVerboseAsync(address btcutil.Address, skip
 // specifically been enabled.
 //
 // See SearchRawTransactions to retrieve a list of raw transactions instead.
-func (c *Client) SearchRawTransactionsVerbose(address btcutil.Address, skip,
+func (c *Client) SearchRawTransactionsVerbose(address zcashutil.Address, skip,
 	count int, includePrevOut, reverse bool, filterAddrs []string) ([]*btcjson.SearchRawTransactionsResult, error) {
 
 	return c.SearchRawTransactionsVerboseAsync(address, skip, count,
 		includePrevOut, reverse, &filterAddrs).Receive()
 }
+
+const (
+	// defaultAddressHistoryWindow is the default number of blocks covered by
+	// each page of an AddressHistory.
+	defaultAddressHistoryWindow = 1000
+
+	// maxReorgLength is the deepest reorganization zcashd accepts.  Blocks
+	// deeper than this below the tip are final, so address histories,
+	// the poller and wallet watchers only track the blocks above them.
+	maxReorgLength = 99
+)
+
+// AddressHistoryCheckpoint records how far an AddressHistory has progressed:
+// every delta up to and including the block at Height, whose hash is Hash, has
+// been delivered.  Callers may persist it to resume the history later.
+type AddressHistoryCheckpoint struct {
+	Height int64
+	Hash   *chainhash.Hash
+}
+
+// AddressHistoryPage is a page of the history of a set of addresses.
+type AddressHistoryPage struct {
+	// Deltas holds the balance changes of the addresses in the blocks from
+	// StartHeight to EndHeight, inclusive, ordered by height.
+	Deltas []zcashjson.AddressDelta
+
+	// StartHeight and EndHeight are the heights of the blocks covered by
+	// the page, and EndHash is the hash of the block at EndHeight.
+	StartHeight int64
+	EndHeight   int64
+	EndHash     *chainhash.Hash
+
+	// Rewound is set when blocks that were delivered by earlier pages have
+	// been reorganized out of the chain.  Such a page holds no deltas, and
+	// every delta above EndHeight that was delivered before must be
+	// discarded.  The history continues from EndHeight.
+	Rewound bool
+}
+
+// AddressHistory walks the history of a set of transparent addresses using the
+// address index of a node run with -insightexplorer.  Each call to Next returns
+// the balance changes of the addresses in the next window of blocks, checking
+// that the chain the earlier pages were read from is still the best chain.
+//
+// An AddressHistory is not safe for concurrent use.
+type AddressHistory struct {
+	client     *Client
+	addrs      []zcashutil.Address
+	window     int64
+	checkpoint AddressHistoryCheckpoint
+
+	// recent holds the checkpoints of the pages delivered within the
+	// reorganization limit of the latest one, oldest first.  They are the
+	// points the history can rewind to.
+	recent []AddressHistoryCheckpoint
+}
+
+// NewAddressHistory returns an AddressHistory over the passed addresses that
+// reads windowSize blocks per page.  A windowSize of zero uses a default of
+// 1000 blocks.  Passing nil for checkpoint starts from the genesis block,
+// otherwise the history resumes after the checkpoint.
+func (c *Client) NewAddressHistory(addrs []zcashutil.Address, windowSize int, checkpoint *AddressHistoryCheckpoint) *AddressHistory {
+	h := &AddressHistory{
+		client: c,
+		addrs:  addrs,
+		window: int64(windowSize),
+	}
+	if h.window <= 0 {
+		h.window = defaultAddressHistoryWindow
+	}
+	if checkpoint != nil {
+		h.checkpoint = *checkpoint
+	}
+	h.recent = append(h.recent, h.checkpoint)
+	return h
+}
+
+// Checkpoint returns the point the history has progressed to.
+func (h *AddressHistory) Checkpoint() AddressHistoryCheckpoint {
+	return h.checkpoint
+}
+
+// Next returns the next page of the history.  Once the history has been
+// delivered up to the best block, Next returns a nil page and no error, and
+// calling it again after new blocks are connected continues the history.
+//
+// When a reorganization has replaced blocks that were already delivered, Next
+// returns a page with Rewound set instead.
+func (h *AddressHistory) Next() (*AddressHistoryPage, error) {
+	tip, err := h.client.GetBlockCount()
+	if err != nil {
+		return nil, err
+	}
+
+	cp := h.checkpoint
+	if cp.Height > tip {
+		return h.rewind()
+	}
+	if cp.Height == tip {
+		// Make sure the last delivered block has not been replaced
+		// while the history was caught up.
+		if cp.Hash != nil {
+			hash, err := h.client.GetBlockHash(cp.Height)
+			if err != nil {
+				return nil, err
+			}
+			if !hash.IsEqual(cp.Hash) {
+				return h.rewind()
+			}
+		}
+		return nil, nil
+	}
+
+	start := cp.Height + 1
+	end := cp.Height + h.window
+	if end > tip {
+		end = tip
+	}
+	res, err := h.client.GetAddressDeltasChainInfo(h.addrs, int(start),
+		int(end))
+	if err != nil {
+		return nil, err
+	}
+
+	// The first block of the page must build on the last delivered block,
+	// otherwise the chain was reorganized since the previous page.
+	if cp.Hash != nil {
+		startHash, err := chainhash.NewHashFromStr(res.Start.Hash)
+		if err != nil {
+			return nil, err
+		}
+		header, err := h.client.GetBlockHeaderVerbose(startHash)
+		if err != nil {
+			return nil, err
+		}
+		if header.PreviousHash != cp.Hash.String() {
+			return h.rewind()
+		}
+	}
+
+	endHash, err := chainhash.NewHashFromStr(res.End.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	deltas := res.Deltas
+	sort.Stable(deltasByHeight(deltas))
+
+	h.advance(AddressHistoryCheckpoint{Height: end, Hash: endHash})
+	return &AddressHistoryPage{
+		Deltas:      deltas,
+		StartHeight: start,
+		EndHeight:   end,
+		EndHash:     endHash,
+	}, nil
+}
+
+// advance moves the history to the checkpoint and forgets the checkpoints that
+// can no longer be reorganized.
+func (h *AddressHistory) advance(cp AddressHistoryCheckpoint) {
+	h.checkpoint = cp
+	h.recent = append(h.recent, cp)
+
+	final := 0
+	for final < len(h.recent)-1 &&
+		h.recent[final].Height < cp.Height-maxReorgLength {
+		final++
+	}
+	h.recent = h.recent[final:]
+}
+
+// rewind moves the history back to the latest delivered checkpoint that is
+// still in the best chain and returns the page reporting it.
+func (h *AddressHistory) rewind() (*AddressHistoryPage, error) {
+	tip, err := h.client.GetBlockCount()
+	if err != nil {
+		return nil, err
+	}
+
+	for len(h.recent) > 0 {
+		cp := h.recent[len(h.recent)-1]
+		if cp.Hash == nil {
+			// Only the starting point is unverified, and history
+			// before it was never delivered.
+			return h.rewound(cp), nil
+		}
+		if cp.Height <= tip {
+			hash, err := h.client.GetBlockHash(cp.Height)
+			if err != nil {
+				return nil, err
+			}
+			if hash.IsEqual(cp.Hash) {
+				return h.rewound(cp), nil
+			}
+		}
+		h.recent = h.recent[:len(h.recent)-1]
+	}
+
+	// None of the remembered checkpoints are in the best chain, so go back
+	// far enough that the block must be.
+	height := h.checkpoint.Height - maxReorgLength - 1
+	if height > tip {
+		height = tip
+	}
+	if height < 0 {
+		height = 0
+	}
+	hash, err := h.client.GetBlockHash(height)
+	if err != nil {
+		return nil, err
+	}
+	return h.rewound(AddressHistoryCheckpoint{Height: height, Hash: hash}), nil
+}
+
+// rewound moves the history back to the checkpoint and returns the page
+// reporting it.
+func (h *AddressHistory) rewound(cp AddressHistoryCheckpoint) *AddressHistoryPage {
+	h.checkpoint = cp
+	if len(h.recent) == 0 || h.recent[len(h.recent)-1] != cp {
+		h.recent = append(h.recent, cp)
+	}
+	return &AddressHistoryPage{
+		StartHeight: cp.Height,
+		EndHeight:   cp.Height,
+		EndHash:     cp.Hash,
+		Rewound:     true,
+	}
+}
+
+// deltasByHeight implements sort.Interface to order address deltas by the
+// height of their block.
+type deltasByHeight []zcashjson.AddressDelta
+
+func (s deltasByHeight) Len() int           { return len(s) }
+func (s deltasByHeight) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s deltasByHeight) Less(i, j int) bool { return s[i].Height < s[j].Height }
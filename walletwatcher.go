@@ -0,0 +1,1030 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashrpcclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/arithmetric/zcashrpcclient/zcashjson"
+	"github.com/arithmetric/zcashrpcclient/zcashutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+)
+
+const (
+	// defaultWalletPollInterval is the default delay between polls of the
+	// wallet.
+	defaultWalletPollInterval = 15 * time.Second
+
+	// defaultWalletConfirmations is the default number of confirmations
+	// after which the confirmations of a note are no longer notified.
+	defaultWalletConfirmations = 10
+
+	// maxWalletConfirmations is the largest number of confirmations
+	// accepted by z_listunspent.
+	maxWalletConfirmations = 9999999
+)
+
+// ErrWalletReorgTooDeep is an error to describe the condition where the best
+// chain was reorganized below the blocks a wallet watcher remembers, so the
+// events it delivered can no longer be retracted.  A watcher stops polling
+// once it reports it.
+var ErrWalletReorgTooDeep = errors.New("the best chain was reorganized " +
+	"below the blocks remembered by the wallet watcher")
+
+// WalletEventType identifies the kind of a WalletEvent.
+type WalletEventType int
+
+// These constants define the kinds of wallet events.
+const (
+	// NoteReceived indicates a note paying a watched address was mined.
+	NoteReceived WalletEventType = iota
+
+	// NoteConfirmed indicates the number of confirmations of a received
+	// note changed.
+	NoteConfirmed
+
+	// NoteSpent indicates the wallet no longer lists a received note as
+	// unspent.
+	NoteSpent
+)
+
+// walletEventTypeStrings is a map of wallet event types back to their constant
+// names for pretty printing.
+var walletEventTypeStrings = map[WalletEventType]string{
+	NoteReceived:  "NoteReceived",
+	NoteConfirmed: "NoteConfirmed",
+	NoteSpent:     "NoteSpent",
+}
+
+// String returns the WalletEventType in human-readable form.
+func (t WalletEventType) String() string {
+	if s, ok := walletEventTypeStrings[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown WalletEventType (%d)", int(t))
+}
+
+// WalletNote describes a shielded note received by a watched address.  For
+// Sprout notes JSIndex and OutIndex are the JoinSplit and its output, for
+// Sapling notes OutIndex is the output, and for Orchard notes it is the
+// action.
+type WalletNote struct {
+	TxID     chainhash.Hash
+	Pool     zcashjson.ValuePool
+	JSIndex  int
+	OutIndex int
+	Address  string
+	Amount   btcutil.Amount
+	Memo     []byte
+	Change   bool
+
+	// Height is the height of the block the note was mined in.
+	Height int32
+}
+
+// noteKey identifies a note.
+type noteKey struct {
+	txid     chainhash.Hash
+	pool     zcashjson.ValuePool
+	jsIndex  int
+	outIndex int
+}
+
+// key returns the key identifying the note.
+func (n *WalletNote) key() noteKey {
+	return noteKey{
+		txid:     n.TxID,
+		pool:     n.Pool,
+		jsIndex:  n.JSIndex,
+		outIndex: n.OutIndex,
+	}
+}
+
+// walletNotes implements sort.Interface to order notes by the height they were
+// mined at and then by their position in the block.
+type walletNotes []*WalletNote
+
+func (s walletNotes) Len() int      { return len(s) }
+func (s walletNotes) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s walletNotes) Less(i, j int) bool {
+	a, b := s[i], s[j]
+	if a.Height != b.Height {
+		return a.Height < b.Height
+	}
+	if a.TxID != b.TxID {
+		return a.TxID.String() < b.TxID.String()
+	}
+	if a.Pool != b.Pool {
+		return a.Pool < b.Pool
+	}
+	if a.JSIndex != b.JSIndex {
+		return a.JSIndex < b.JSIndex
+	}
+	return a.OutIndex < b.OutIndex
+}
+
+// WalletEvent describes a change to a note received by a watched address.
+type WalletEvent struct {
+	Type WalletEventType
+	Note WalletNote
+
+	// Height is the height of the block the event happened at, and
+	// Confirmations is the number of confirmations of the note at it.
+	Height        int32
+	Confirmations int32
+
+	// Retracted is set when the event was previously delivered at a block
+	// which has since been disconnected from the best chain, and must be
+	// undone.
+	Retracted bool
+}
+
+// WalletEventBatch holds the events of a block of the best chain.  Batches are
+// delivered in order of height, once for each block.  When the best chain is
+// reorganized, a batch for the fork point retracts the events delivered at
+// the disconnected blocks, before the blocks of the new chain are delivered.
+type WalletEventBatch struct {
+	Height int32
+	Hash   chainhash.Hash
+	Events []WalletEvent
+
+	// Cursor is the position of the watcher after the batch.  Persisting
+	// it together with the effects of the events, and passing it to the
+	// watcher on restart, delivers every batch exactly once.
+	Cursor *WalletCursor
+}
+
+// WalletCursor is the position of a wallet watcher in the best chain.  It
+// includes the unspent notes and the events of the most recent blocks, so
+// a restarted watcher is able to detect spends and retract events.  It is
+// persisted by encoding it to JSON.
+type WalletCursor struct {
+	// height is the height of the most recent block, and blocks holds the
+	// hashes of the most recent blocks in ascending order of height.
+	height int32
+	blocks []chainhash.Hash
+
+	// notes holds the unspent notes received by the watched addresses.
+	notes map[noteKey]*WalletNote
+
+	// journal holds the events delivered at the blocks in blocks, in the
+	// order they were delivered.
+	journal []WalletEvent
+}
+
+// Height returns the height of the most recent block of the cursor.
+func (c *WalletCursor) Height() int32 {
+	return c.height
+}
+
+// Hash returns the hash of the most recent block of the cursor.
+func (c *WalletCursor) Hash() chainhash.Hash {
+	return c.blocks[len(c.blocks)-1]
+}
+
+// copy returns a deep copy of the cursor.
+func (c *WalletCursor) copy() *WalletCursor {
+	cursor := &WalletCursor{
+		height:  c.height,
+		blocks:  append([]chainhash.Hash(nil), c.blocks...),
+		notes:   make(map[noteKey]*WalletNote, len(c.notes)),
+		journal: append([]WalletEvent(nil), c.journal...),
+	}
+	for key, note := range c.notes {
+		cursor.notes[key] = note
+	}
+	return cursor
+}
+
+// sortedNotes returns the unspent notes in a deterministic order.
+func (c *WalletCursor) sortedNotes() walletNotes {
+	notes := make(walletNotes, 0, len(c.notes))
+	for _, note := range c.notes {
+		notes = append(notes, note)
+	}
+	sort.Sort(notes)
+	return notes
+}
+
+// walletNoteJSON is the JSON encoding of a WalletNote in a cursor.
+type walletNoteJSON struct {
+	TxID     string              `json:"txid"`
+	Pool     zcashjson.ValuePool `json:"pool"`
+	JSIndex  int                 `json:"jsindex,omitempty"`
+	OutIndex int                 `json:"outindex"`
+	Address  string              `json:"address"`
+	Amount   int64               `json:"amount"`
+	Memo     []byte              `json:"memo,omitempty"`
+	Change   bool                `json:"change,omitempty"`
+	Height   int32               `json:"height"`
+}
+
+// walletEventJSON is the JSON encoding of a WalletEvent in a cursor.
+type walletEventJSON struct {
+	Type          WalletEventType `json:"type"`
+	Note          walletNoteJSON  `json:"note"`
+	Height        int32           `json:"height"`
+	Confirmations int32           `json:"confirmations"`
+}
+
+// walletCursorJSON is the JSON encoding of a WalletCursor.
+type walletCursorJSON struct {
+	Height  int32             `json:"height"`
+	Blocks  []string          `json:"blocks"`
+	Notes   []walletNoteJSON  `json:"notes"`
+	Journal []walletEventJSON `json:"journal"`
+}
+
+// newWalletNoteJSON returns the JSON encoding of the note.
+func newWalletNoteJSON(note *WalletNote) walletNoteJSON {
+	return walletNoteJSON{
+		TxID:     note.TxID.String(),
+		Pool:     note.Pool,
+		JSIndex:  note.JSIndex,
+		OutIndex: note.OutIndex,
+		Address:  note.Address,
+		Amount:   int64(note.Amount),
+		Memo:     note.Memo,
+		Change:   note.Change,
+		Height:   note.Height,
+	}
+}
+
+// note decodes the note from its JSON encoding.
+func (n *walletNoteJSON) note() (*WalletNote, error) {
+	txid, err := chainhash.NewHashFromStr(n.TxID)
+	if err != nil {
+		return nil, err
+	}
+	return &WalletNote{
+		TxID:     *txid,
+		Pool:     n.Pool,
+		JSIndex:  n.JSIndex,
+		OutIndex: n.OutIndex,
+		Address:  n.Address,
+		Amount:   btcutil.Amount(n.Amount),
+		Memo:     n.Memo,
+		Change:   n.Change,
+		Height:   n.Height,
+	}, nil
+}
+
+// MarshalJSON encodes the cursor to JSON.
+func (c *WalletCursor) MarshalJSON() ([]byte, error) {
+	raw := walletCursorJSON{
+		Height:  c.height,
+		Blocks:  make([]string, 0, len(c.blocks)),
+		Notes:   make([]walletNoteJSON, 0, len(c.notes)),
+		Journal: make([]walletEventJSON, 0, len(c.journal)),
+	}
+	for i := range c.blocks {
+		raw.Blocks = append(raw.Blocks, c.blocks[i].String())
+	}
+	for _, note := range c.sortedNotes() {
+		raw.Notes = append(raw.Notes, newWalletNoteJSON(note))
+	}
+	for i := range c.journal {
+		event := &c.journal[i]
+		raw.Journal = append(raw.Journal, walletEventJSON{
+			Type:          event.Type,
+			Note:          newWalletNoteJSON(&event.Note),
+			Height:        event.Height,
+			Confirmations: event.Confirmations,
+		})
+	}
+	return json.Marshal(&raw)
+}
+
+// UnmarshalJSON decodes the cursor from JSON.
+func (c *WalletCursor) UnmarshalJSON(b []byte) error {
+	var raw walletCursorJSON
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if len(raw.Blocks) == 0 {
+		return errors.New("wallet cursor has no blocks")
+	}
+
+	cursor := WalletCursor{
+		height:  raw.Height,
+		blocks:  make([]chainhash.Hash, len(raw.Blocks)),
+		notes:   make(map[noteKey]*WalletNote, len(raw.Notes)),
+		journal: make([]WalletEvent, 0, len(raw.Journal)),
+	}
+	for i, block := range raw.Blocks {
+		if err := chainhash.Decode(&cursor.blocks[i], block); err != nil {
+			return err
+		}
+	}
+	for i := range raw.Notes {
+		note, err := raw.Notes[i].note()
+		if err != nil {
+			return err
+		}
+		cursor.notes[note.key()] = note
+	}
+	for i := range raw.Journal {
+		event := &raw.Journal[i]
+		note, err := event.Note.note()
+		if err != nil {
+			return err
+		}
+		cursor.journal = append(cursor.journal, WalletEvent{
+			Type:          event.Type,
+			Note:          *note,
+			Height:        event.Height,
+			Confirmations: event.Confirmations,
+		})
+	}
+
+	*c = cursor
+	return nil
+}
+
+// rollback returns the cursor at the passed height, which must be one of its
+// blocks, and the events retracted to reach it.
+func (c *WalletCursor) rollback(height int32) (*WalletCursor, []WalletEvent) {
+	cursor := c.copy()
+
+	var retracted []WalletEvent
+	i := len(cursor.journal)
+	for ; i > 0 && cursor.journal[i-1].Height > height; i-- {
+		event := cursor.journal[i-1]
+		note := event.Note
+		switch event.Type {
+		case NoteReceived:
+			delete(cursor.notes, note.key())
+		case NoteSpent:
+			cursor.notes[note.key()] = &note
+		}
+		event.Retracted = true
+		retracted = append(retracted, event)
+	}
+	cursor.journal = cursor.journal[:i]
+	cursor.blocks = cursor.blocks[:len(cursor.blocks)-int(c.height-height)]
+	cursor.height = height
+	return cursor, retracted
+}
+
+// advance returns the cursor at the next block, which has the passed hash, and
+// the events that happened at it.  The passed notes are the notes received by
+// the watched addresses at any height.  When unspent is not nil, it holds the
+// keys of the unspent notes, and the notes which are not in it are spent at
+// the block.
+func (c *WalletCursor) advance(hash *chainhash.Hash, received walletNotes,
+	unspent map[noteKey]struct{}, confirmations int32) (*WalletCursor, []WalletEvent) {
+
+	cursor := c.copy()
+	cursor.height++
+	height := cursor.height
+
+	var events []WalletEvent
+	for _, note := range received {
+		if note.Height != height {
+			continue
+		}
+		if _, ok := cursor.notes[note.key()]; ok {
+			continue
+		}
+		cursor.notes[note.key()] = note
+		events = append(events, WalletEvent{
+			Type:          NoteReceived,
+			Note:          *note,
+			Height:        height,
+			Confirmations: 1,
+		})
+	}
+
+	notes := cursor.sortedNotes()
+	for _, note := range notes {
+		confs := height - note.Height + 1
+		if confs < 2 || confs > confirmations {
+			continue
+		}
+		events = append(events, WalletEvent{
+			Type:          NoteConfirmed,
+			Note:          *note,
+			Height:        height,
+			Confirmations: confs,
+		})
+	}
+
+	if unspent != nil {
+		for _, note := range notes {
+			if _, ok := unspent[note.key()]; ok {
+				continue
+			}
+			delete(cursor.notes, note.key())
+			events = append(events, WalletEvent{
+				Type:          NoteSpent,
+				Note:          *note,
+				Height:        height,
+				Confirmations: height - note.Height + 1,
+			})
+		}
+	}
+
+	// Only the events of the remembered blocks can be retracted.
+	cursor.blocks = append(cursor.blocks, *hash)
+	cursor.journal = append(cursor.journal, events...)
+	if len(cursor.blocks) > maxReorgLength+1 {
+		cursor.blocks = cursor.blocks[len(cursor.blocks)-maxReorgLength-1:]
+		oldest := height - maxReorgLength
+		i := 0
+		for i < len(cursor.journal) && cursor.journal[i].Height <= oldest {
+			i++
+		}
+		cursor.journal = cursor.journal[i:]
+	}
+	return cursor, events
+}
+
+// WalletWatcherConfig describes the addresses watched by a WalletWatcher and
+// how its events are delivered.
+type WalletWatcherConfig struct {
+	// Addresses are the shielded and unified addresses whose notes are
+	// watched.  Their notes must be known to the wallet of the server,
+	// either as spending or viewing keys.
+	Addresses []zcashutil.Address
+
+	// OnEvents is invoked with the events of each block, in order of
+	// height.  When it returns an error, the batch is delivered again at
+	// the next poll.  It must be set.
+	OnEvents func(batch *WalletEventBatch) error
+
+	// OnError is invoked, when it is set, with the errors that prevent a
+	// poll from delivering events, which are otherwise only logged.  The
+	// poll is retried at the next interval, except after
+	// ErrWalletReorgTooDeep, which stops the watcher since the events
+	// delivered after the fork can no longer be retracted.  To recover,
+	// the effects of the events delivered by the watcher must be discarded
+	// and a new watcher started without a cursor, from a StartHeight below
+	// the fork such as the one the first watcher started from.
+	OnError func(err error)
+
+	// Cursor is the cursor of the last batch processed before the watcher
+	// was restarted, and events are delivered from the block after it.
+	Cursor *WalletCursor
+
+	// StartHeight is the height events are delivered from when Cursor is
+	// nil.  Zero starts from the block after the current best block.  The
+	// unspent notes received before it are tracked without delivering
+	// their receipt.
+	StartHeight int32
+
+	// Confirmations is the number of confirmations up to which a change
+	// of the confirmations of a note is delivered.  Zero uses a default
+	// of 10.
+	Confirmations int32
+
+	// PollInterval is the delay between polls of the wallet.  Zero uses a
+	// default of 15 seconds.
+	PollInterval time.Duration
+}
+
+// WalletWatcher delivers the notes received and spent by shielded and unified
+// addresses of the wallet, which NotifyReceived and OnRecvTx do not support.
+// It polls z_listreceivedbyaddress for each address and z_listunspent, and
+// delivers the changes as a batch of events for each block of the best chain.
+//
+// Since the wallet only lists the notes that are currently unspent, spends
+// are delivered at the block the watcher notices them at, which may include
+// spends by transactions still in the memory pool.  A note received and spent
+// between two polls is delivered as received at the block it was mined in and
+// as spent at the best block.
+//
+// A WalletWatcher must be stopped with Stop once it is no longer needed.
+type WalletWatcher struct {
+	client        *Client
+	addresses     []zcashutil.Address
+	onEvents      func(batch *WalletEventBatch) error
+	onError       func(err error)
+	startHeight   int32
+	confirmations int32
+	interval      time.Duration
+
+	mtx    sync.Mutex
+	cursor *WalletCursor
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewWalletWatcher returns a new WalletWatcher that polls the wallet of the
+// server the client is connected to.
+func (c *Client) NewWalletWatcher(config *WalletWatcherConfig) *WalletWatcher {
+	w := &WalletWatcher{
+		client:        c,
+		addresses:     config.Addresses,
+		onEvents:      config.OnEvents,
+		onError:       config.OnError,
+		startHeight:   config.StartHeight,
+		confirmations: defaultWalletConfirmations,
+		interval:      defaultWalletPollInterval,
+		quit:          make(chan struct{}),
+	}
+	if config.Cursor != nil {
+		w.cursor = config.Cursor.copy()
+	}
+	if config.Confirmations > 0 {
+		w.confirmations = config.Confirmations
+	}
+	if config.PollInterval > 0 {
+		w.interval = config.PollInterval
+	}
+
+	w.wg.Add(1)
+	go w.pollHandler()
+	return w
+}
+
+// Cursor returns the cursor of the last batch delivered, or nil before the
+// first poll.
+func (w *WalletWatcher) Cursor() *WalletCursor {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if w.cursor == nil {
+		return nil
+	}
+	return w.cursor.copy()
+}
+
+// Stop stops polling the wallet.  It waits for a batch being delivered.
+func (w *WalletWatcher) Stop() {
+	select {
+	case <-w.quit:
+	default:
+		close(w.quit)
+	}
+	w.wg.Wait()
+}
+
+// pollHandler polls the wallet at the configured interval until the watcher is
+// stopped, the client shuts down, or the best chain is reorganized below the
+// cursor.  It must be run as a goroutine.
+func (w *WalletWatcher) pollHandler() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.poll(); err != nil {
+			if w.onError != nil {
+				w.onError(err)
+			}
+			if err == ErrWalletReorgTooDeep {
+				log.Errorf("Stopped polling the wallet: %v", err)
+				return
+			}
+			log.Warnf("Failed to poll the wallet: %v", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-w.client.shutdown:
+			return
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// setCursor advances the watcher to the passed cursor.
+func (w *WalletWatcher) setCursor(cursor *WalletCursor) {
+	w.mtx.Lock()
+	w.cursor = cursor
+	w.mtx.Unlock()
+}
+
+// deliver passes the events of a block to the handler and advances the watcher
+// to the cursor after them when it succeeds.
+func (w *WalletWatcher) deliver(hash *chainhash.Hash, events []WalletEvent,
+	cursor *WalletCursor) error {
+
+	batch := &WalletEventBatch{
+		Height: cursor.height,
+		Hash:   *hash,
+		Events: events,
+		Cursor: cursor.copy(),
+	}
+	if err := w.onEvents(batch); err != nil {
+		return err
+	}
+	w.setCursor(cursor)
+	return nil
+}
+
+// findFork returns the height of the most recent block of the cursor that is
+// still part of the best chain with the passed height.
+func (w *WalletWatcher) findFork(cursor *WalletCursor, bestHeight int32) (int32, error) {
+	oldest := cursor.height - int32(len(cursor.blocks)) + 1
+	for height := cursor.height; height >= oldest; height-- {
+		if height > bestHeight {
+			continue
+		}
+		hash, err := w.client.GetBlockHash(int64(height))
+		if err != nil {
+			return 0, err
+		}
+		if *hash == cursor.blocks[height-oldest] {
+			return height, nil
+		}
+	}
+	return 0, ErrWalletReorgTooDeep
+}
+
+// poll delivers the events of the blocks connected to the best chain since the
+// cursor, after retracting the events of the blocks disconnected from it.
+func (w *WalletWatcher) poll() error {
+	c := w.client
+	bestHash, err := c.GetBestBlockHash()
+	if err != nil {
+		return err
+	}
+	best, err := c.GetBlockHeaderVerbose(bestHash)
+	if err != nil {
+		return err
+	}
+	bestHeight := best.Height
+
+	w.mtx.Lock()
+	cursor := w.cursor
+	w.mtx.Unlock()
+
+	// Start from the block before the start height, or the best block.
+	if cursor == nil {
+		height := bestHeight
+		if w.startHeight > 0 && w.startHeight <= bestHeight {
+			height = w.startHeight - 1
+		}
+		hash, err := c.GetBlockHash(int64(height))
+		if err != nil {
+			return err
+		}
+		cursor = &WalletCursor{
+			height: height,
+			blocks: []chainhash.Hash{*hash},
+			notes:  make(map[noteKey]*WalletNote),
+		}
+		w.setCursor(cursor)
+	}
+
+	// Retract the events of the blocks disconnected by a reorganization.
+	fork, err := w.findFork(cursor, bestHeight)
+	if err != nil {
+		return err
+	}
+	if fork != cursor.height {
+		next, retracted := cursor.rollback(fork)
+		hash := next.Hash()
+		if err := w.deliver(&hash, retracted, next); err != nil {
+			return err
+		}
+		cursor = next
+	}
+	if bestHeight <= cursor.height {
+		return nil
+	}
+
+	hashes, err := w.fetchBlockHashes(cursor.height+1, bestHeight)
+	if err != nil {
+		return err
+	}
+	received, unspent, err := w.fetchNotes(cursor, hashes)
+	if err != nil {
+		return err
+	}
+
+	// Unspent notes received at the blocks already delivered, such as
+	// those before the start height, are tracked without events.
+	recorded := false
+	for _, note := range received {
+		if note.Height > cursor.height {
+			continue
+		}
+		if _, ok := unspent[note.key()]; !ok {
+			continue
+		}
+		if _, ok := cursor.notes[note.key()]; !ok {
+			if !recorded {
+				cursor = cursor.copy()
+				recorded = true
+			}
+			cursor.notes[note.key()] = note
+		}
+	}
+
+	for i := range hashes {
+		// Spends are only known at the best block.
+		var bestUnspent map[noteKey]struct{}
+		if i == len(hashes)-1 {
+			bestUnspent = unspent
+		}
+		next, events := cursor.advance(&hashes[i], received,
+			bestUnspent, w.confirmations)
+		if err := w.deliver(&hashes[i], events, next); err != nil {
+			return err
+		}
+		cursor = next
+	}
+	return nil
+}
+
+// fetchNotes returns the notes received by the watched addresses which the
+// cursor does not track and which are either mined after it or unspent, and
+// the keys of the unspent notes.  The passed hashes are those of the blocks
+// after the cursor.
+//
+// The receipts are listed with z_listreceivedbyaddress, which includes spent
+// notes, and the spends with z_listunspent.  Nodes which list receipts without
+// the height of their block only report their confirmations, so the blocks of
+// those transactions are looked up, limited to the transactions which may be
+// mined after the cursor and which listsinceblock does not already place.
+func (w *WalletWatcher) fetchNotes(cursor *WalletCursor,
+	hashes []chainhash.Hash) (walletNotes, map[noteKey]struct{}, error) {
+
+	c := w.client
+	receivedFutures := make([]FutureZListReceivedByAddressResult, 0,
+		len(w.addresses))
+	for _, addr := range w.addresses {
+		receivedFutures = append(receivedFutures,
+			c.ZListReceivedByAddressAsync(addr))
+	}
+	unspentFuture := c.ZListUnspentMinMaxAddressesAsync(1,
+		maxWalletConfirmations, true, w.addresses)
+
+	results, err := unspentFuture.Receive()
+	if err != nil {
+		return nil, nil, err
+	}
+	unspent := make(map[noteKey]struct{}, len(results))
+	for i := range results {
+		key, err := unspentNoteKey(&results[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		unspent[key] = struct{}{}
+	}
+
+	var received walletNotes
+	var unplaced []*unplacedWalletNote
+	for i, future := range receivedFutures {
+		results, err := future.Receive()
+		if err != nil {
+			return nil, nil, err
+		}
+		address := w.addresses[i].EncodeAddress()
+		for j := range results {
+			result := &results[j]
+			note, err := newReceivedWalletNote(result, address)
+			if err != nil {
+				return nil, nil, err
+			}
+			if _, ok := cursor.notes[note.key()]; ok {
+				continue
+			}
+			switch {
+			case result.BlockHeight != nil:
+				note.Height = *result.BlockHeight
+			case result.Confirmations > 0:
+				unplaced = append(unplaced, &unplacedWalletNote{
+					note:          note,
+					confirmations: result.Confirmations,
+				})
+				continue
+			default:
+				// The note is not mined yet.
+				continue
+			}
+			_, isUnspent := unspent[note.key()]
+			if note.Height > cursor.height || isUnspent {
+				received = append(received, note)
+			}
+		}
+	}
+
+	if len(unplaced) != 0 {
+		placed, err := w.placeNotes(cursor, hashes, unplaced, unspent)
+		if err != nil {
+			return nil, nil, err
+		}
+		received = append(received, placed...)
+	}
+	sort.Sort(received)
+	return received, unspent, nil
+}
+
+// unplacedWalletNote is a received note listed without the height of its
+// block.
+type unplacedWalletNote struct {
+	note          *WalletNote
+	confirmations int64
+}
+
+// placeNotes sets the heights of the passed notes, which are listed without
+// them, and returns those which are mined after the cursor or unspent.  The
+// passed hashes are those of the blocks after the cursor.
+func (w *WalletWatcher) placeNotes(cursor *WalletCursor,
+	hashes []chainhash.Hash, unplaced []*unplacedWalletNote,
+	unspent map[noteKey]struct{}) (walletNotes, error) {
+
+	c := w.client
+	cursorHash := cursor.Hash()
+	sinceFuture := c.ListSinceBlockAsync(&cursorHash)
+	countFuture := c.GetBlockCountAsync()
+
+	heights := make(map[chainhash.Hash]int32, len(hashes))
+	for i := range hashes {
+		heights[hashes[i]] = cursor.height + 1 + int32(i)
+	}
+
+	// The transactions listsinceblock lists at the blocks after the
+	// cursor are placed without looking them up.
+	since, err := sinceFuture.Receive()
+	if err != nil {
+		return nil, err
+	}
+	txHeights := make(map[chainhash.Hash]int32)
+	for i := range since.Transactions {
+		tx := &since.Transactions[i]
+		blockHash, err := chainhash.NewHashFromStr(tx.BlockHash)
+		if err != nil {
+			continue
+		}
+		height, ok := heights[*blockHash]
+		if !ok {
+			continue
+		}
+		txid, err := chainhash.NewHashFromStr(tx.TxID)
+		if err != nil {
+			return nil, err
+		}
+		txHeights[*txid] = height
+	}
+
+	// The confirmations of the notes were counted at a best block no
+	// higher than the current one, so a note with more confirmations
+	// than blocks were connected since the cursor is mined at or below
+	// it.  Such notes are only tracked when they are unspent.
+	count, err := countFuture.Receive()
+	if err != nil {
+		return nil, err
+	}
+	lookupFutures := make(map[chainhash.Hash]FutureGetTransactionResult)
+	var lookup []chainhash.Hash
+	for _, u := range unplaced {
+		txid := u.note.TxID
+		if _, ok := txHeights[txid]; ok {
+			continue
+		}
+		if _, ok := lookupFutures[txid]; ok {
+			continue
+		}
+		_, isUnspent := unspent[u.note.key()]
+		if !isUnspent && u.confirmations > count-int64(cursor.height) {
+			continue
+		}
+		lookup = append(lookup, txid)
+		lookupFutures[txid] = c.GetTransactionAsync(&txid)
+	}
+	for _, txid := range lookup {
+		tx, err := lookupFutures[txid].Receive()
+		if err != nil {
+			return nil, err
+		}
+		if tx.BlockHash == "" {
+			continue
+		}
+		blockHash, err := chainhash.NewHashFromStr(tx.BlockHash)
+		if err != nil {
+			return nil, err
+		}
+		height, ok := heights[*blockHash]
+		if !ok {
+			header, err := c.GetBlockHeaderVerbose(blockHash)
+			if err != nil {
+				return nil, err
+			}
+			height = header.Height
+			heights[*blockHash] = height
+		}
+		txHeights[txid] = height
+	}
+
+	var placed walletNotes
+	for _, u := range unplaced {
+		height, ok := txHeights[u.note.TxID]
+		if !ok {
+			continue
+		}
+		u.note.Height = height
+		_, isUnspent := unspent[u.note.key()]
+		if height > cursor.height || isUnspent {
+			placed = append(placed, u.note)
+		}
+	}
+	return placed, nil
+}
+
+// fetchBlockHashes returns the hashes of the blocks of the best chain from the
+// start height through the end height.
+func (w *WalletWatcher) fetchBlockHashes(startHeight, endHeight int32) ([]chainhash.Hash, error) {
+	hashes := make([]chainhash.Hash, 0, endHeight-startHeight+1)
+	for height := startHeight; height <= endHeight; height += headerBatchSize {
+		batchEnd := height + headerBatchSize - 1
+		if batchEnd > endHeight {
+			batchEnd = endHeight
+		}
+
+		futures := make([]FutureGetBlockHashResult, 0, batchEnd-height+1)
+		for h := height; h <= batchEnd; h++ {
+			futures = append(futures, w.client.GetBlockHashAsync(int64(h)))
+		}
+		for _, future := range futures {
+			hash, err := future.Receive()
+			if err != nil {
+				return nil, err
+			}
+			hashes = append(hashes, *hash)
+		}
+	}
+	return hashes, nil
+}
+
+// intValue returns the value of an optional index, or zero when it is not set.
+func intValue(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+// walletNoteKey returns the key of a note from the indices zcashd lists it
+// with.  Sprout notes are identified by their JoinSplit and its output,
+// Sapling notes by their output and Orchard notes by their action.
+func walletNoteKey(txid string, pool zcashjson.ValuePool, jsIndex, jsOutIndex,
+	outIndex, actionIndex *int) (noteKey, error) {
+
+	hash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return noteKey{}, err
+	}
+	key := noteKey{txid: *hash, pool: pool}
+	switch pool {
+	case zcashjson.PoolSprout:
+		key.jsIndex = intValue(jsIndex)
+		key.outIndex = intValue(jsOutIndex)
+	case zcashjson.PoolOrchard:
+		key.outIndex = intValue(actionIndex)
+	default:
+		key.outIndex = intValue(outIndex)
+	}
+	return key, nil
+}
+
+// newReceivedWalletNote returns the note described by a
+// z_listreceivedbyaddress result for the passed address.  Its height is left
+// for the caller to set.
+func newReceivedWalletNote(result *zcashjson.ZListReceivedByAddressResult,
+	address string) (*WalletNote, error) {
+
+	key, err := walletNoteKey(result.TxID, result.Pool, result.JSIndex,
+		result.JSOutIndex, result.OutIndex, result.ActionIndex)
+	if err != nil {
+		return nil, err
+	}
+	note := &WalletNote{
+		TxID:     key.txid,
+		Pool:     key.pool,
+		JSIndex:  key.jsIndex,
+		OutIndex: key.outIndex,
+		Address:  address,
+		Amount:   btcutil.Amount(result.AmountZat),
+		Memo:     result.Memo,
+		Change:   result.Change,
+	}
+	if result.AmountZat == 0 {
+		note.Amount, err = btcutil.NewAmount(result.Amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return note, nil
+}
+
+// unspentNoteKey returns the key of the note described by a z_listunspent
+// result.
+func unspentNoteKey(result *zcashjson.ZListUnspentResult) (noteKey, error) {
+	return walletNoteKey(result.TxID, result.Pool, result.JSIndex,
+		result.JSOutIndex, result.OutIndex, result.ActionIndex)
+}
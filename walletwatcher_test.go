@@ -0,0 +1,829 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashrpcclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashjson"
+	"github.com/arithmetric/zcashrpcclient/zcashutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// walletTestHash returns the hash standing for the block or transaction with
+// the passed label.
+func walletTestHash(label string) chainhash.Hash {
+	return chainhash.DoubleHashH([]byte("wallet " + label))
+}
+
+// walletTestNote returns a Sapling note of the transaction with the passed
+// label mined at the passed height.
+func walletTestNote(label string, height int32) *WalletNote {
+	return &WalletNote{
+		TxID:    walletTestHash(label),
+		Pool:    zcashjson.PoolSapling,
+		Address: "zs1test",
+		Amount:  100000,
+		Height:  height,
+	}
+}
+
+// walletTestCursor returns a cursor at the block with the passed height and
+// label, which tracks no notes.
+func walletTestCursor(height int32, label string) *WalletCursor {
+	return &WalletCursor{
+		height: height,
+		blocks: []chainhash.Hash{walletTestHash(label)},
+		notes:  make(map[noteKey]*WalletNote),
+	}
+}
+
+// walletEventString describes an event with the labels of its transactions.
+func walletEventString(event *WalletEvent, labels map[chainhash.Hash]string) string {
+	s := fmt.Sprintf("%v %s %d", event.Type, labels[event.Note.TxID],
+		event.Confirmations)
+	if event.Retracted {
+		s += " retracted"
+	}
+	return s
+}
+
+// advanceTestCursor advances the cursor through the blocks with the passed
+// labels, receiving the passed notes and spending every tracked note which is
+// not in unspent at the last block.
+func advanceTestCursor(cursor *WalletCursor, labels []string,
+	received walletNotes, unspent []*WalletNote) *WalletCursor {
+
+	for i, label := range labels {
+		var keys map[noteKey]struct{}
+		if i == len(labels)-1 && unspent != nil {
+			keys = make(map[noteKey]struct{})
+			for _, note := range unspent {
+				keys[note.key()] = struct{}{}
+			}
+		}
+		hash := walletTestHash(label)
+		cursor, _ = cursor.advance(&hash, received, keys, 3)
+	}
+	return cursor
+}
+
+func TestWalletCursorAdvance(t *testing.T) {
+	t1 := walletTestNote("t1", 1)
+	t2 := walletTestNote("t2", 2)
+	labels := map[chainhash.Hash]string{t1.TxID: "t1", t2.TxID: "t2"}
+
+	tests := []struct {
+		name     string
+		blocks   []string
+		received walletNotes
+		unspent  []*WalletNote
+		want     [][]string
+		notes    int
+	}{
+		{
+			name:     "receive and confirm",
+			blocks:   []string{"a1", "a2", "a3", "a4"},
+			received: walletNotes{t1, t2},
+			want: [][]string{
+				{"NoteReceived t1 1"},
+				{"NoteReceived t2 1", "NoteConfirmed t1 2"},
+				{"NoteConfirmed t1 3", "NoteConfirmed t2 2"},
+				{"NoteConfirmed t2 3"},
+			},
+			notes: 2,
+		},
+		{
+			name:     "received and spent between polls",
+			blocks:   []string{"a1", "a2"},
+			received: walletNotes{t1, t2},
+			unspent:  []*WalletNote{t1},
+			want: [][]string{
+				{"NoteReceived t1 1"},
+				{"NoteReceived t2 1", "NoteConfirmed t1 2",
+					"NoteSpent t2 1"},
+			},
+			notes: 1,
+		},
+		{
+			name:     "spent at the best block",
+			blocks:   []string{"a1", "a2", "a3"},
+			received: walletNotes{t1},
+			unspent:  []*WalletNote{},
+			want: [][]string{
+				{"NoteReceived t1 1"},
+				{"NoteConfirmed t1 2"},
+				{"NoteConfirmed t1 3", "NoteSpent t1 3"},
+			},
+			notes: 0,
+		},
+	}
+
+	for _, test := range tests {
+		cursor := walletTestCursor(0, "a0")
+		for i, label := range test.blocks {
+			var unspent map[noteKey]struct{}
+			if i == len(test.blocks)-1 && test.unspent != nil {
+				unspent = make(map[noteKey]struct{})
+				for _, note := range test.unspent {
+					unspent[note.key()] = struct{}{}
+				}
+			}
+			hash := walletTestHash(label)
+			next, events := cursor.advance(&hash, test.received,
+				unspent, 3)
+			var got []string
+			for j := range events {
+				got = append(got, walletEventString(&events[j],
+					labels))
+			}
+			if !reflect.DeepEqual(got, test.want[i]) {
+				t.Errorf("%s: block %d: got events %q, want %q",
+					test.name, i+1, got, test.want[i])
+			}
+			if next.Height() != int32(i+1) || next.Hash() != hash {
+				t.Errorf("%s: block %d: got cursor at %d %v",
+					test.name, i+1, next.Height(), next.Hash())
+			}
+			if cursor.Height() != int32(i) {
+				t.Errorf("%s: block %d: advance modified the "+
+					"previous cursor", test.name, i+1)
+			}
+			cursor = next
+		}
+		if len(cursor.notes) != test.notes {
+			t.Errorf("%s: got %d unspent notes, want %d", test.name,
+				len(cursor.notes), test.notes)
+		}
+	}
+}
+
+func TestWalletCursorTrim(t *testing.T) {
+	// Only the most recent blocks a reorganization can fork from, and the
+	// events delivered at them, are remembered.
+	tests := []struct {
+		blocks     int32
+		remembered int
+	}{
+		{maxReorgLength, maxReorgLength + 1},
+		{maxReorgLength + 1, maxReorgLength + 1},
+		{maxReorgLength + 2, maxReorgLength + 1},
+		{3 * maxReorgLength, maxReorgLength + 1},
+	}
+
+	for _, test := range tests {
+		cursor := walletTestCursor(0, "a0")
+		var received walletNotes
+		for height := int32(1); height <= test.blocks; height++ {
+			received = append(received,
+				walletTestNote(fmt.Sprint("t", height), height))
+		}
+		for height := int32(1); height <= test.blocks; height++ {
+			hash := walletTestHash(fmt.Sprint("a", height))
+			cursor, _ = cursor.advance(&hash, received, nil, 1)
+		}
+
+		if len(cursor.blocks) != test.remembered {
+			t.Errorf("%d blocks: got %d remembered blocks, want %d",
+				test.blocks, len(cursor.blocks), test.remembered)
+			continue
+		}
+		oldest := test.blocks - int32(test.remembered) + 1
+		if cursor.blocks[0] != walletTestHash(fmt.Sprint("a", oldest)) {
+			t.Errorf("%d blocks: oldest remembered block is not "+
+				"at height %d", test.blocks, oldest)
+		}
+
+		// Each block received a note, so the journal holds a receipt
+		// for each remembered block above the oldest.
+		if len(cursor.journal) != test.remembered-1 {
+			t.Errorf("%d blocks: got %d journal events, want %d",
+				test.blocks, len(cursor.journal),
+				test.remembered-1)
+		}
+		for _, event := range cursor.journal {
+			if event.Height <= oldest {
+				t.Errorf("%d blocks: journal holds an event at "+
+					"height %d", test.blocks, event.Height)
+			}
+		}
+		if len(cursor.notes) != int(test.blocks) {
+			t.Errorf("%d blocks: got %d unspent notes, want %d",
+				test.blocks, len(cursor.notes), test.blocks)
+		}
+
+		// Rolling back to the oldest remembered block retracts every
+		// event of the journal.
+		back, retracted := cursor.rollback(oldest)
+		if len(retracted) != len(cursor.journal) ||
+			len(back.blocks) != 1 || back.Height() != oldest {
+
+			t.Errorf("%d blocks: rollback to %d retracted %d events "+
+				"and kept %d blocks", test.blocks, oldest,
+				len(retracted), len(back.blocks))
+		}
+	}
+}
+
+func TestWalletCursorRollback(t *testing.T) {
+	t1 := walletTestNote("t1", 1)
+	t2 := walletTestNote("t2", 2)
+	labels := map[chainhash.Hash]string{t1.TxID: "t1", t2.TxID: "t2"}
+
+	// t1 is received at 1 and spent at 3, and t2 is received at 2.
+	cursor := walletTestCursor(0, "a0")
+	cursor = advanceTestCursor(cursor, []string{"a1", "a2"},
+		walletNotes{t1, t2}, nil)
+	cursor = advanceTestCursor(cursor, []string{"a3"}, walletNotes{t1, t2},
+		[]*WalletNote{t2})
+
+	tests := []struct {
+		height    int32
+		retracted []string
+		notes     []*WalletNote
+	}{
+		{
+			height:    3,
+			retracted: nil,
+			notes:     []*WalletNote{t2},
+		},
+		{
+			height: 2,
+			retracted: []string{
+				"NoteSpent t1 3 retracted",
+				"NoteConfirmed t2 2 retracted",
+				"NoteConfirmed t1 3 retracted",
+			},
+			notes: []*WalletNote{t1, t2},
+		},
+		{
+			height: 1,
+			retracted: []string{
+				"NoteSpent t1 3 retracted",
+				"NoteConfirmed t2 2 retracted",
+				"NoteConfirmed t1 3 retracted",
+				"NoteConfirmed t1 2 retracted",
+				"NoteReceived t2 1 retracted",
+			},
+			notes: []*WalletNote{t1},
+		},
+		{
+			height: 0,
+			retracted: []string{
+				"NoteSpent t1 3 retracted",
+				"NoteConfirmed t2 2 retracted",
+				"NoteConfirmed t1 3 retracted",
+				"NoteConfirmed t1 2 retracted",
+				"NoteReceived t2 1 retracted",
+				"NoteReceived t1 1 retracted",
+			},
+			notes: nil,
+		},
+	}
+
+	for _, test := range tests {
+		back, retracted := cursor.rollback(test.height)
+		var got []string
+		for i := range retracted {
+			got = append(got, walletEventString(&retracted[i], labels))
+		}
+		if !reflect.DeepEqual(got, test.retracted) {
+			t.Errorf("rollback to %d: got %q, want %q", test.height,
+				got, test.retracted)
+		}
+		if back.Height() != test.height ||
+			back.Hash() != walletTestHash(fmt.Sprint("a", test.height)) {
+
+			t.Errorf("rollback to %d: got cursor at %d", test.height,
+				back.Height())
+		}
+		if len(back.notes) != len(test.notes) {
+			t.Errorf("rollback to %d: got %d unspent notes, want %d",
+				test.height, len(back.notes), len(test.notes))
+		}
+		for _, note := range test.notes {
+			if _, ok := back.notes[note.key()]; !ok {
+				t.Errorf("rollback to %d: %s is not unspent",
+					test.height, labels[note.TxID])
+			}
+		}
+	}
+
+	// Rolling back leaves the cursor it started from untouched.
+	if cursor.Height() != 3 || len(cursor.notes) != 1 ||
+		len(cursor.journal) != 6 {
+
+		t.Errorf("rollback modified the cursor")
+	}
+}
+
+func TestWalletCursorJSON(t *testing.T) {
+	sprout := walletTestNote("sprout", 1)
+	sprout.Pool = zcashjson.PoolSprout
+	sprout.JSIndex = 1
+	sprout.OutIndex = 1
+	orchard := walletTestNote("orchard", 2)
+	orchard.Pool = zcashjson.PoolOrchard
+	orchard.OutIndex = 3
+	orchard.Memo = bytes.Repeat([]byte{0xf6}, 4)
+	orchard.Change = true
+	spent := walletTestNote("spent", 2)
+
+	cursor := walletTestCursor(0, "a0")
+	cursor = advanceTestCursor(cursor, []string{"a1", "a2"},
+		walletNotes{sprout, orchard, spent}, nil)
+	cursor = advanceTestCursor(cursor, []string{"a3"},
+		walletNotes{sprout, orchard, spent},
+		[]*WalletNote{sprout, orchard})
+	back, _ := cursor.rollback(2)
+
+	for _, c := range []*WalletCursor{cursor, back} {
+		b, err := json.Marshal(c)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		var decoded WalletCursor
+		if err := json.Unmarshal(b, &decoded); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		if !reflect.DeepEqual(&decoded, c) {
+			t.Errorf("cursor at %d changed by JSON round trip:\n"+
+				"got  %+v\nwant %+v", c.Height(), &decoded, c)
+		}
+		again, err := json.Marshal(&decoded)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		if !bytes.Equal(again, b) {
+			t.Errorf("cursor at %d encodes differently after a "+
+				"round trip", c.Height())
+		}
+	}
+
+	invalid := []string{
+		`{"height":1,"blocks":[]}`,
+		`{"height":1,"blocks":["zz"]}`,
+		`{"height":1,"blocks":["` + walletTestHash("a1").String() +
+			`"],"notes":[{"txid":"zz"}]}`,
+	}
+	for _, s := range invalid {
+		var decoded WalletCursor
+		if err := json.Unmarshal([]byte(s), &decoded); err == nil {
+			t.Errorf("Unmarshal(%s): got no error", s)
+		}
+	}
+}
+
+// walletTestTx is a transaction of the test wallet server.
+type walletTestTx struct {
+	label string
+
+	// block is the label of the block the transaction is mined in, or
+	// empty when it is not mined.  The transaction is also not mined when
+	// the block is not part of the best chain.
+	block string
+
+	// transparent is set when the transaction involves a transparent
+	// address of the wallet, so listsinceblock lists it.
+	transparent bool
+
+	// spent is set once the note received by the transaction is spent.
+	spent bool
+}
+
+// walletTestServer is a chain and wallet server answering the requests of a
+// wallet watcher.  Each transaction pays a single Sapling note of 1000
+// zatoshis to the watched address.
+type walletTestServer struct {
+	mtx     sync.Mutex
+	address string
+	chain   []string
+	known   map[string]int32
+	txs     []*walletTestTx
+	calls   map[string]int
+
+	// heightless lists receipts without the height of their block, as
+	// older nodes do.
+	heightless bool
+}
+
+// setChain replaces the best chain with the blocks with the passed labels.
+// Blocks which leave the best chain stay known to the server.
+func (s *walletTestServer) setChain(labels ...string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.chain = labels
+	for i, label := range labels {
+		s.known[label] = int32(i)
+	}
+}
+
+// addTx adds a transaction to the wallet.
+func (s *walletTestServer) addTx(tx *walletTestTx) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.txs = append(s.txs, tx)
+}
+
+// height returns the height of the block with the passed label in the best
+// chain, or -1 when it is not part of it.
+func (s *walletTestServer) height(block string) int32 {
+	for i, label := range s.chain {
+		if label == block {
+			return int32(i)
+		}
+	}
+	return -1
+}
+
+// blockLabel returns the label of the block with the passed hash.
+func (s *walletTestServer) blockLabel(hash string) (string, bool) {
+	for label := range s.known {
+		if walletTestHash(label).String() == hash {
+			return label, true
+		}
+	}
+	return "", false
+}
+
+// handle answers a request of the watcher.
+func (s *walletTestServer) handle(method string, params []json.RawMessage) (interface{}, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.calls[method]++
+
+	var param interface{}
+	if len(params) != 0 {
+		json.Unmarshal(params[0], &param)
+	}
+	tip := int32(len(s.chain) - 1)
+
+	switch method {
+	case "getbestblockhash":
+		return walletTestHash(s.chain[tip]).String(), nil
+
+	case "getblockcount":
+		return tip, nil
+
+	case "getblockhash":
+		height := int32(param.(float64))
+		if height > tip {
+			return nil, errors.New("block height out of range")
+		}
+		return walletTestHash(s.chain[height]).String(), nil
+
+	case "getblockheader":
+		label, ok := s.blockLabel(param.(string))
+		if !ok {
+			return nil, errors.New("block not found")
+		}
+		return map[string]interface{}{
+			"hash":   param,
+			"height": s.known[label],
+		}, nil
+
+	case "z_listreceivedbyaddress":
+		var results []interface{}
+		for _, tx := range s.txs {
+			height := s.height(tx.block)
+			if height < 0 || param != s.address {
+				continue
+			}
+			result := map[string]interface{}{
+				"pool":          "sapling",
+				"txid":          walletTestHash(tx.label).String(),
+				"amount":        0.00001,
+				"amountZat":     1000,
+				"memo":          "f6",
+				"outindex":      0,
+				"confirmations": tip - height + 1,
+				"change":        false,
+			}
+			if !s.heightless {
+				result["blockheight"] = height
+			}
+			results = append(results, result)
+		}
+		return results, nil
+
+	case "z_listunspent":
+		var results []interface{}
+		for _, tx := range s.txs {
+			height := s.height(tx.block)
+			if height < 0 || tx.spent {
+				continue
+			}
+			results = append(results, map[string]interface{}{
+				"pool":          "sapling",
+				"txid":          walletTestHash(tx.label).String(),
+				"outindex":      0,
+				"confirmations": tip - height + 1,
+				"spendable":     true,
+				"address":       s.address,
+				"amount":        0.00001,
+				"memo":          "f6",
+				"change":        false,
+			})
+		}
+		return results, nil
+
+	case "listsinceblock":
+		label, ok := s.blockLabel(param.(string))
+		if !ok {
+			return nil, errors.New("block not found")
+		}
+		from := s.known[label]
+		var txs []interface{}
+		for _, tx := range s.txs {
+			height := s.height(tx.block)
+			if !tx.transparent || height <= from {
+				continue
+			}
+			txs = append(txs, map[string]interface{}{
+				"txid":      walletTestHash(tx.label).String(),
+				"category":  "receive",
+				"amount":    0,
+				"blockhash": walletTestHash(tx.block).String(),
+			})
+		}
+		return map[string]interface{}{
+			"transactions": txs,
+			"lastblock":    walletTestHash(s.chain[tip]).String(),
+		}, nil
+
+	case "gettransaction":
+		for _, tx := range s.txs {
+			if walletTestHash(tx.label).String() != param {
+				continue
+			}
+			result := map[string]interface{}{
+				"txid":    param,
+				"amount":  0,
+				"details": []interface{}{},
+				"hex":     "",
+			}
+			if s.height(tx.block) >= 0 {
+				result["blockhash"] =
+					walletTestHash(tx.block).String()
+			}
+			return result, nil
+		}
+		return nil, errors.New("transaction not found")
+	}
+	return nil, fmt.Errorf("unexpected method %s", method)
+}
+
+// resetCalls returns the number of requests of each method since the previous
+// reset.
+func (s *walletTestServer) resetCalls() map[string]int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	calls := s.calls
+	s.calls = make(map[string]int)
+	return calls
+}
+
+// newWalletTestServer returns a test wallet server for a Sapling address,
+// along with a client connected to it and a function shutting both down.
+func newWalletTestServer(t *testing.T) (*walletTestServer, zcashutil.Address, *Client, func()) {
+	addr, err := zcashutil.NewAddressSapling(
+		bytes.Repeat([]byte{7}, zcashutil.SaplingPaymentAddrSize),
+		&zcashcfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewAddressSapling: %v", err)
+	}
+	server := &walletTestServer{
+		address: addr.EncodeAddress(),
+		known:   make(map[string]int32),
+		calls:   make(map[string]int),
+	}
+	client, done := newTestClient(t, server.handle)
+	return server, addr, client, done
+}
+
+func TestWalletWatcher(t *testing.T) {
+	server, addr, client, done := newWalletTestServer(t)
+	defer done()
+
+	labels := make(map[chainhash.Hash]string)
+	for _, label := range []string{"t0", "t1", "t2", "t3", "t4", "t5"} {
+		labels[walletTestHash(label)] = label
+	}
+	var batches []string
+	w := &WalletWatcher{
+		client:    client,
+		addresses: []zcashutil.Address{addr},
+		onEvents: func(batch *WalletEventBatch) error {
+			var events []string
+			for i := range batch.Events {
+				events = append(events, walletEventString(
+					&batch.Events[i], labels))
+			}
+			batches = append(batches, fmt.Sprintf("%d: %s",
+				batch.Height, strings.Join(events, ", ")))
+			return nil
+		},
+		startHeight:   4,
+		confirmations: 3,
+	}
+
+	tests := []struct {
+		name   string
+		update func()
+		want   []string
+		calls  map[string]int
+	}{
+		{
+			// The unspent note received before the start height is
+			// tracked without delivering its receipt.
+			name: "start",
+			update: func() {
+				server.setChain("a0", "a1", "a2", "a3", "a4", "a5")
+				server.addTx(&walletTestTx{label: "t0", block: "a1"})
+				server.addTx(&walletTestTx{label: "t1", block: "a4"})
+			},
+			want: []string{
+				"4: NoteReceived t1 1",
+				"5: NoteConfirmed t1 2",
+			},
+		},
+		{
+			// A note which only involves shielded addresses, and is
+			// received and spent between two polls, is delivered.
+			name: "received and spent between polls",
+			update: func() {
+				server.setChain("a0", "a1", "a2", "a3", "a4", "a5",
+					"a6", "a7")
+				server.addTx(&walletTestTx{label: "t2", block: "a6",
+					spent: true})
+			},
+			want: []string{
+				"6: NoteReceived t2 1, NoteConfirmed t1 3",
+				"7: NoteConfirmed t2 2, NoteSpent t2 2",
+			},
+		},
+		{
+			// Reorganizing the chain retracts the events of the
+			// disconnected blocks, restoring the spent note, before
+			// the new chain is delivered.
+			name: "reorganization",
+			update: func() {
+				server.setChain("a0", "a1", "a2", "a3", "a4", "a5",
+					"b6", "b7", "b8")
+				server.mtx.Lock()
+				server.txs[2].block = "b7"
+				server.txs[2].spent = false
+				server.mtx.Unlock()
+			},
+			want: []string{
+				"5: NoteSpent t2 2 retracted, " +
+					"NoteConfirmed t2 2 retracted, " +
+					"NoteConfirmed t1 3 retracted, " +
+					"NoteReceived t2 1 retracted",
+				"6: NoteConfirmed t1 3",
+				"7: NoteReceived t2 1",
+				"8: NoteConfirmed t2 2",
+			},
+		},
+		{
+			// Receipts listed without their height are placed by
+			// listsinceblock, and only the other transactions which
+			// may be mined since the cursor are looked up.
+			name: "receipts without heights",
+			update: func() {
+				server.setChain("a0", "a1", "a2", "a3", "a4", "a5",
+					"b6", "b7", "b8", "b9")
+				server.heightless = true
+				server.addTx(&walletTestTx{label: "t3", block: "b9"})
+				server.addTx(&walletTestTx{label: "t4", block: "b9",
+					transparent: true})
+				server.addTx(&walletTestTx{label: "t5", block: "a1",
+					spent: true})
+			},
+			want: []string{
+				"9: NoteReceived t3 1, NoteReceived t4 1, " +
+					"NoteConfirmed t2 3",
+			},
+			calls: map[string]int{"gettransaction": 1},
+		},
+		{
+			name:   "no new block",
+			update: func() {},
+			want:   nil,
+			calls: map[string]int{
+				"z_listreceivedbyaddress": 0,
+				"z_listunspent":           0,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test.update()
+		server.resetCalls()
+		batches = nil
+		if err := w.poll(); err != nil {
+			t.Fatalf("%s: poll: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(batches, test.want) {
+			t.Fatalf("%s: got batches\n%s\nwant\n%s", test.name,
+				strings.Join(batches, "\n"),
+				strings.Join(test.want, "\n"))
+		}
+		calls := server.resetCalls()
+		for method, want := range test.calls {
+			if calls[method] != want {
+				t.Errorf("%s: got %d %s requests, want %d",
+					test.name, calls[method], method, want)
+			}
+		}
+	}
+
+	// The watcher restarted from the cursor of the last batch resumes
+	// from it.
+	cursor := w.Cursor()
+	if cursor.Height() != 9 || len(cursor.notes) != 5 {
+		t.Fatalf("got cursor at %d with %d notes", cursor.Height(),
+			len(cursor.notes))
+	}
+	server.setChain("a0", "a1", "a2", "a3", "a4", "a5", "b6", "b7", "b8",
+		"b9", "b10")
+	batches = nil
+	restarted := &WalletWatcher{
+		client:        client,
+		addresses:     w.addresses,
+		onEvents:      w.onEvents,
+		cursor:        cursor,
+		confirmations: 3,
+	}
+	if err := restarted.poll(); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	want := []string{"10: NoteConfirmed t3 2, NoteConfirmed t4 2"}
+	if !reflect.DeepEqual(batches, want) {
+		t.Fatalf("restart: got batches %q, want %q", batches, want)
+	}
+}
+
+func TestWalletWatcherReorgTooDeep(t *testing.T) {
+	server, addr, client, done := newWalletTestServer(t)
+	defer done()
+	server.setChain("a0", "b1", "b2", "b3")
+
+	// The cursor remembers blocks which all left the best chain.
+	cursor := walletTestCursor(1, "a1")
+	cursor = advanceTestCursor(cursor, []string{"a2", "a3"}, nil, nil)
+
+	errs := make(chan error, 10)
+	w := client.NewWalletWatcher(&WalletWatcherConfig{
+		Addresses: []zcashutil.Address{addr},
+		OnEvents: func(batch *WalletEventBatch) error {
+			t.Errorf("got batch at %d", batch.Height)
+			return nil
+		},
+		OnError:      func(err error) { errs <- err },
+		Cursor:       cursor,
+		PollInterval: time.Millisecond,
+	})
+	defer w.Stop()
+
+	select {
+	case err := <-errs:
+		if err != ErrWalletReorgTooDeep {
+			t.Fatalf("got error %v, want %v", err,
+				ErrWalletReorgTooDeep)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the error")
+	}
+
+	// The watcher stops polling once the error is reported.
+	stopped := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the watcher did not stop polling")
+	}
+	select {
+	case err := <-errs:
+		t.Fatalf("got error %v after stopping", err)
+	default:
+	}
+	if got := w.Cursor(); got.Height() != 3 || got.Hash() != cursor.Hash() {
+		t.Errorf("the cursor moved to %d", got.Height())
+	}
+}
@@ -6,9 +6,14 @@
 package zcashrpcclient
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/arithmetric/zcashrpcclient/zcashjson"
+	"github.com/arithmetric/zcashrpcclient/zcashutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcutil"
 )
 
@@ -20,8 +25,8 @@ import (
 // of a ZGetOperationResultAsync RPC invocation (or an applicable error).
 type FutureZGetOperationResultResult chan *response
 
-// Receive waits for the response promised by the future and returns detailed
-// information about a wallet transaction.
+// Receive waits for the response promised by the future and returns the
+// finished operations.
 func (r FutureZGetOperationResultResult) Receive() ([]zcashjson.ZGetOperationStatusResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
@@ -43,22 +48,28 @@ func (r FutureZGetOperationResultResult) Receive() ([]zcashjson.ZGetOperationSta
 // function on the returned instance.
 //
 // See ZGetOperationResult for the blocking version and more details.
-func (c *Client) ZGetOperationResultAsync() FutureZGetOperationResultResult {
-	cmd := zcashjson.NewZGetOperationResultCmd(nil)
+func (c *Client) ZGetOperationResultAsync(operationIDs ...string) FutureZGetOperationResultResult {
+	var ids *[]string
+	if len(operationIDs) > 0 {
+		ids = &operationIDs
+	}
+	cmd := zcashjson.NewZGetOperationResultCmd(ids)
 	return c.sendCmd(cmd)
 }
 
-// ZGetOperationResult returns detailed information about a wallet transaction.
-func (c *Client) ZGetOperationResult() ([]zcashjson.ZGetOperationStatusResult, error) {
-	return c.ZGetOperationResultAsync().Receive()
+// ZGetOperationResult returns the status and result of the passed finished
+// operations, or of every finished operation when none are passed.  The server
+// forgets the returned operations.
+func (c *Client) ZGetOperationResult(operationIDs ...string) ([]zcashjson.ZGetOperationStatusResult, error) {
+	return c.ZGetOperationResultAsync(operationIDs...).Receive()
 }
 
 // FutureZGetOperationStatusResult is a future promise to deliver the result
 // of a ZGetOperationStatusAsync RPC invocation (or an applicable error).
 type FutureZGetOperationStatusResult chan *response
 
-// Receive waits for the response promised by the future and returns detailed
-// information about a wallet transaction.
+// Receive waits for the response promised by the future and returns the
+// status of the operations.
 func (r FutureZGetOperationStatusResult) Receive() ([]zcashjson.ZGetOperationStatusResult, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
@@ -80,14 +91,19 @@ func (r FutureZGetOperationStatusResult) Receive() ([]zcashjson.ZGetOperationSta
 // function on the returned instance.
 //
 // See ZGetOperationStatus for the blocking version and more details.
-func (c *Client) ZGetOperationStatusAsync() FutureZGetOperationStatusResult {
-	cmd := zcashjson.NewZGetOperationStatusCmd(nil)
+func (c *Client) ZGetOperationStatusAsync(operationIDs ...string) FutureZGetOperationStatusResult {
+	var ids *[]string
+	if len(operationIDs) > 0 {
+		ids = &operationIDs
+	}
+	cmd := zcashjson.NewZGetOperationStatusCmd(ids)
 	return c.sendCmd(cmd)
 }
 
-// ZGetOperationStatus returns detailed information about a wallet transaction.
-func (c *Client) ZGetOperationStatus() ([]zcashjson.ZGetOperationStatusResult, error) {
-	return c.ZGetOperationStatusAsync().Receive()
+// ZGetOperationStatus returns the status of the passed operations, or of every
+// operation when none are passed.
+func (c *Client) ZGetOperationStatus(operationIDs ...string) ([]zcashjson.ZGetOperationStatusResult, error) {
+	return c.ZGetOperationStatusAsync(operationIDs...).Receive()
 }
 
 // FutureZListOperationIdsResult is a future promise to deliver the result of a
@@ -131,6 +147,16 @@ func (c *Client) ZListOperationIds() ([]string, error) {
 // Transaction Send Functions
 // **************************
 
+// sendManyFromAddress returns the source address argument of z_sendmany for
+// the passed address, which selects any transparent address in the wallet when
+// nil.
+func sendManyFromAddress(address zcashutil.Address) string {
+	if address == nil {
+		return "ANY_TADDR"
+	}
+	return address.EncodeAddress()
+}
+
 // FutureZSendManyResult is a future promise to deliver the result of a
 // ZSendManyAsync RPC invocation (or an applicable error).
 type FutureZSendManyResult chan *response
@@ -158,16 +184,149 @@ func (r FutureZSendManyResult) Receive() (string, error) {
 // returned instance.
 //
 // See ZSendMany for the blocking version and more details.
-func (c *Client) ZSendManyAsync(fromAccount string, amounts []zcashjson.ZSendManyEntry) FutureZSendManyResult {
-	cmd := zcashjson.NewZSendManyCmd(fromAccount, amounts, nil)
+func (c *Client) ZSendManyAsync(fromAddress zcashutil.Address, amounts []zcashjson.ZSendManyEntry) FutureZSendManyResult {
+	cmd := zcashjson.NewZSendManyCmd(sendManyFromAddress(fromAddress),
+		amounts, nil)
 	return c.sendCmd(cmd)
 }
 
 // ZSendMany sends multiple amounts to multiple addresses using the provided
-// account as a source of funds in a single transaction.  Only funds with the
-// default number of minimum confirmations will be used.
-func (c *Client) ZSendMany(fromAccount string, amounts []zcashjson.ZSendManyEntry) (string, error) {
-	return c.ZSendManyAsync(fromAccount, amounts).Receive()
+// address as a source of funds in a single transaction.  A nil fromAddress
+// spends the UTXOs of any transparent address in the wallet.  Only funds with
+// the default number of minimum confirmations will be used, the ZIP 317
+// conventional fee is paid and the server's default privacy policy applies.
+//
+// The returned string is the ID of the asynchronous operation performing the
+// send.
+//
+// See ZSendManyOpts to override the defaults.
+func (c *Client) ZSendMany(fromAddress zcashutil.Address, amounts []zcashjson.ZSendManyEntry) (string, error) {
+	return c.ZSendManyAsync(fromAddress, amounts).Receive()
+}
+
+// ZSendManyOptsAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See ZSendManyOpts for the blocking version and more details.
+func (c *Client) ZSendManyOptsAsync(fromAddress zcashutil.Address, amounts []zcashjson.ZSendManyEntry, options *zcashjson.ZSendManyOptions) FutureZSendManyResult {
+	cmd := zcashjson.NewZSendManyCmd(sendManyFromAddress(fromAddress),
+		amounts, options)
+	return c.sendCmd(cmd)
+}
+
+// ZSendManyOpts sends multiple amounts to multiple addresses using the
+// provided address as a source of funds in a single transaction.  A nil
+// fromAddress spends the UTXOs of any transparent address in the wallet.  The
+// options select the minimum number of confirmations of the funds to spend,
+// the fee and the privacy policy.  Passing nil for options, or for any of its
+// fields, uses the default value.
+//
+// The returned string is the ID of the asynchronous operation performing the
+// send.
+func (c *Client) ZSendManyOpts(fromAddress zcashutil.Address, amounts []zcashjson.ZSendManyEntry, options *zcashjson.ZSendManyOptions) (string, error) {
+	return c.ZSendManyOptsAsync(fromAddress, amounts, options).Receive()
+}
+
+// FutureZShieldCoinbaseResult is a future promise to deliver the result of a
+// ZShieldCoinbaseAsync RPC invocation (or an applicable error).
+type FutureZShieldCoinbaseResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// coinbase UTXOs being shielded along with the ID of the operation shielding
+// them.
+func (r FutureZShieldCoinbaseResult) Receive() (*zcashjson.ZShieldCoinbaseResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a z_shieldcoinbase result object.
+	var result zcashjson.ZShieldCoinbaseResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ZShieldCoinbaseAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See ZShieldCoinbase for the blocking version and more details.
+func (c *Client) ZShieldCoinbaseAsync(fromAddress, toAddress zcashutil.Address, options *zcashjson.ZShieldCoinbaseOptions) FutureZShieldCoinbaseResult {
+	// The special address "*" shields from every wallet address.
+	from := "*"
+	if fromAddress != nil {
+		from = fromAddress.EncodeAddress()
+	}
+	cmd := zcashjson.NewZShieldCoinbaseCmd(from, toAddress.EncodeAddress(),
+		options)
+	return c.sendCmd(cmd)
+}
+
+// ZShieldCoinbase shields the coinbase UTXOs of the passed transparent
+// address, or of every wallet address when it is nil, by sending them to the
+// passed shielded address.  The options select the fee, the maximum number of
+// UTXOs to shield, the memo and the privacy policy.  Passing nil for options,
+// or for any of its fields, uses the default value.
+//
+// The shielding happens asynchronously.  The OperationID of the result can be
+// passed to an OperationTracker to wait for the transaction.
+func (c *Client) ZShieldCoinbase(fromAddress, toAddress zcashutil.Address, options *zcashjson.ZShieldCoinbaseOptions) (*zcashjson.ZShieldCoinbaseResult, error) {
+	return c.ZShieldCoinbaseAsync(fromAddress, toAddress, options).Receive()
+}
+
+// FutureZMergeToAddressResult is a future promise to deliver the result of a
+// ZMergeToAddressAsync RPC invocation (or an applicable error).
+type FutureZMergeToAddressResult chan *response
+
+// Receive waits for the response promised by the future and returns the UTXOs
+// and notes being merged along with the ID of the operation merging them.
+func (r FutureZMergeToAddressResult) Receive() (*zcashjson.ZMergeToAddressResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a z_mergetoaddress result object.
+	var result zcashjson.ZMergeToAddressResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ZMergeToAddressAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See ZMergeToAddress for the blocking version and more details.
+func (c *Client) ZMergeToAddressAsync(fromAddresses []zcashutil.Address, toAddress zcashutil.Address, options *zcashjson.ZMergeToAddressOptions) FutureZMergeToAddressResult {
+	from := make([]string, 0, len(fromAddresses))
+	for _, addr := range fromAddresses {
+		from = append(from, addr.EncodeAddress())
+	}
+	cmd := zcashjson.NewZMergeToAddressCmd(from, toAddress.EncodeAddress(),
+		options)
+	return c.sendCmd(cmd)
+}
+
+// ZMergeToAddress merges the UTXOs and notes of the passed addresses into a
+// single output to the passed address.  The FromPools option selects every
+// transparent, Sprout or Sapling address in the wallet instead of listing
+// them.  The options also select the fee, the maximum numbers of UTXOs and
+// notes to merge, the memo and the privacy policy.  Passing nil for options, or for
+// any of its fields, uses the default value.
+//
+// The merge happens asynchronously.  The OperationID of the result can be
+// passed to an OperationTracker to wait for the transaction.
+func (c *Client) ZMergeToAddress(fromAddresses []zcashutil.Address, toAddress zcashutil.Address, options *zcashjson.ZMergeToAddressOptions) (*zcashjson.ZMergeToAddressResult, error) {
+	return c.ZMergeToAddressAsync(fromAddresses, toAddress, options).Receive()
 }
 
 // *************************
@@ -180,20 +339,20 @@ type FutureZGetNewAddressResult chan *response
 
 // Receive waits for the response promised by the future and returns a new
 // address.
-func (r FutureZGetNewAddressResult) Receive() (string, error) {
+func (r FutureZGetNewAddressResult) Receive() (zcashutil.Address, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Unmarshal result as a string.
 	var zaddr string
 	err = json.Unmarshal(res, &zaddr)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return zaddr, nil
+	return zcashutil.DecodeAddress(zaddr)
 }
 
 // ZGetNewAddressAsync returns an instance of a type that can be used to get the
@@ -202,15 +361,262 @@ func (r FutureZGetNewAddressResult) Receive() (string, error) {
 //
 // See ZGetNewAddress for the blocking version and more details.
 func (c *Client) ZGetNewAddressAsync() FutureZGetNewAddressResult {
-	cmd := zcashjson.NewZGetNewAddressCmd()
+	cmd := zcashjson.NewZGetNewAddressCmd(nil)
 	return c.sendCmd(cmd)
 }
 
-// ZGetNewAddress returns a new address.
-func (c *Client) ZGetNewAddress() (string, error) {
+// ZGetNewAddress returns a new shielded address of the server's default type.
+func (c *Client) ZGetNewAddress() (zcashutil.Address, error) {
 	return c.ZGetNewAddressAsync().Receive()
 }
 
+// ZGetNewAddressTypeAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See ZGetNewAddressType for the blocking version and more details.
+func (c *Client) ZGetNewAddressTypeAsync(addressType zcashjson.ValuePool) FutureZGetNewAddressResult {
+	cmd := zcashjson.NewZGetNewAddressCmd(&addressType)
+	return c.sendCmd(cmd)
+}
+
+// ZGetNewAddressType returns a new legacy shielded address in the passed pool,
+// which must be PoolSprout or PoolSapling.  Unified addresses are derived from
+// accounts with ZGetAddressForAccount instead.
+func (c *Client) ZGetNewAddressType(addressType zcashjson.ValuePool) (zcashutil.Address, error) {
+	return c.ZGetNewAddressTypeAsync(addressType).Receive()
+}
+
+// FutureZGetNewAccountResult is a future promise to deliver the result of a
+// ZGetNewAccountAsync RPC invocation (or an applicable error).
+type FutureZGetNewAccountResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// number of the new account.
+func (r FutureZGetNewAccountResult) Receive() (uint32, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return 0, err
+	}
+
+	// Unmarshal result as a z_getnewaccount result object.
+	var account zcashjson.ZGetNewAccountResult
+	err = json.Unmarshal(res, &account)
+	if err != nil {
+		return 0, err
+	}
+
+	return account.Account, nil
+}
+
+// ZGetNewAccountAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See ZGetNewAccount for the blocking version and more details.
+func (c *Client) ZGetNewAccountAsync() FutureZGetNewAccountResult {
+	cmd := zcashjson.NewZGetNewAccountCmd()
+	return c.sendCmd(cmd)
+}
+
+// ZGetNewAccount creates a new ZIP 32 account derived from the wallet's
+// mnemonic seed and returns its number.
+func (c *Client) ZGetNewAccount() (uint32, error) {
+	return c.ZGetNewAccountAsync().Receive()
+}
+
+// FutureZGetAddressForAccountResult is a future promise to deliver the result
+// of a ZGetAddressForAccountAsync RPC invocation (or an applicable error).
+type FutureZGetAddressForAccountResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// derived unified address along with its diversifier index and receiver
+// types.
+func (r FutureZGetAddressForAccountResult) Receive() (*zcashjson.ZGetAddressForAccountResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a z_getaddressforaccount result object.
+	var address zcashjson.ZGetAddressForAccountResult
+	err = json.Unmarshal(res, &address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &address, nil
+}
+
+// ZGetAddressForAccountAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ZGetAddressForAccount for the blocking version and more details.
+func (c *Client) ZGetAddressForAccountAsync(account uint32, receiverTypes []zcashjson.ReceiverType, diversifierIndex *uint64) FutureZGetAddressForAccountResult {
+	var types *[]zcashjson.ReceiverType
+	if len(receiverTypes) > 0 {
+		types = &receiverTypes
+	}
+
+	cmd := zcashjson.NewZGetAddressForAccountCmd(account, types,
+		diversifierIndex)
+	return c.sendCmd(cmd)
+}
+
+// ZGetAddressForAccount derives a unified address for the account with the
+// passed receiver types at the passed diversifier index.  Passing no receiver
+// types uses P2PKH, Sapling and Orchard receivers, and passing nil for the
+// diversifier index uses the next unused one.  Deriving the address at an
+// index used before returns the same address.
+func (c *Client) ZGetAddressForAccount(account uint32, receiverTypes []zcashjson.ReceiverType, diversifierIndex *uint64) (*zcashjson.ZGetAddressForAccountResult, error) {
+	return c.ZGetAddressForAccountAsync(account, receiverTypes,
+		diversifierIndex).Receive()
+}
+
+// FutureZListAccountsResult is a future promise to deliver the result of a
+// ZListAccountsAsync RPC invocation (or an applicable error).
+type FutureZListAccountsResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// accounts of the wallet.
+func (r FutureZListAccountsResult) Receive() ([]zcashjson.ZListAccountsResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of z_listaccounts result objects.
+	var accounts []zcashjson.ZListAccountsResult
+	err = json.Unmarshal(res, &accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// ZListAccountsAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See ZListAccounts for the blocking version and more details.
+func (c *Client) ZListAccountsAsync() FutureZListAccountsResult {
+	cmd := zcashjson.NewZListAccountsCmd()
+	return c.sendCmd(cmd)
+}
+
+// ZListAccounts returns the ZIP 32 accounts of the wallet along with the
+// unified addresses derived for each.
+func (c *Client) ZListAccounts() ([]zcashjson.ZListAccountsResult, error) {
+	return c.ZListAccountsAsync().Receive()
+}
+
+// FutureListAddressesResult is a future promise to deliver the result of a
+// ListAddressesAsync RPC invocation (or an applicable error).
+type FutureListAddressesResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// addresses of the wallet grouped by source.
+func (r FutureListAddressesResult) Receive() ([]zcashjson.ListAddressesResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of listaddresses result objects.
+	var groups []zcashjson.ListAddressesResult
+	err = json.Unmarshal(res, &groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// ListAddressesAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See ListAddresses for the blocking version and more details.
+func (c *Client) ListAddressesAsync() FutureListAddressesResult {
+	cmd := zcashjson.NewListAddressesCmd()
+	return c.sendCmd(cmd)
+}
+
+// ListAddresses returns every address of the wallet, transparent, shielded
+// and unified, grouped by the source of its keys such as legacy_random,
+// mnemonic_seed or imported_watchonly.
+func (c *Client) ListAddresses() ([]zcashjson.ListAddressesResult, error) {
+	return c.ListAddressesAsync().Receive()
+}
+
+// FutureZListUnifiedReceiversResult is a future promise to deliver the result
+// of a ZListUnifiedReceiversAsync RPC invocation (or an applicable error).
+type FutureZListUnifiedReceiversResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// receivers of the unified address.
+func (r FutureZListUnifiedReceiversResult) Receive() (*zcashutil.UnifiedReceivers, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a z_listunifiedreceivers result object.
+	var result zcashjson.ZListUnifiedReceiversResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode each of the receivers, leaving those the unified address
+	// does not contain nil.
+	var receivers zcashutil.UnifiedReceivers
+	encoded := []string{result.P2PKH, result.P2SH, result.Sapling,
+		result.Orchard}
+	for _, e := range encoded {
+		if e == "" {
+			continue
+		}
+		addr, err := zcashutil.DecodeAddress(e)
+		if err != nil {
+			return nil, err
+		}
+		switch a := addr.(type) {
+		case *zcashutil.AddressPubKeyHash:
+			receivers.P2PKH = a
+		case *zcashutil.AddressScriptHash:
+			receivers.P2SH = a
+		case *zcashutil.AddressSapling:
+			receivers.Sapling = a
+		case *zcashutil.AddressUnified:
+			receivers.Orchard = a
+		default:
+			return nil, fmt.Errorf("unexpected receiver %s", e)
+		}
+	}
+
+	return &receivers, nil
+}
+
+// ZListUnifiedReceiversAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ZListUnifiedReceivers for the blocking version and more details.
+func (c *Client) ZListUnifiedReceiversAsync(address zcashutil.Address) FutureZListUnifiedReceiversResult {
+	cmd := zcashjson.NewZListUnifiedReceiversCmd(address.EncodeAddress())
+	return c.sendCmd(cmd)
+}
+
+// ZListUnifiedReceivers returns the receivers of the passed unified address as
+// reported by the server.  The result can be compared with the receivers
+// decoded locally by zcashutil.AddressUnified.ListReceivers.
+func (c *Client) ZListUnifiedReceivers(address zcashutil.Address) (*zcashutil.UnifiedReceivers, error) {
+	return c.ZListUnifiedReceiversAsync(address).Receive()
+}
+
 // ************************
 // Amount/Balance Functions
 // ************************
@@ -219,22 +625,31 @@ func (c *Client) ZGetNewAddress() (string, error) {
 // ZListAddressesAsync RPC invocation (or an applicable error).
 type FutureZListAddressesResult chan *response
 
-// Receive waits for the response promised by the future and returns returns a
-// map of account names and their associated balances.
-func (r FutureZListAddressesResult) Receive() ([]string, error) {
+// Receive waits for the response promised by the future and returns the
+// shielded addresses of the wallet.
+func (r FutureZListAddressesResult) Receive() ([]zcashutil.Address, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
 	}
 
-	// Unmarshal result as a json object.
+	// Unmarshal result as an array of strings.
 	var zaddrs []string
 	err = json.Unmarshal(res, &zaddrs)
 	if err != nil {
 		return nil, err
 	}
 
-	return zaddrs, nil
+	addrs := make([]zcashutil.Address, 0, len(zaddrs))
+	for _, zaddr := range zaddrs {
+		addr, err := zcashutil.DecodeAddress(zaddr)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+
+	return addrs, nil
 }
 
 // ZListAddressesAsync returns an instance of a type that can be used to get the
@@ -247,9 +662,8 @@ func (c *Client) ZListAddressesAsync() FutureZListAddressesResult {
 	return c.sendCmd(cmd)
 }
 
-// ZListAddresses returns a map of account names and their associated balances
-// using the default number of minimum confirmations.
-func (c *Client) ZListAddresses() ([]string, error) {
+// ZListAddresses returns the shielded addresses of the wallet.
+func (c *Client) ZListAddresses() ([]zcashutil.Address, error) {
 	return c.ZListAddressesAsync().Receive()
 }
 
@@ -286,15 +700,15 @@ func (r FutureZGetBalanceResult) Receive() (btcutil.Amount, error) {
 // returned instance.
 //
 // See ZGetBalance for the blocking version and more details.
-func (c *Client) ZGetBalanceAsync(address string) FutureZGetBalanceResult {
-	cmd := zcashjson.NewZGetBalanceCmd(&address, nil)
+func (c *Client) ZGetBalanceAsync(address zcashutil.Address) FutureZGetBalanceResult {
+	addr := address.EncodeAddress()
+	cmd := zcashjson.NewZGetBalanceCmd(&addr, nil)
 	return c.sendCmd(cmd)
 }
 
 // ZGetBalance returns the available balance from the server for the specified
-// account using the default number of minimum confirmations.  The account may
-// be "*" for all accounts.
-func (c *Client) ZGetBalance(address string) (btcutil.Amount, error) {
+// address using the default number of minimum confirmations.
+func (c *Client) ZGetBalance(address zcashutil.Address) (btcutil.Amount, error) {
 	return c.ZGetBalanceAsync(address).Receive()
 }
 
@@ -336,6 +750,121 @@ func (c *Client) ZGetTotalBalance() (*zcashjson.ZGetTotalBalanceResult, error) {
 	return c.ZGetTotalBalanceAsync().Receive()
 }
 
+// FutureZGetBalanceForAccountResult is a future promise to deliver the result
+// of a ZGetBalanceForAccountAsync or ZGetBalanceForAccountMinConfAsync RPC
+// invocation (or an applicable error).
+type FutureZGetBalanceForAccountResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// balance of the account in each value pool.
+func (r FutureZGetBalanceForAccountResult) Receive() (*zcashjson.ZGetBalanceForAccountResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a z_getbalanceforaccount result object.
+	var balance zcashjson.ZGetBalanceForAccountResult
+	err = json.Unmarshal(res, &balance)
+	if err != nil {
+		return nil, err
+	}
+
+	return &balance, nil
+}
+
+// ZGetBalanceForAccountAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ZGetBalanceForAccount for the blocking version and more details.
+func (c *Client) ZGetBalanceForAccountAsync(account uint32) FutureZGetBalanceForAccountResult {
+	cmd := zcashjson.NewZGetBalanceForAccountCmd(account, nil)
+	return c.sendCmd(cmd)
+}
+
+// ZGetBalanceForAccount returns the balance of the account in each value pool
+// using the default number of minimum confirmations.
+func (c *Client) ZGetBalanceForAccount(account uint32) (*zcashjson.ZGetBalanceForAccountResult, error) {
+	return c.ZGetBalanceForAccountAsync(account).Receive()
+}
+
+// ZGetBalanceForAccountMinConfAsync returns an instance of a type that can be
+// used to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ZGetBalanceForAccountMinConf for the blocking version and more details.
+func (c *Client) ZGetBalanceForAccountMinConfAsync(account uint32, minConf int) FutureZGetBalanceForAccountResult {
+	cmd := zcashjson.NewZGetBalanceForAccountCmd(account, &minConf)
+	return c.sendCmd(cmd)
+}
+
+// ZGetBalanceForAccountMinConf returns the balance of the account in each
+// value pool using the specified number of minimum confirmations.
+func (c *Client) ZGetBalanceForAccountMinConf(account uint32, minConf int) (*zcashjson.ZGetBalanceForAccountResult, error) {
+	return c.ZGetBalanceForAccountMinConfAsync(account, minConf).Receive()
+}
+
+// FutureZGetBalanceForViewingKeyResult is a future promise to deliver the
+// result of a ZGetBalanceForViewingKeyAsync or
+// ZGetBalanceForViewingKeyMinConfAsync RPC invocation (or an applicable
+// error).
+type FutureZGetBalanceForViewingKeyResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// balance received by the viewing key in each value pool.
+func (r FutureZGetBalanceForViewingKeyResult) Receive() (*zcashjson.ZGetBalanceForViewingKeyResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a z_getbalanceforviewingkey result object.
+	var balance zcashjson.ZGetBalanceForViewingKeyResult
+	err = json.Unmarshal(res, &balance)
+	if err != nil {
+		return nil, err
+	}
+
+	return &balance, nil
+}
+
+// ZGetBalanceForViewingKeyAsync returns an instance of a type that can be used
+// to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ZGetBalanceForViewingKey for the blocking version and more details.
+func (c *Client) ZGetBalanceForViewingKeyAsync(viewingKey string) FutureZGetBalanceForViewingKeyResult {
+	cmd := zcashjson.NewZGetBalanceForViewingKeyCmd(viewingKey, nil)
+	return c.sendCmd(cmd)
+}
+
+// ZGetBalanceForViewingKey returns the balance received by the imported
+// viewing key in each value pool using the default number of minimum
+// confirmations.  This works for watch-only wallets, which can not spend the
+// funds.
+func (c *Client) ZGetBalanceForViewingKey(viewingKey string) (*zcashjson.ZGetBalanceForViewingKeyResult, error) {
+	return c.ZGetBalanceForViewingKeyAsync(viewingKey).Receive()
+}
+
+// ZGetBalanceForViewingKeyMinConfAsync returns an instance of a type that can
+// be used to get the result of the RPC at some future time by invoking the
+// Receive function on the returned instance.
+//
+// See ZGetBalanceForViewingKeyMinConf for the blocking version and more
+// details.
+func (c *Client) ZGetBalanceForViewingKeyMinConfAsync(viewingKey string, minConf int) FutureZGetBalanceForViewingKeyResult {
+	cmd := zcashjson.NewZGetBalanceForViewingKeyCmd(viewingKey, &minConf)
+	return c.sendCmd(cmd)
+}
+
+// ZGetBalanceForViewingKeyMinConf returns the balance received by the
+// imported viewing key in each value pool using the specified number of
+// minimum confirmations.
+func (c *Client) ZGetBalanceForViewingKeyMinConf(viewingKey string, minConf int) (*zcashjson.ZGetBalanceForViewingKeyResult, error) {
+	return c.ZGetBalanceForViewingKeyMinConfAsync(viewingKey, minConf).Receive()
+}
+
 // FutureZListReceivedByAddressResult is a future promise to deliver the result
 // of a ZListReceivedByAddressAsync RPC invocation (or an applicable error).
 type FutureZListReceivedByAddressResult chan *response
@@ -363,18 +892,146 @@ func (r FutureZListReceivedByAddressResult) Receive() ([]zcashjson.ZListReceived
 // function on the returned instance.
 //
 // See ZListReceivedByAddress for the blocking version and more details.
-func (c *Client) ZListReceivedByAddressAsync(address string) FutureZListReceivedByAddressResult {
-	cmd := zcashjson.NewZListReceivedByAddressCmd(address, nil)
+func (c *Client) ZListReceivedByAddressAsync(address zcashutil.Address) FutureZListReceivedByAddressResult {
+	cmd := zcashjson.NewZListReceivedByAddressCmd(address.EncodeAddress(), nil)
 	return c.sendCmd(cmd)
 }
 
 // ZListReceivedByAddress lists balances by address using the default number
 // of minimum confirmations not including addresses that haven't received any
 // payments or watching only addresses.
-func (c *Client) ZListReceivedByAddress(address string) ([]zcashjson.ZListReceivedByAddressResult, error) {
+func (c *Client) ZListReceivedByAddress(address zcashutil.Address) ([]zcashjson.ZListReceivedByAddressResult, error) {
 	return c.ZListReceivedByAddressAsync(address).Receive()
 }
 
+// FutureZListUnspentResult is a future promise to deliver the result of a
+// ZListUnspentAsync, ZListUnspentMinMaxAsync, or
+// ZListUnspentMinMaxAddressesAsync RPC invocation (or an applicable error).
+type FutureZListUnspentResult chan *response
+
+// Receive waits for the response promised by the future and returns all
+// unspent shielded notes returned by the RPC call.
+func (r FutureZListUnspentResult) Receive() ([]zcashjson.ZListUnspentResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of z_listunspent results.
+	var unspent []zcashjson.ZListUnspentResult
+	err = json.Unmarshal(res, &unspent)
+	if err != nil {
+		return nil, err
+	}
+
+	return unspent, nil
+}
+
+// ZListUnspentAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See ZListUnspent for the blocking version and more details.
+func (c *Client) ZListUnspentAsync() FutureZListUnspentResult {
+	cmd := zcashjson.NewZListUnspentCmd(nil, nil, nil, nil)
+	return c.sendCmd(cmd)
+}
+
+// ZListUnspentMinMaxAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ZListUnspentMinMax for the blocking version and more details.
+func (c *Client) ZListUnspentMinMaxAsync(minConf, maxConf int) FutureZListUnspentResult {
+	cmd := zcashjson.NewZListUnspentCmd(&minConf, &maxConf, nil, nil)
+	return c.sendCmd(cmd)
+}
+
+// ZListUnspentMinMaxAddressesAsync returns an instance of a type that can be
+// used to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ZListUnspentMinMaxAddresses for the blocking version and more details.
+func (c *Client) ZListUnspentMinMaxAddressesAsync(minConf, maxConf int, includeWatchOnly bool, addrs []zcashutil.Address) FutureZListUnspentResult {
+	addrStrs := encodeAddresses(addrs)
+	cmd := zcashjson.NewZListUnspentCmd(&minConf, &maxConf,
+		&includeWatchOnly, &addrStrs)
+	return c.sendCmd(cmd)
+}
+
+// ZListUnspent returns all unspent shielded notes known to a wallet, using the
+// default number of minimum and maximum number of confirmations as a filter
+// (1 and 9999999, respectively).  Notes of watch-only addresses are not
+// included.
+func (c *Client) ZListUnspent() ([]zcashjson.ZListUnspentResult, error) {
+	return c.ZListUnspentAsync().Receive()
+}
+
+// ZListUnspentMinMax returns all unspent shielded notes known to a wallet,
+// using the specified number of minimum and maximum number of confirmations as
+// a filter.
+func (c *Client) ZListUnspentMinMax(minConf, maxConf int) ([]zcashjson.ZListUnspentResult, error) {
+	return c.ZListUnspentMinMaxAsync(minConf, maxConf).Receive()
+}
+
+// ZListUnspentMinMaxAddresses returns all unspent shielded notes received by
+// any of the specified addresses in a wallet, using the specified number of
+// minimum and maximum number of confirmations as a filter.  Notes of
+// watch-only addresses are only included when includeWatchOnly is true.
+func (c *Client) ZListUnspentMinMaxAddresses(minConf, maxConf int, includeWatchOnly bool, addrs []zcashutil.Address) ([]zcashjson.ZListUnspentResult, error) {
+	return c.ZListUnspentMinMaxAddressesAsync(minConf, maxConf,
+		includeWatchOnly, addrs).Receive()
+}
+
+// *****************************
+// Transaction Listing Functions
+// *****************************
+
+// FutureZViewTransactionResult is a future promise to deliver the result of a
+// ZViewTransactionAsync RPC invocation (or an applicable error).
+type FutureZViewTransactionResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// shielded spends and outputs of the transaction.
+func (r FutureZViewTransactionResult) Receive() (*zcashjson.ZViewTransactionResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a z_viewtransaction result object.
+	var view zcashjson.ZViewTransactionResult
+	err = json.Unmarshal(res, &view)
+	if err != nil {
+		return nil, err
+	}
+
+	return &view, nil
+}
+
+// ZViewTransactionAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See ZViewTransaction for the blocking version and more details.
+func (c *Client) ZViewTransactionAsync(txHash *chainhash.Hash) FutureZViewTransactionResult {
+	hash := ""
+	if txHash != nil {
+		hash = txHash.String()
+	}
+
+	cmd := zcashjson.NewZViewTransactionCmd(hash)
+	return c.sendCmd(cmd)
+}
+
+// ZViewTransaction returns the shielded spends and outputs of a wallet
+// transaction that the wallet is able to decrypt, grouped into spends and
+// outputs with the pool, address and value of each.  The memo of each output
+// can be interpreted with zcashutil.DecodeMemo.
+func (c *Client) ZViewTransaction(txHash *chainhash.Hash) (*zcashjson.ZViewTransactionResult, error) {
+	return c.ZViewTransactionAsync(txHash).Receive()
+}
+
 // ***********************
 // Export/Import Functions
 // ***********************
@@ -406,16 +1063,54 @@ func (r FutureZExportKeyResult) Receive() (string, error) {
 // returned instance.
 //
 // See ZExportKey for the blocking version and more details.
-func (c *Client) ZExportKeyAsync(address string) FutureZExportKeyResult {
-	cmd := zcashjson.NewZExportKeyCmd(address)
+func (c *Client) ZExportKeyAsync(address zcashutil.Address) FutureZExportKeyResult {
+	cmd := zcashjson.NewZExportKeyCmd(address.EncodeAddress())
 	return c.sendCmd(cmd)
 }
 
 // ZExportKey gets the private key corresponding to the passed address.
-func (c *Client) ZExportKey(address string) (string, error) {
+func (c *Client) ZExportKey(address zcashutil.Address) (string, error) {
 	return c.ZExportKeyAsync(address).Receive()
 }
 
+// FutureZExportViewingKeyResult is a future promise to deliver the result of
+// a ZExportViewingKeyAsync RPC invocation (or an applicable error).
+type FutureZExportViewingKeyResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// viewing key corresponding to the passed address.
+func (r FutureZExportViewingKeyResult) Receive() (string, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return "", err
+	}
+
+	// Unmarshal result as a string.
+	var vkey string
+	err = json.Unmarshal(res, &vkey)
+	if err != nil {
+		return "", err
+	}
+
+	return vkey, nil
+}
+
+// ZExportViewingKeyAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See ZExportViewingKey for the blocking version and more details.
+func (c *Client) ZExportViewingKeyAsync(address zcashutil.Address) FutureZExportViewingKeyResult {
+	cmd := zcashjson.NewZExportViewingKeyCmd(address.EncodeAddress())
+	return c.sendCmd(cmd)
+}
+
+// ZExportViewingKey gets the viewing key corresponding to the passed shielded
+// address.  Unified addresses export their unified full viewing key.
+func (c *Client) ZExportViewingKey(address zcashutil.Address) (string, error) {
+	return c.ZExportViewingKeyAsync(address).Receive()
+}
+
 // FutureZExportWalletResult is a future promise to deliver the result of a
 // ZExportWalletAsync RPC invocation (or an applicable error).
 type FutureZExportWalletResult chan *response
@@ -441,7 +1136,8 @@ func (c *Client) ZExportWalletAsync(filename string) FutureZExportWalletResult {
 	return c.sendCmd(cmd)
 }
 
-// ZExportWallet gets the private key corresponding to the passed address.
+// ZExportWallet writes every key of the wallet to the passed file on the
+// server, which can be read with the walletdump package.
 func (c *Client) ZExportWallet(filename string) error {
 	return c.ZExportWalletAsync(filename).Receive()
 }
@@ -476,6 +1172,129 @@ func (c *Client) ZImportKey(key string, rescan *bool) error {
 	return c.ZImportKeyAsync(key, rescan).Receive()
 }
 
+// FutureZImportViewingKeyResult is a future promise to deliver the result of
+// a ZImportViewingKeyAsync RPC invocation (or an applicable error).
+type FutureZImportViewingKeyResult chan *response
+
+// Receive waits for the response promised by the future and returns the type
+// and address of the imported viewing key.  Servers that do not report them
+// return an empty result.
+func (r FutureZImportViewingKeyResult) Receive() (*zcashjson.ZImportKeyResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a z_importviewingkey result object.
+	var imported zcashjson.ZImportKeyResult
+	err = json.Unmarshal(res, &imported)
+	if err != nil {
+		return nil, err
+	}
+
+	return &imported, nil
+}
+
+// ZImportViewingKeyAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See ZImportViewingKey for the blocking version and more details.
+func (c *Client) ZImportViewingKeyAsync(vkey string) FutureZImportViewingKeyResult {
+	cmd := zcashjson.NewZImportViewingKeyCmd(vkey, nil, nil)
+	return c.sendCmd(cmd)
+}
+
+// ZImportViewingKey imports the passed viewing key into the wallet, adding a
+// watch-only address.  The block chain is rescanned when the key is new to the
+// wallet.
+func (c *Client) ZImportViewingKey(vkey string) (*zcashjson.ZImportKeyResult, error) {
+	return c.ZImportViewingKeyAsync(vkey).Receive()
+}
+
+// ZImportViewingKeyRescanAsync returns an instance of a type that can be used
+// to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ZImportViewingKeyRescan for the blocking version and more details.
+func (c *Client) ZImportViewingKeyRescanAsync(vkey string, rescan zcashjson.RescanMode, startHeight int) FutureZImportViewingKeyResult {
+	cmd := zcashjson.NewZImportViewingKeyCmd(vkey, &rescan, &startHeight)
+	return c.sendCmd(cmd)
+}
+
+// ZImportViewingKeyRescan imports the passed viewing key into the wallet,
+// adding a watch-only address.  The rescan mode selects whether the block
+// chain is rescanned, and startHeight the block the rescan starts from.
+//
+// The server does not respond until the rescan completes, which can take a
+// long time.  See ZImportViewingKeyHeartbeat to be notified while it runs
+// and to abandon it.
+func (c *Client) ZImportViewingKeyRescan(vkey string, rescan zcashjson.RescanMode, startHeight int) (*zcashjson.ZImportKeyResult, error) {
+	return c.ZImportViewingKeyRescanAsync(vkey, rescan, startHeight).Receive()
+}
+
+// RescanHeartbeat describes a rescan that is running as part of a key import.
+// The server holds its chain and wallet locks for the whole rescan, so no RPC
+// can report which block it has reached, and a heartbeat carries no progress.
+// It only tells the caller the import is still running, along with the range
+// of blocks requested and the time spent so far.
+type RescanHeartbeat struct {
+	StartHeight int64
+	EndHeight   int64
+	Elapsed     time.Duration
+}
+
+// ZImportViewingKeyHeartbeat imports the passed viewing key like
+// ZImportViewingKeyRescan and invokes the heartbeat function every interval
+// until the import completes, so long imports can be told apart from stuck
+// connections.  The end height of the heartbeats is the block count when the
+// import starts.  An error is returned if the interval is not positive.
+//
+// A stuck or unwanted rescan is abandoned by cancelling the passed context, in
+// which case the context's error is returned.  Abandoning the import only
+// stops waiting for it, as the server carries on with the rescan.
+func (c *Client) ZImportViewingKeyHeartbeat(ctx context.Context, vkey string, rescan zcashjson.RescanMode, startHeight int, interval time.Duration, heartbeat func(*RescanHeartbeat)) (*zcashjson.ZImportKeyResult, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid heartbeat interval %v", interval)
+	}
+
+	// The block count is requested first, since the server holds the
+	// chain lock while it rescans.
+	countFuture := c.GetBlockCountAsync()
+	if err := c.WaitForResponse(ctx, countFuture); err != nil {
+		return nil, err
+	}
+	endHeight, err := countFuture.Receive()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	future := c.ZImportViewingKeyRescanAsync(vkey, rescan, startHeight)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case res := <-future:
+			done := make(FutureZImportViewingKeyResult, 1)
+			done <- res
+			return done.Receive()
+
+		case <-ticker.C:
+			heartbeat(&RescanHeartbeat{
+				StartHeight: int64(startHeight),
+				EndHeight:   endHeight,
+				Elapsed:     time.Since(start),
+			})
+
+		case <-ctx.Done():
+			c.cancelRequest(future, ctx.Err())
+			return nil, ctx.Err()
+		}
+	}
+}
+
 // FutureZImportWalletResult is a future promise to deliver the result of a
 // ZImportWalletAsync RPC invocation (or an applicable error).
 type FutureZImportWalletResult chan *response
@@ -501,7 +1320,8 @@ func (c *Client) ZImportWalletAsync(filename string) FutureZImportWalletResult {
 	return c.sendCmd(cmd)
 }
 
-// ZImportWallet gets the private key corresponding to the passed address.
+// ZImportWallet imports every key in the passed wallet dump file on the server.
+// The walletdump package can validate a dump before it is imported.
 func (c *Client) ZImportWallet(filename string) error {
 	return c.ZImportWalletAsync(filename).Receive()
 }
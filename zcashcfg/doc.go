@@ -0,0 +1,20 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package zcashcfg defines Zcash network parameters.
+//
+// The btcsuite chaincfg package describes Bitcoin networks, whose address
+// encodings and consensus rules do not apply to Zcash.  This package provides
+// the equivalent parameters for the Zcash main, test and regression test
+// networks so addresses and keys can be associated with the network they are
+// intended for.  It also defines the consensus branch IDs that identify the
+// rules of each network upgrade, along with the message magic, default ports
+// and upgrade activation heights of each network, so the branch ID in effect
+// at a given height can be looked up with BranchID, as well as the Equihash
+// and difficulty adjustment parameters of its proof-of-work.
+//
+// For library packages, zcashcfg provides the ability to lookup network
+// parameters and encoding magics when passed a *Params.  Callers may also
+// register custom networks with Register.
+package zcashcfg
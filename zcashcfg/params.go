@@ -0,0 +1,419 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashcfg
+
+import (
+	"errors"
+	"math/big"
+	"time"
+)
+
+var (
+	// bigOne is 1 represented as a big.Int.  It is defined here to avoid
+	// the overhead of creating it multiple times.
+	bigOne = big.NewInt(1)
+
+	// mainPowLimit is the highest proof of work value a Zcash block can
+	// have for the main network.  It is the value 2^243 - 1.
+	mainPowLimit = new(big.Int).Sub(new(big.Int).Lsh(bigOne, 243), bigOne)
+
+	// testNetPowLimit is the highest proof of work value a Zcash block can
+	// have for the test network.  It is the value 2^251 - 1.
+	testNetPowLimit = new(big.Int).Sub(new(big.Int).Lsh(bigOne, 251), bigOne)
+
+	// regressionPowLimit is the highest proof of work value a Zcash block
+	// can have for the regression test network.  It is the value
+	// 0x0f0f...0f.
+	regressionPowLimit, _ = new(big.Int).SetString(
+		"0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f0f", 16)
+)
+
+var (
+	// ErrDuplicateNet describes an error where the parameters for a Zcash
+	// network could not be set due to the network already being a standard
+	// network or previously-registered into this package.
+	ErrDuplicateNet = errors.New("duplicate Zcash network")
+
+	// ErrUnknownHRP describes an error where the provided human-readable
+	// part is not associated with any registered network.
+	ErrUnknownHRP = errors.New("unknown human-readable part")
+
+	// ErrUnknownNet describes an error where the provided network name is
+	// not associated with any registered network.
+	ErrUnknownNet = errors.New("unknown Zcash network")
+)
+
+// ZcashNet represents which Zcash network a message belongs to.  It is the
+// magic that starts every peer-to-peer message, read as a little-endian
+// integer.
+type ZcashNet uint32
+
+// These constants define the magics of the default Zcash networks.
+const (
+	// MainNet represents the main Zcash network.
+	MainNet ZcashNet = 0x6427e924
+
+	// TestNet represents the test Zcash network.
+	TestNet ZcashNet = 0xbff91afa
+
+	// RegTest represents the regression test Zcash network.
+	RegTest ZcashNet = 0x5f3fe8aa
+)
+
+// NetworkUpgrade describes the activation of a network upgrade.
+type NetworkUpgrade struct {
+	BranchID         BranchID
+	ActivationHeight int32
+}
+
+// Params defines a Zcash network by its parameters.  These parameters may be
+// used by Zcash applications to differentiate networks as well as addresses
+// and keys for one network from those intended for use on another network.
+type Params struct {
+	// Name defines a human-readable identifier for the network.  It
+	// matches the chain name reported by zcashd.
+	Name string
+
+	// Net defines the magic bytes used to identify the network.
+	Net ZcashNet
+
+	// DefaultPort defines the default peer-to-peer port for the network,
+	// and RPCPort the default port of the JSON-RPC server.
+	DefaultPort string
+	RPCPort     string
+
+	// Upgrades defines the network upgrades scheduled on the network,
+	// ordered by activation height.  Blocks below the first activation
+	// height follow the Sprout rules.
+	Upgrades []NetworkUpgrade
+
+	// EquihashN and EquihashK are the parameters of the Equihash
+	// proof-of-work.
+	EquihashN uint32
+	EquihashK uint32
+
+	// PowLimit defines the highest allowed proof of work value for a block
+	// as a uint256.
+	PowLimit *big.Int
+
+	// PowAveragingWindow is the number of blocks whose targets are
+	// averaged by the difficulty adjustment.  PowMaxAdjustDown and
+	// PowMaxAdjustUp limit how far, in percent, the difficulty may move
+	// down and up in a single block.
+	PowAveragingWindow int32
+	PowMaxAdjustDown   int64
+	PowMaxAdjustUp     int64
+
+	// PreBlossomPowTargetSpacing and PostBlossomPowTargetSpacing are the
+	// desired amount of time between blocks before and after Blossom.
+	PreBlossomPowTargetSpacing  time.Duration
+	PostBlossomPowTargetSpacing time.Duration
+
+	// PowNoRetargeting defines whether every block keeps the difficulty
+	// of its parent instead of being adjusted.
+	PowNoRetargeting bool
+
+	// ReduceMinDifficulty defines whether blocks after
+	// MinDifficultyReductionHeight may be mined at the minimum difficulty
+	// when their timestamp is more than six target spacings after the
+	// previous block.
+	ReduceMinDifficulty          bool
+	MinDifficultyReductionHeight int32
+
+	// Address encoding magics.  Transparent and Sprout addresses use
+	// two-byte Base58Check prefixes, while Sapling and unified addresses
+	// use Bech32 and Bech32m human-readable parts.
+	PubKeyHashAddrID      [2]byte
+	ScriptHashAddrID      [2]byte
+	SproutPaymentAddrID   [2]byte
+	SaplingPaymentAddrHRP string
+	UnifiedAddrHRP        string
+
+	// Unified viewing key human-readable parts
+	UnifiedFullViewingKeyHRP     string
+	UnifiedIncomingViewingKeyHRP string
+
+	// Spending key encoding magics.  Transparent keys use the WIF format,
+	// Sprout spending keys a two-byte Base58Check prefix and Sapling
+	// extended spending keys a Bech32 human-readable part.
+	PrivateKeyID                  byte
+	SproutSpendingKeyID           [2]byte
+	SaplingExtendedSpendingKeyHRP string
+}
+
+// MainNetParams defines the network parameters for the main Zcash network.
+var MainNetParams = Params{
+	Name:        "main",
+	Net:         MainNet,
+	DefaultPort: "8233",
+	RPCPort:     "8232",
+
+	Upgrades: []NetworkUpgrade{
+		{BranchOverwinter, 347500},
+		{BranchSapling, 419200},
+		{BranchBlossom, 653600},
+		{BranchHeartwood, 903000},
+		{BranchCanopy, 1046400},
+		{BranchNU5, 1687104},
+		{BranchNU6, 2726400},
+	},
+
+	// Proof-of-work parameters
+	EquihashN:                   200,
+	EquihashK:                   9,
+	PowLimit:                    mainPowLimit,
+	PowAveragingWindow:          17,
+	PowMaxAdjustDown:            32,
+	PowMaxAdjustUp:              16,
+	PreBlossomPowTargetSpacing:  150 * time.Second,
+	PostBlossomPowTargetSpacing: 75 * time.Second,
+
+	// Address encoding magics
+	PubKeyHashAddrID:      [2]byte{0x1c, 0xb8}, // starts with t1
+	ScriptHashAddrID:      [2]byte{0x1c, 0xbd}, // starts with t3
+	SproutPaymentAddrID:   [2]byte{0x16, 0x9a}, // starts with zc
+	SaplingPaymentAddrHRP: "zs",
+	UnifiedAddrHRP:        "u",
+
+	// Unified viewing key human-readable parts
+	UnifiedFullViewingKeyHRP:     "uview",
+	UnifiedIncomingViewingKeyHRP: "uivk",
+
+	// Spending key encoding magics
+	PrivateKeyID:                  0x80,
+	SproutSpendingKeyID:           [2]byte{0xab, 0x36}, // starts with SK
+	SaplingExtendedSpendingKeyHRP: "secret-extended-key-main",
+}
+
+// TestNetParams defines the network parameters for the test Zcash network.
+var TestNetParams = Params{
+	Name:        "test",
+	Net:         TestNet,
+	DefaultPort: "18233",
+	RPCPort:     "18232",
+
+	Upgrades: []NetworkUpgrade{
+		{BranchOverwinter, 207500},
+		{BranchSapling, 280000},
+		{BranchBlossom, 584000},
+		{BranchHeartwood, 903800},
+		{BranchCanopy, 1028500},
+		{BranchNU5, 1842420},
+		{BranchNU6, 2976000},
+	},
+
+	// Proof-of-work parameters
+	EquihashN:                    200,
+	EquihashK:                    9,
+	PowLimit:                     testNetPowLimit,
+	PowAveragingWindow:           17,
+	PowMaxAdjustDown:             32,
+	PowMaxAdjustUp:               16,
+	PreBlossomPowTargetSpacing:   150 * time.Second,
+	PostBlossomPowTargetSpacing:  75 * time.Second,
+	ReduceMinDifficulty:          true,
+	MinDifficultyReductionHeight: 299187,
+
+	// Address encoding magics
+	PubKeyHashAddrID:      [2]byte{0x1d, 0x25}, // starts with tm
+	ScriptHashAddrID:      [2]byte{0x1c, 0xba}, // starts with t2
+	SproutPaymentAddrID:   [2]byte{0x16, 0xb6}, // starts with zt
+	SaplingPaymentAddrHRP: "ztestsapling",
+	UnifiedAddrHRP:        "utest",
+
+	// Unified viewing key human-readable parts
+	UnifiedFullViewingKeyHRP:     "uviewtest",
+	UnifiedIncomingViewingKeyHRP: "uivktest",
+
+	// Spending key encoding magics
+	PrivateKeyID:                  0xef,
+	SproutSpendingKeyID:           [2]byte{0xac, 0x08}, // starts with ST
+	SaplingExtendedSpendingKeyHRP: "secret-extended-key-test",
+}
+
+// RegressionNetParams defines the network parameters for the regression test
+// Zcash network.  Transparent and Sprout addresses share their encoding with
+// the test network.
+var RegressionNetParams = Params{
+	Name:        "regtest",
+	Net:         RegTest,
+	DefaultPort: "18344",
+	RPCPort:     "18232",
+
+	// Network upgrades are not scheduled on regtest by default.  Nodes
+	// started with -nuparams choose their own activation heights, which
+	// zcashd reports in getblockchaininfo.
+	Upgrades: nil,
+
+	// Proof-of-work parameters.  The difficulty is not adjusted on regtest.
+	EquihashN:                    48,
+	EquihashK:                    5,
+	PowLimit:                     regressionPowLimit,
+	PowAveragingWindow:           17,
+	PowMaxAdjustDown:             0,
+	PowMaxAdjustUp:               0,
+	PreBlossomPowTargetSpacing:   150 * time.Second,
+	PostBlossomPowTargetSpacing:  75 * time.Second,
+	PowNoRetargeting:             true,
+	ReduceMinDifficulty:          true,
+	MinDifficultyReductionHeight: 0,
+
+	// Address encoding magics
+	PubKeyHashAddrID:      [2]byte{0x1d, 0x25}, // starts with tm
+	ScriptHashAddrID:      [2]byte{0x1c, 0xba}, // starts with t2
+	SproutPaymentAddrID:   [2]byte{0x16, 0xb6}, // starts with zt
+	SaplingPaymentAddrHRP: "zregtestsapling",
+	UnifiedAddrHRP:        "uregtest",
+
+	// Unified viewing key human-readable parts
+	UnifiedFullViewingKeyHRP:     "uviewregtest",
+	UnifiedIncomingViewingKeyHRP: "uivkregtest",
+
+	// Spending key encoding magics
+	PrivateKeyID:                  0xef,
+	SproutSpendingKeyID:           [2]byte{0xac, 0x08}, // starts with ST
+	SaplingExtendedSpendingKeyHRP: "secret-extended-key-regtest",
+}
+
+var (
+	registeredNets    = make(map[string]*Params)
+	pubKeyHashAddrIDs = make(map[[2]byte]struct{})
+	scriptHashAddrIDs = make(map[[2]byte]struct{})
+	sproutAddrIDs     = make(map[[2]byte]struct{})
+	saplingAddrHRPs   = make(map[string]struct{})
+	unifiedHRPs       = make(map[string]*Params)
+)
+
+// Register registers the network parameters for a Zcash network.  This may
+// error with ErrDuplicateNet if the network is already registered (either
+// due to a previous Register call, or the network being one of the default
+// networks).
+//
+// Network parameters should be registered into this package by a main
+// package as early as possible.  Then, library packages may lookup networks
+// or network parameters based on inputs and work regardless of the network
+// being standard or not.
+func Register(params *Params) error {
+	if _, ok := registeredNets[params.Name]; ok {
+		return ErrDuplicateNet
+	}
+	registeredNets[params.Name] = params
+	pubKeyHashAddrIDs[params.PubKeyHashAddrID] = struct{}{}
+	scriptHashAddrIDs[params.ScriptHashAddrID] = struct{}{}
+	sproutAddrIDs[params.SproutPaymentAddrID] = struct{}{}
+	saplingAddrHRPs[params.SaplingPaymentAddrHRP] = struct{}{}
+	unifiedHRPs[params.UnifiedAddrHRP] = params
+	unifiedHRPs[params.UnifiedFullViewingKeyHRP] = params
+	unifiedHRPs[params.UnifiedIncomingViewingKeyHRP] = params
+	return nil
+}
+
+// mustRegister performs the same function as Register except it panics if
+// there is an error.  This should only be called from package init
+// functions.
+func mustRegister(params *Params) {
+	if err := Register(params); err != nil {
+		panic("failed to register network: " + err.Error())
+	}
+}
+
+// IsPubKeyHashAddrID returns whether the id is an identifier known to prefix
+// a transparent pay-to-pubkey-hash address on any registered network.
+func IsPubKeyHashAddrID(id [2]byte) bool {
+	_, ok := pubKeyHashAddrIDs[id]
+	return ok
+}
+
+// IsScriptHashAddrID returns whether the id is an identifier known to prefix
+// a transparent pay-to-script-hash address on any registered network.
+func IsScriptHashAddrID(id [2]byte) bool {
+	_, ok := scriptHashAddrIDs[id]
+	return ok
+}
+
+// IsSproutPaymentAddrID returns whether the id is an identifier known to
+// prefix a Sprout payment address on any registered network.
+func IsSproutPaymentAddrID(id [2]byte) bool {
+	_, ok := sproutAddrIDs[id]
+	return ok
+}
+
+// IsSaplingPaymentAddrHRP returns whether the human-readable part is known to
+// prefix a Sapling payment address on any registered network.
+func IsSaplingPaymentAddrHRP(hrp string) bool {
+	_, ok := saplingAddrHRPs[hrp]
+	return ok
+}
+
+// IsUnifiedAddrHRP returns whether the human-readable part is known to prefix
+// a unified address on any registered network.
+func IsUnifiedAddrHRP(hrp string) bool {
+	params, ok := unifiedHRPs[hrp]
+	return ok && params.UnifiedAddrHRP == hrp
+}
+
+// ParamsForUnifiedHRP returns the parameters of the registered network that
+// uses the human-readable part for unified addresses or unified full or
+// incoming viewing keys.  Unlike transparent prefixes, unified human-readable
+// parts are unique to each network, so they identify it unambiguously.
+func ParamsForUnifiedHRP(hrp string) (*Params, error) {
+	params, ok := unifiedHRPs[hrp]
+	if !ok {
+		return nil, ErrUnknownHRP
+	}
+	return params, nil
+}
+
+// ParamsForName returns the parameters of the registered network with the
+// passed name, which is the chain name reported by zcashd.
+func ParamsForName(name string) (*Params, error) {
+	params, ok := registeredNets[name]
+	if !ok {
+		return nil, ErrUnknownNet
+	}
+	return params, nil
+}
+
+// BranchID returns the consensus branch ID of the rules blocks at the passed
+// height are validated under.
+func (p *Params) BranchID(height int32) BranchID {
+	branch := BranchSprout
+	for _, upgrade := range p.Upgrades {
+		if height < upgrade.ActivationHeight {
+			break
+		}
+		branch = upgrade.BranchID
+	}
+	return branch
+}
+
+// ActivationHeight returns the height the network upgrade with the passed
+// branch ID activates at, and whether it is scheduled on the network.
+func (p *Params) ActivationHeight(branch BranchID) (int32, bool) {
+	for _, upgrade := range p.Upgrades {
+		if upgrade.BranchID == branch {
+			return upgrade.ActivationHeight, true
+		}
+	}
+	return 0, false
+}
+
+// PowTargetSpacing returns the desired amount of time between the block at
+// the passed height and its parent, which halved with Blossom.
+func (p *Params) PowTargetSpacing(height int32) time.Duration {
+	if activation, ok := p.ActivationHeight(BranchBlossom); ok &&
+		height >= activation {
+
+		return p.PostBlossomPowTargetSpacing
+	}
+	return p.PreBlossomPowTargetSpacing
+}
+
+func init() {
+	// Register all default networks when the package is initialized.
+	mustRegister(&MainNetParams)
+	mustRegister(&TestNetParams)
+	mustRegister(&RegressionNetParams)
+}
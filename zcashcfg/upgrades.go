@@ -0,0 +1,74 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashcfg
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrInvalidBranchID describes an error where a consensus branch ID is not
+// encoded as eight hex digits.
+var ErrInvalidBranchID = errors.New("invalid consensus branch ID")
+
+// BranchID identifies the consensus rules of a network upgrade.  It is
+// committed to by transaction signatures, so it must match the rules of the
+// block a transaction is mined in.
+type BranchID uint32
+
+// These constants define the consensus branch IDs of the Zcash network
+// upgrades.
+const (
+	BranchSprout     BranchID = 0
+	BranchOverwinter BranchID = 0x5ba81b19
+	BranchSapling    BranchID = 0x76b809bb
+	BranchBlossom    BranchID = 0x2bb40e60
+	BranchHeartwood  BranchID = 0xf5b9230b
+	BranchCanopy     BranchID = 0xe9ff75a6
+	BranchNU5        BranchID = 0xc2d6d0b4
+	BranchNU6        BranchID = 0xc8e71055
+)
+
+// branchIDStrings is a map of branch IDs back to the names of their network
+// upgrades for pretty printing.
+var branchIDStrings = map[BranchID]string{
+	BranchSprout:     "Sprout",
+	BranchOverwinter: "Overwinter",
+	BranchSapling:    "Sapling",
+	BranchBlossom:    "Blossom",
+	BranchHeartwood:  "Heartwood",
+	BranchCanopy:     "Canopy",
+	BranchNU5:        "NU5",
+	BranchNU6:        "NU6",
+}
+
+// String returns the name of the network upgrade the branch ID belongs to, or
+// the hex encoded ID for unknown upgrades.
+func (b BranchID) String() string {
+	if s, ok := branchIDStrings[b]; ok {
+		return s
+	}
+	return fmt.Sprintf("%08x", uint32(b))
+}
+
+// MarshalText encodes the branch ID as eight hex digits, the way zcashd
+// reports it.
+func (b BranchID) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%08x", uint32(b))), nil
+}
+
+// UnmarshalText decodes a branch ID from eight hex digits.
+func (b *BranchID) UnmarshalText(text []byte) error {
+	if len(text) != 8 {
+		return ErrInvalidBranchID
+	}
+	v, err := strconv.ParseUint(string(text), 16, 32)
+	if err != nil {
+		return ErrInvalidBranchID
+	}
+	*b = BranchID(v)
+	return nil
+}
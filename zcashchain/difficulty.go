@@ -0,0 +1,72 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashchain
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
+	"github.com/btcsuite/btcd/blockchain"
+)
+
+// CheckProofOfWork ensures the difficulty bits of the header encode a target
+// within the proof-of-work limit of the network, that the block hash does not
+// exceed the target, and that the Equihash solution is valid for the
+// parameters of the network.
+func CheckProofOfWork(header *zcashwire.BlockHeader, net *zcashcfg.Params) error {
+	target := blockchain.CompactToBig(header.Bits)
+	if target.Sign() <= 0 {
+		str := fmt.Sprintf("block target difficulty of %064x is too low",
+			target)
+		return ruleError(ErrUnexpectedDifficulty, str)
+	}
+	if target.Cmp(net.PowLimit) > 0 {
+		str := fmt.Sprintf("block target difficulty of %064x is higher "+
+			"than max of %064x", target, net.PowLimit)
+		return ruleError(ErrUnexpectedDifficulty, str)
+	}
+
+	hash := header.BlockHash()
+	if blockchain.HashToBig(&hash).Cmp(target) > 0 {
+		str := fmt.Sprintf("block hash of %v is higher than expected "+
+			"max of %064x", hash, target)
+		return ruleError(ErrHighHash, str)
+	}
+
+	return VerifyEquihash(net.EquihashN, net.EquihashK,
+		header.EquihashInput(), header.Solution)
+}
+
+// calcNextRequiredDifficulty computes the difficulty bits required of a
+// block from the mean target of the averaging window and the median times
+// past of the last block and of the block before the window.  This is the
+// DigiShield v3 adjustment used by Zcash, damped by a factor of four and
+// bounded by the maximum adjustments of the network.
+func calcNextRequiredDifficulty(net *zcashcfg.Params, meanTarget *big.Int,
+	lastMedianTime, firstMedianTime int64, nextHeight int32) uint32 {
+
+	spacing := int64(net.PowTargetSpacing(nextHeight).Seconds())
+	windowTimespan := int64(net.PowAveragingWindow) * spacing
+	minTimespan := windowTimespan * (100 - net.PowMaxAdjustUp) / 100
+	maxTimespan := windowTimespan * (100 + net.PowMaxAdjustDown) / 100
+
+	actualTimespan := lastMedianTime - firstMedianTime
+	actualTimespan = windowTimespan + (actualTimespan-windowTimespan)/4
+	if actualTimespan < minTimespan {
+		actualTimespan = minTimespan
+	}
+	if actualTimespan > maxTimespan {
+		actualTimespan = maxTimespan
+	}
+
+	newTarget := new(big.Int).Div(meanTarget, big.NewInt(windowTimespan))
+	newTarget.Mul(newTarget, big.NewInt(actualTimespan))
+	if newTarget.Cmp(net.PowLimit) > 0 {
+		newTarget.Set(net.PowLimit)
+	}
+	return blockchain.BigToCompact(newTarget)
+}
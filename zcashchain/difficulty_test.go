@@ -0,0 +1,127 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashchain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
+	"github.com/btcsuite/btcd/blockchain"
+)
+
+func TestCalcNextRequiredDifficulty(t *testing.T) {
+	// The main network averages 17 blocks of 150 seconds before Blossom
+	// and 75 seconds after it, so the window spans 2550 and 1275 seconds.
+	// The "slow" vector is the one of the zcashd proof-of-work tests.
+	const preBlossom, postBlossom = 500000, 700000
+	tests := []struct {
+		name       string
+		meanBits   uint32
+		timespan   int64
+		nextHeight int32
+		want       uint32
+	}{
+		{"on schedule", 0x1d00ffff, 2550, preBlossom, 0x1d00fffe},
+		{"slow", 0x1d00ffff, 3570, preBlossom, 0x1d011998},
+		{"fast", 0x1d00ffff, 1530, preBlossom, 0x1d00e665},
+		{"max adjust up", 0x1d00ffff, 0, preBlossom, 0x1d00d709},
+		{"max adjust down", 0x1d00ffff, 10000, preBlossom, 0x1d0151ea},
+		{"pow limit", 0x1f07ffff, 3570, preBlossom, 0x1f07ffff},
+		{"post-Blossom on schedule", 0x1d00ffff, 1275, postBlossom, 0x1d00fffe},
+		{"post-Blossom slow", 0x1d00ffff, 1785, postBlossom, 0x1d01197e},
+	}
+
+	const firstMedianTime = 1000000000
+	for _, test := range tests {
+		got := calcNextRequiredDifficulty(&zcashcfg.MainNetParams,
+			blockchain.CompactToBig(test.meanBits),
+			firstMedianTime+test.timespan, firstMedianTime,
+			test.nextHeight)
+		if got != test.want {
+			t.Errorf("%s: got %08x, want %08x", test.name, got,
+				test.want)
+		}
+	}
+}
+
+// newTestHeaderChain returns a header chain holding the headers from first to
+// last.  The headers share the difficulty bits and are spaced by the
+// pre-Blossom target spacing.
+func newTestHeaderChain(net *zcashcfg.Params, first, last int32, bits uint32) *HeaderChain {
+	c := NewHeaderChain(net, first)
+	start := time.Unix(1500000000, 0)
+	for i := int32(0); i <= last-first; i++ {
+		c.headers = append(c.headers, &zcashwire.BlockHeader{
+			Version:   4,
+			Timestamp: start.Add(time.Duration(i) * net.PreBlossomPowTargetSpacing),
+			Bits:      bits,
+		})
+	}
+	return c
+}
+
+func TestNextRequiredDifficulty(t *testing.T) {
+	// The test network allows blocks more than six target spacings after
+	// their parent to be mined at the minimum difficulty from height
+	// 299187 on.  Its target spacing drops from 150 to 75 seconds at
+	// Blossom, height 584000.
+	testNetLimit := blockchain.BigToCompact(zcashcfg.TestNetParams.PowLimit)
+	tests := []struct {
+		name  string
+		net   *zcashcfg.Params
+		last  int32
+		delay time.Duration
+		want  uint32
+	}{
+		{"main late block", &zcashcfg.MainNetParams, 299187,
+			time.Hour, 0x1c0ffffe},
+		{"testnet before min difficulty", &zcashcfg.TestNetParams, 299186,
+			time.Hour, 0x1c0ffffe},
+		{"testnet six spacings", &zcashcfg.TestNetParams, 299187,
+			900 * time.Second, 0x1c0ffffe},
+		{"testnet min difficulty", &zcashcfg.TestNetParams, 299187,
+			901 * time.Second, testNetLimit},
+		{"testnet Blossom six spacings", &zcashcfg.TestNetParams, 583999,
+			450 * time.Second, 0x1c13fd95},
+		{"testnet Blossom min difficulty", &zcashcfg.TestNetParams, 583999,
+			451 * time.Second, testNetLimit},
+		{"regtest", &zcashcfg.RegressionNetParams, 1000,
+			time.Hour, 0x1c0fffff},
+	}
+
+	for _, test := range tests {
+		first := test.last - DifficultyContext(test.net) + 1
+		c := newTestHeaderChain(test.net, first, test.last, 0x1c0fffff)
+		next := &zcashwire.BlockHeader{
+			Timestamp: c.header(test.last).Timestamp.Add(test.delay),
+		}
+		got, ok := c.nextRequiredDifficulty(next)
+		if !ok {
+			t.Errorf("%s: difficulty not computed", test.name)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: got %08x, want %08x", test.name, got,
+				test.want)
+		}
+	}
+
+	// The first blocks of the chain are mined at the minimum difficulty
+	// until there are enough blocks to average.
+	c := newTestHeaderChain(&zcashcfg.TestNetParams, 0, 16, 0x1c0fffff)
+	next := &zcashwire.BlockHeader{Timestamp: c.header(16).Timestamp}
+	if got, ok := c.nextRequiredDifficulty(next); !ok || got != testNetLimit {
+		t.Errorf("early chain: got %08x, %v, want %08x", got, ok,
+			testNetLimit)
+	}
+
+	// The difficulty is unknown until the headers it depends on are.
+	c = newTestHeaderChain(&zcashcfg.MainNetParams, 1000, 1000, 0x1c0fffff)
+	if _, ok := c.nextRequiredDifficulty(next); ok {
+		t.Errorf("single header: difficulty computed")
+	}
+}
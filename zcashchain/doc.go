@@ -0,0 +1,35 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package zcashchain validates Zcash block headers so the chain reported by a
+node does not have to be trusted blindly.
+
+Proof of Work
+
+Zcash blocks are mined with the Equihash memory-hard proof-of-work.  A header
+carries a solution to the Equihash generalized birthday problem over the
+BLAKE2b hashes of the rest of the header, and its hash must additionally not
+exceed the target encoded by its difficulty bits.  VerifyEquihash checks a
+solution for any parameters, including the n=200, k=9 parameters of mainnet
+and testnet and the n=48, k=5 parameters of regtest, which are part of the
+zcashcfg network parameters.  CheckProofOfWork checks both the solution and
+the target of a header.
+
+Difficulty Adjustment
+
+The difficulty of each block is derived from the mean target of the previous
+PowAveragingWindow blocks and the median times past at both ends of that
+window, damped and bounded as described by the Zcash protocol specification.
+Testnet additionally allows blocks mined long after their parent to use the
+minimum difficulty, while regtest never adjusts it.
+
+Header Chains
+
+A HeaderChain connects a contiguous sequence of headers, checking each one
+references the previous, carries a valid proof-of-work, has a timestamp after
+the median time past and has the required difficulty.  Violations are
+reported as a RuleError whose ErrorCode identifies the rule.
+*/
+package zcashchain
@@ -0,0 +1,158 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashchain
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/arithmetric/zcashrpcclient/internal/blake2b"
+)
+
+// equihashPersonalPrefix is the start of the BLAKE2b personalization of the
+// Equihash hash function, which is followed by the little endian n and k.
+var equihashPersonalPrefix = []byte("ZcashPoW")
+
+// EquihashSolutionSize returns the size of a minimally encoded Equihash
+// solution for the passed parameters.  A solution holds 2^k indices of
+// n/(k+1)+1 bits each.
+func EquihashSolutionSize(n, k uint32) int {
+	return (1 << k) * int(n/(k+1)+1) / 8
+}
+
+// VerifyEquihash checks that the solution is a valid Equihash solution with
+// parameters n and k for the passed input, which for block headers is the
+// serialized header without its solution.  A nil error means the solution is
+// valid.
+//
+// The solution is valid when the 2^k indices it encodes are distinct, the
+// hashes of the indices XOR to zero, the hashes of each pair of sibling
+// subtrees collide on the n/(k+1) bits of their level, and the first index of
+// each left subtree is below the first index of its right sibling.
+func VerifyEquihash(n, k uint32, input, solution []byte) error {
+	if n%8 != 0 || k == 0 || k >= n || n%(k+1) != 0 || n > 512 {
+		return fmt.Errorf("invalid Equihash parameters n=%d, k=%d", n, k)
+	}
+	collisionBits := n / (k + 1)
+	if collisionBits+1 > 32 {
+		return fmt.Errorf("invalid Equihash parameters n=%d, k=%d", n, k)
+	}
+	if len(solution) != EquihashSolutionSize(n, k) {
+		return ruleError(ErrBadEquihashSolution, fmt.Sprintf(
+			"Equihash solution is %d bytes instead of %d",
+			len(solution), EquihashSolutionSize(n, k)))
+	}
+
+	indices := expandIndices(solution, collisionBits+1)
+	seen := make(map[uint32]struct{}, len(indices))
+	for _, index := range indices {
+		if _, ok := seen[index]; ok {
+			return ruleError(ErrBadEquihashSolution, fmt.Sprintf(
+				"Equihash solution repeats index %d", index))
+		}
+		seen[index] = struct{}{}
+	}
+
+	// Each BLAKE2b invocation yields the hashes of several consecutive
+	// indices, so the outputs are cached.
+	hashSize := int(n / 8)
+	indicesPerHash := 512 / n
+	personal := make([]byte, blake2b.PersonalSize)
+	copy(personal, equihashPersonalPrefix)
+	binary.LittleEndian.PutUint32(personal[8:], n)
+	binary.LittleEndian.PutUint32(personal[12:], k)
+	outputs := make(map[uint32][]byte)
+
+	type node struct {
+		hash  []byte
+		first uint32
+	}
+	row := make([]node, len(indices))
+	for i, index := range indices {
+		out, ok := outputs[index/indicesPerHash]
+		if !ok {
+			h, err := blake2b.New(int(indicesPerHash)*hashSize, personal)
+			if err != nil {
+				return err
+			}
+			var counter [4]byte
+			binary.LittleEndian.PutUint32(counter[:],
+				index/indicesPerHash)
+			h.Write(input)
+			h.Write(counter[:])
+			out = h.Sum(nil)
+			outputs[index/indicesPerHash] = out
+		}
+
+		start := int(index%indicesPerHash) * hashSize
+		hash := make([]byte, hashSize)
+		copy(hash, out[start:start+hashSize])
+		row[i] = node{hash: hash, first: index}
+	}
+
+	// Combine sibling subtrees level by level up to the root.
+	for level := uint32(0); level < k; level++ {
+		next := make([]node, len(row)/2)
+		for i := range next {
+			left, right := row[2*i], row[2*i+1]
+			if right.first < left.first {
+				return ruleError(ErrBadEquihashSolution, fmt.Sprintf(
+					"Equihash solution indices are not "+
+						"ordered at level %d", level))
+			}
+
+			hash := make([]byte, hashSize)
+			for j := range hash {
+				hash[j] = left.hash[j] ^ right.hash[j]
+			}
+			if !zeroBits(hash, level*collisionBits, collisionBits) {
+				return ruleError(ErrBadEquihashSolution, fmt.Sprintf(
+					"Equihash solution hashes do not collide "+
+						"at level %d", level))
+			}
+			next[i] = node{hash: hash, first: left.first}
+		}
+		row = next
+	}
+
+	for _, b := range row[0].hash {
+		if b != 0 {
+			return ruleError(ErrBadEquihashSolution,
+				"Equihash solution hashes do not XOR to zero")
+		}
+	}
+	return nil
+}
+
+// expandIndices decodes the big endian indexBits-bit indices packed in the
+// minimal encoding of a solution.
+func expandIndices(solution []byte, indexBits uint32) []uint32 {
+	count := len(solution) * 8 / int(indexBits)
+	indices := make([]uint32, count)
+	var acc uint64
+	var accBits uint32
+	i := 0
+	for _, b := range solution {
+		acc = acc<<8 | uint64(b)
+		accBits += 8
+		if accBits >= indexBits {
+			accBits -= indexBits
+			indices[i] = uint32(acc>>accBits) & (1<<indexBits - 1)
+			i++
+		}
+	}
+	return indices
+}
+
+// zeroBits returns whether the count bits of the big endian bit string b
+// starting at bit offset are all zero.
+func zeroBits(b []byte, offset, count uint32) bool {
+	for bit := offset; bit < offset+count; bit++ {
+		if b[bit/8]&(0x80>>(bit%8)) != 0 {
+			return false
+		}
+	}
+	return true
+}
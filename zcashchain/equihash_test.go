@@ -0,0 +1,217 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashchain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
+)
+
+// The test headers carry the fields of the genesis blocks of their networks
+// with solutions found by an Equihash solver, and were checked by a verifier
+// written independently of this package.
+
+// equihashTestMainHeader is a header with a valid Equihash (200,9) solution,
+// whose hash is above the main network target.
+var equihashTestMainHeader = "0400000000000000000000000000000000000000000000000000000000000000" +
+	"00000000f24c7a85b768123f1dff1d4c4cece70083b2d27e117b4ac2e31d0879" +
+	"88a5eac400000000000000000000000000000000000000000000000000000000" +
+	"0000000090041358ffff071f5712000000000000000000000000000000000000" +
+	"000000000000000000000000fd4005002aebcfe14830da5ae08079aa1988f90f" +
+	"b93d395302899abab37bf459e7f441645e77f58622d77dd435078a5c13511d6c" +
+	"0ef294e80d22d8efdf0d129af551281474e3009ae4a3f8bf64534c5069a9cd8d" +
+	"5320080d147d4cedcbead9016c23c328a0b1ee79bfbdfbfc17d04be23818228b" +
+	"d43f24f35e54a391522aae9a6115f7c8c5e8c7e2bd4a07535c9b40da7ce6afef" +
+	"7eb219a561a99f8a197f0c3be82d8bcda5cea8b97e5c38016f129cae1bc179d2" +
+	"fa028b473c462e964adb45ec18f232673708dc24debe0279b448926d87367cdd" +
+	"710d7fb633d28935dcc0d0041460fe10f3c59c7fe06333190ca63d2e1aa5f0d1" +
+	"3995db4eed16709b58b0d20c0b4e4cf45f504f44b7a1c6203a17f214689f9739" +
+	"10b6691b805f3bd3e1afb273253b60512bdbccc0af29cedca19f7be7d7e4c535" +
+	"7022f384c1b0f3b10c5467e056cadc282723423cea3b4b57634b632cdb9dd703" +
+	"5331c2b5c487c111d9c54b7f7b5d9e55c15bcdd303ae7852a2e6bec9e7135582" +
+	"845cb4f5ffa5b978a914370e3ff434ca45e8e1016c6e97a726c43419ac82179d" +
+	"02c1805021a30dc5a18069bffb348431a508970746e1bb6a46f525937b91924f" +
+	"f61b4cd097e8f56d4770f53abb743efdaab607abb1fb3b1aacd9d642f70de0fa" +
+	"30a36ceb0b6c5c134271b5ffbe5a90f45fd01cf0b41a9cdaef2df939544e903b" +
+	"fd5a11a3bb40410542e2bef6cd0852ffb7a6016bdf021afe1a3902c834885422" +
+	"7b72bf35cd95b376f478a00cf2f3bdc3520c8543f4e823571b667c418927c8eb" +
+	"6c8a48f762452574c2180111214bb073c5302f7fcf0b7db99d1879078e88d286" +
+	"8bf5b597f17194243537097dbb5ae66b32e8ca93edcd72299982e43604306c65" +
+	"aea155739716cb49fb9e8a5f738e7f95dfbdf7d37b27abde7cac29e7cdc80d56" +
+	"98130f04a2e51a9e3204d3d45b2cf500f89adb980ddbbef095516acc5f8a11cb" +
+	"ab9ce9940f833dac179fad390e27d191d336d17384a81f8dba12a339fba649b9" +
+	"d769e5f20d4b4df989326410c5c32ed905eca2e3d9c969eb53ebe8e6f721f03a" +
+	"722b8003d1395811d3e5f8d114a6fcbbe8146a57b31fa30b099ed42c0f052865" +
+	"d10dc1977f485800c9d3b8b32906ecd5c4eb8d0266abfd22bef751a379980a38" +
+	"5c562d2aadd775f2923f9b1d22e1f0f204cd22965b733d059022766381951a26" +
+	"15f0b7630f60c1f3dd1b2e1e0eb4af70b4142dc9fd4b7182143b3e6cd9f597a5" +
+	"960607a220b04ebce1347342247e7c76993a23be877d0a355dc1281c20935693" +
+	"857e8b6a752e0019fee8cb0dbb03a8bcc68fa37eabb508093fe22e25723acd06" +
+	"31a00256d90cdff2c7f303c61aba273781cd3fd5d50dcd56be530d6653606c84" +
+	"84b5d7cb1e9503da494e3752ed870fdf47bfb981f59bf4ad071dbb25d1a74406" +
+	"1771ee86ee2c61916d7a7383f428334bac5f21e42fefd67a08e104e9aef2e975" +
+	"1f56fb2e6d4098192014732dbf3a6a39d36b4b888c636ac02ab91f5bc18c2d26" +
+	"14a6208fbca0894b28768b71b556a67257b26f096325f47c7cb1bbf41f12dff5" +
+	"298d320090d87e271042621afc0ef6ad8b6b85b7cefd9891f22e327e4c102396" +
+	"c66196753d7ed4b4665841ab769bfdb86e0240302c61d5995993084e1519d6bc" +
+	"de76af3cf6d529062f1dc7d1ced45133a22196afd0fb05a51b1ca54d118f2ef0" +
+	"0fc58d33bcf38a7f3b6288fad65ffca2020b1791dd4c98251555ebe0f5cafe33" +
+	"8276e49938a70ba0d1a13bf3d6d1ff79766f0c7f86da99311b970e0a5edda6a8" +
+	"e2a119725830dd941b4e70a7f4d9f6fd1a8672eb00d41b1cc1140597f358a4d2" +
+	"c12497de46170bafe41685dd00a03b81838bfabc2c7cd432c9e5227c2a9c8be3" +
+	"615b32ec66fe4a675e2a93017c1743"
+
+// equihashTestMainHash is the hash of equihashTestMainHeader.
+const equihashTestMainHash = "d994eb8239d65d24147af692e768db1dd91cee287f5bb4a4cd8eab0fdc2a71cd"
+
+// equihashTestRegTestHeader is a header with a valid Equihash (48,5) solution
+// whose hash meets the regression test network target.
+var equihashTestRegTestHeader = "0400000000000000000000000000000000000000000000000000000000000000" +
+	"00000000f24c7a85b768123f1dff1d4c4cece70083b2d27e117b4ac2e31d0879" +
+	"88a5eac400000000000000000000000000000000000000000000000000000000" +
+	"00000000dae5494d0f0f0f200100000000000000000000000000000000000000" +
+	"00000000000000000000000024053c139584b44211bd2c53599a48663a83cb0e" +
+	"70910db1d9be7b87686a6abf18243711d3"
+
+// equihashTestRegTestHash is the hash of equihashTestRegTestHeader.
+const equihashTestRegTestHash = "0ba31d4ee537c9703ef6e08bcddb8514e4b274e139cd91555ea6456bedf78ae3"
+
+// decodeTestHeader decodes a hex-encoded block header.
+func decodeTestHeader(t *testing.T, s string) *zcashwire.BlockHeader {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("hex.DecodeString: %v", err)
+	}
+	var header zcashwire.BlockHeader
+	if err := header.Deserialize(bytes.NewReader(b)); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	return &header
+}
+
+// compressIndices packs the indices into the minimal encoding of a solution
+// with indexBits-bit indices.  It is the inverse of expandIndices.
+func compressIndices(indices []uint32, indexBits uint32) []byte {
+	solution := make([]byte, len(indices)*int(indexBits)/8)
+	bit := 0
+	for _, index := range indices {
+		for i := int(indexBits) - 1; i >= 0; i-- {
+			if index>>uint(i)&1 != 0 {
+				solution[bit/8] |= 0x80 >> uint(bit%8)
+			}
+			bit++
+		}
+	}
+	return solution
+}
+
+// checkRuleError reports an error unless err is a RuleError with the passed
+// code.
+func checkRuleError(t *testing.T, name string, err error, code ErrorCode) {
+	rerr, ok := err.(RuleError)
+	if !ok {
+		t.Errorf("%s: got %v, want %v", name, err, code)
+		return
+	}
+	if rerr.ErrorCode != code {
+		t.Errorf("%s: got %v (%v), want %v", name, rerr.ErrorCode,
+			rerr, code)
+	}
+}
+
+func TestVerifyEquihash(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		hash   string
+		n, k   uint32
+	}{
+		{"main", equihashTestMainHeader, equihashTestMainHash, 200, 9},
+		{"regtest", equihashTestRegTestHeader, equihashTestRegTestHash, 48, 5},
+	}
+
+	for _, test := range tests {
+		header := decodeTestHeader(t, test.header)
+		if hash := header.BlockHash(); hash.String() != test.hash {
+			t.Errorf("%s: hash %v, want %s", test.name, hash,
+				test.hash)
+		}
+		if size := EquihashSolutionSize(test.n, test.k); len(header.Solution) != size {
+			t.Errorf("%s: solution is %d bytes, want %d", test.name,
+				len(header.Solution), size)
+		}
+		input := header.EquihashInput()
+		if err := VerifyEquihash(test.n, test.k, input, header.Solution); err != nil {
+			t.Errorf("%s: VerifyEquihash: %v", test.name, err)
+			continue
+		}
+
+		indexBits := test.n/(test.k+1) + 1
+		indices := expandIndices(header.Solution, indexBits)
+		mutate := func(f func(indices []uint32)) []byte {
+			mutated := append([]uint32(nil), indices...)
+			f(mutated)
+			return compressIndices(mutated, indexBits)
+		}
+		half := len(indices) / 2
+		flipped := append([]byte(nil), header.Solution...)
+		flipped[len(flipped)-1] ^= 1
+		otherInput := append([]byte(nil), input...)
+		otherInput[len(otherInput)-1] ^= 1
+
+		negatives := []struct {
+			name     string
+			input    []byte
+			solution []byte
+		}{
+			{"truncated", input, header.Solution[:len(header.Solution)-1]},
+			{"flipped bit", input, flipped},
+			{"repeated index", input, mutate(func(indices []uint32) {
+				indices[1] = indices[0]
+			})},
+			{"swapped leaves", input, mutate(func(indices []uint32) {
+				indices[0], indices[1] = indices[1], indices[0]
+			})},
+			{"swapped subtrees", input, mutate(func(indices []uint32) {
+				copy(indices, append(indices[half:], indices[:half]...))
+			})},
+			{"other input", otherInput, header.Solution},
+		}
+		for _, neg := range negatives {
+			err := VerifyEquihash(test.n, test.k, neg.input, neg.solution)
+			checkRuleError(t, test.name+" "+neg.name, err,
+				ErrBadEquihashSolution)
+		}
+	}
+
+	if err := VerifyEquihash(200, 6, nil, nil); err == nil {
+		t.Errorf("VerifyEquihash(200, 6): got no error")
+	}
+}
+
+func TestCheckProofOfWork(t *testing.T) {
+	regtest := decodeTestHeader(t, equihashTestRegTestHeader)
+	if err := CheckProofOfWork(regtest, &zcashcfg.RegressionNetParams); err != nil {
+		t.Errorf("CheckProofOfWork(regtest): %v", err)
+	}
+
+	main := decodeTestHeader(t, equihashTestMainHeader)
+	checkRuleError(t, "main", CheckProofOfWork(main, &zcashcfg.MainNetParams),
+		ErrHighHash)
+
+	easy := *regtest
+	easy.Bits = 0x20100000
+	checkRuleError(t, "above limit", CheckProofOfWork(&easy,
+		&zcashcfg.RegressionNetParams), ErrUnexpectedDifficulty)
+
+	zero := *regtest
+	zero.Bits = 0
+	checkRuleError(t, "zero target", CheckProofOfWork(&zero,
+		&zcashcfg.RegressionNetParams), ErrUnexpectedDifficulty)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashchain
+
+import (
+	"fmt"
+)
+
+// ErrorCode identifies a kind of error.
+type ErrorCode int
+
+// These constants are used to identify a specific RuleError.
+const (
+	// ErrBadEquihashSolution indicates the Equihash solution of a block
+	// header is invalid.
+	ErrBadEquihashSolution ErrorCode = iota
+
+	// ErrUnexpectedDifficulty indicates the difficulty bits of a block
+	// header are out of range or differ from the difficulty required by
+	// the difficulty adjustment.
+	ErrUnexpectedDifficulty
+
+	// ErrHighHash indicates the block hash is higher than the target
+	// encoded by the difficulty bits of the header.
+	ErrHighHash
+
+	// ErrPrevBlockMismatch indicates a block header does not reference
+	// the block preceding it.
+	ErrPrevBlockMismatch
+
+	// ErrTimeTooOld indicates the timestamp of a block header is not after
+	// the median time of the blocks preceding it.
+	ErrTimeTooOld
+
+	// ErrHashMismatch indicates the hash of a block header differs from
+	// the hash the server reported for it.
+	ErrHashMismatch
+)
+
+// Map of ErrorCode values back to their constant names for pretty printing.
+var errorCodeStrings = map[ErrorCode]string{
+	ErrBadEquihashSolution:  "ErrBadEquihashSolution",
+	ErrUnexpectedDifficulty: "ErrUnexpectedDifficulty",
+	ErrHighHash:             "ErrHighHash",
+	ErrPrevBlockMismatch:    "ErrPrevBlockMismatch",
+	ErrTimeTooOld:           "ErrTimeTooOld",
+	ErrHashMismatch:         "ErrHashMismatch",
+}
+
+// String returns the ErrorCode as a human-readable name.
+func (e ErrorCode) String() string {
+	if s := errorCodeStrings[e]; s != "" {
+		return s
+	}
+	return fmt.Sprintf("Unknown ErrorCode (%d)", int(e))
+}
+
+// RuleError identifies a rule violation.  The caller can use type assertions
+// to determine if an error is a RuleError and access the ErrorCode field to
+// ascertain the specific reason for the rule violation.
+type RuleError struct {
+	ErrorCode   ErrorCode // Describes the kind of error
+	Description string    // Human readable description of the issue
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e RuleError) Error() string {
+	return e.Description
+}
+
+// ruleError creates a RuleError given a set of arguments.
+func ruleError(c ErrorCode, desc string) RuleError {
+	return RuleError{ErrorCode: c, Description: desc}
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashchain
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// medianTimeBlocks is the number of previous blocks which should be used to
+// calculate the median time used to validate block timestamps.
+const medianTimeBlocks = 11
+
+// int64Sorter implements sort.Interface to allow a slice of 64-bit integers
+// to be sorted.
+type int64Sorter []int64
+
+func (s int64Sorter) Len() int           { return len(s) }
+func (s int64Sorter) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s int64Sorter) Less(i, j int) bool { return s[i] < s[j] }
+
+// DifficultyContext returns the number of headers preceding a block which
+// its required difficulty depends on.  A HeaderChain checks the difficulty of
+// a header once it has connected that many headers before it.
+func DifficultyContext(net *zcashcfg.Params) int32 {
+	return net.PowAveragingWindow + medianTimeBlocks
+}
+
+// HeaderChain validates a contiguous sequence of block headers without the
+// blocks themselves.  Each connected header must reference the previous one,
+// carry a valid proof-of-work, have a timestamp after the median time of the
+// blocks preceding it and, once enough previous headers are known to compute
+// it, the difficulty required by the difficulty adjustment.
+//
+// The difficulty adjustment of a block depends on the DifficultyContext
+// headers before it, so the difficulty of the headers connected first is
+// only checked when the chain starts close enough to the genesis block.
+type HeaderChain struct {
+	net         *zcashcfg.Params
+	headers     []*zcashwire.BlockHeader
+	firstHeight int32
+	tipHash     chainhash.Hash
+}
+
+// NewHeaderChain returns a header chain for the passed network whose first
+// connected header will be the one at startHeight.
+func NewHeaderChain(net *zcashcfg.Params, startHeight int32) *HeaderChain {
+	return &HeaderChain{net: net, firstHeight: startHeight}
+}
+
+// Height returns the height of the last connected header, which is one less
+// than the start height when no header has been connected.
+func (c *HeaderChain) Height() int32 {
+	return c.firstHeight + int32(len(c.headers)) - 1
+}
+
+// TipHash returns the hash of the last connected header.
+func (c *HeaderChain) TipHash() chainhash.Hash {
+	return c.tipHash
+}
+
+// header returns the connected header at the passed height, or nil when it
+// is no longer or not yet known.
+func (c *HeaderChain) header(height int32) *zcashwire.BlockHeader {
+	if height < c.firstHeight || height > c.Height() {
+		return nil
+	}
+	return c.headers[height-c.firstHeight]
+}
+
+// medianTimePast returns the median timestamp of the block at the passed
+// height and the blocks preceding it, and whether enough headers are known to
+// compute it.
+func (c *HeaderChain) medianTimePast(height int32) (int64, bool) {
+	start := height - medianTimeBlocks + 1
+	if start < 0 {
+		start = 0
+	}
+	if height < 0 || start < c.firstHeight || height > c.Height() {
+		return 0, false
+	}
+
+	timestamps := make([]int64, 0, medianTimeBlocks)
+	for h := start; h <= height; h++ {
+		timestamps = append(timestamps, c.header(h).Timestamp.Unix())
+	}
+	sort.Sort(int64Sorter(timestamps))
+	return timestamps[len(timestamps)/2], true
+}
+
+// nextRequiredDifficulty returns the difficulty bits required of the header
+// connected next, and whether enough headers are known to compute them.
+func (c *HeaderChain) nextRequiredDifficulty(header *zcashwire.BlockHeader) (uint32, bool) {
+	last := c.Height()
+	if last < 0 || c.header(last) == nil {
+		return 0, false
+	}
+	if c.net.PowNoRetargeting {
+		return c.header(last).Bits, true
+	}
+	powLimitBits := blockchain.BigToCompact(c.net.PowLimit)
+
+	// Blocks far enough apart may be mined at the minimum difficulty on
+	// networks which allow it.
+	if c.net.ReduceMinDifficulty &&
+		last >= c.net.MinDifficultyReductionHeight {
+
+		spacing := c.net.PowTargetSpacing(last + 1)
+		maxTime := c.header(last).Timestamp.Add(6 * spacing)
+		if header.Timestamp.After(maxTime) {
+			return powLimitBits, true
+		}
+	}
+
+	// The first blocks of the chain are mined at the minimum difficulty
+	// until there are enough blocks to average.
+	first := last - c.net.PowAveragingWindow
+	if first < 0 {
+		return powLimitBits, true
+	}
+
+	meanTarget := new(big.Int)
+	for h := first + 1; h <= last; h++ {
+		prev := c.header(h)
+		if prev == nil {
+			return 0, false
+		}
+		meanTarget.Add(meanTarget, blockchain.CompactToBig(prev.Bits))
+	}
+	meanTarget.Div(meanTarget, big.NewInt(int64(c.net.PowAveragingWindow)))
+
+	lastMedianTime, ok := c.medianTimePast(last)
+	if !ok {
+		return 0, false
+	}
+	firstMedianTime, ok := c.medianTimePast(first)
+	if !ok {
+		return 0, false
+	}
+	return calcNextRequiredDifficulty(c.net, meanTarget, lastMedianTime,
+		firstMedianTime, last+1), true
+}
+
+// Connect validates the header as the successor of the last connected header
+// and connects it.  A RuleError describing the first rule the header
+// violates is returned when it is invalid, in which case it is not connected.
+func (c *HeaderChain) Connect(header *zcashwire.BlockHeader) error {
+	height := c.Height() + 1
+	if err := CheckProofOfWork(header, c.net); err != nil {
+		if rerr, ok := err.(RuleError); ok {
+			rerr.Description = fmt.Sprintf("block %d: %s", height,
+				rerr.Description)
+			return rerr
+		}
+		return err
+	}
+
+	if len(c.headers) != 0 && header.PrevBlock != c.tipHash {
+		str := fmt.Sprintf("block %d references previous block %v "+
+			"instead of %v", height, header.PrevBlock, c.tipHash)
+		return ruleError(ErrPrevBlockMismatch, str)
+	}
+
+	if medianTime, ok := c.medianTimePast(height - 1); ok &&
+		!header.Timestamp.After(time.Unix(medianTime, 0)) {
+
+		str := fmt.Sprintf("block %d timestamp of %v is not after "+
+			"the median time of %v", height, header.Timestamp,
+			time.Unix(medianTime, 0))
+		return ruleError(ErrTimeTooOld, str)
+	}
+
+	if bits, ok := c.nextRequiredDifficulty(header); ok &&
+		header.Bits != bits {
+
+		str := fmt.Sprintf("block %d difficulty bits of %08x are not "+
+			"the expected value of %08x", height, header.Bits, bits)
+		return ruleError(ErrUnexpectedDifficulty, str)
+	}
+
+	c.headers = append(c.headers, header)
+	c.tipHash = header.BlockHash()
+
+	// Only the headers needed to check the difficulty of the next header
+	// are kept.
+	keep := int(DifficultyContext(c.net))
+	if len(c.headers) > keep {
+		trim := len(c.headers) - keep
+		c.headers = append(c.headers[:0:0], c.headers[trim:]...)
+		c.firstHeight += int32(trim)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2016 arithmetric
+// Based on btcd by the btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// NOTE: This file is intended to house the RPC commands that are supported by
+// the address and spent indexes of Zcash nodes run with -insightexplorer.
+
+package zcashjson
+
+import (
+	"github.com/btcsuite/btcd/btcjson"
+)
+
+// AddressIndexRequest models the request object of the address index
+// commands.  Start and End limit the results to an inclusive range of block
+// heights, and ChainInfo requests the hash and height of the blocks the
+// results were read at.  Not every command accepts every field.
+type AddressIndexRequest struct {
+	Addresses []string `json:"addresses"`
+	Start     *int     `json:"start,omitempty"`
+	End       *int     `json:"end,omitempty"`
+	ChainInfo *bool    `json:"chainInfo,omitempty"`
+}
+
+// GetAddressBalanceCmd defines the getaddressbalance JSON-RPC command.
+type GetAddressBalanceCmd struct {
+	Request AddressIndexRequest
+}
+
+// NewGetAddressBalanceCmd returns a new instance which can be used to issue a
+// getaddressbalance JSON-RPC command.
+func NewGetAddressBalanceCmd(addresses []string) *GetAddressBalanceCmd {
+	return &GetAddressBalanceCmd{
+		Request: AddressIndexRequest{Addresses: addresses},
+	}
+}
+
+// GetAddressUtxosCmd defines the getaddressutxos JSON-RPC command.
+type GetAddressUtxosCmd struct {
+	Request AddressIndexRequest
+}
+
+// NewGetAddressUtxosCmd returns a new instance which can be used to issue a
+// getaddressutxos JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetAddressUtxosCmd(addresses []string, chainInfo *bool) *GetAddressUtxosCmd {
+	return &GetAddressUtxosCmd{
+		Request: AddressIndexRequest{
+			Addresses: addresses,
+			ChainInfo: chainInfo,
+		},
+	}
+}
+
+// GetAddressDeltasCmd defines the getaddressdeltas JSON-RPC command.
+type GetAddressDeltasCmd struct {
+	Request AddressIndexRequest
+}
+
+// NewGetAddressDeltasCmd returns a new instance which can be used to issue a
+// getaddressdeltas JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.  The server only
+// honors the height range when both start and end are given, and chain info
+// when the height range is.
+func NewGetAddressDeltasCmd(addresses []string, start, end *int, chainInfo *bool) *GetAddressDeltasCmd {
+	return &GetAddressDeltasCmd{
+		Request: AddressIndexRequest{
+			Addresses: addresses,
+			Start:     start,
+			End:       end,
+			ChainInfo: chainInfo,
+		},
+	}
+}
+
+// GetAddressTxIDsCmd defines the getaddresstxids JSON-RPC command.
+type GetAddressTxIDsCmd struct {
+	Request AddressIndexRequest
+}
+
+// NewGetAddressTxIDsCmd returns a new instance which can be used to issue a
+// getaddresstxids JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.  The server only
+// honors the height range when both start and end are given.
+func NewGetAddressTxIDsCmd(addresses []string, start, end *int) *GetAddressTxIDsCmd {
+	return &GetAddressTxIDsCmd{
+		Request: AddressIndexRequest{
+			Addresses: addresses,
+			Start:     start,
+			End:       end,
+		},
+	}
+}
+
+// GetAddressMempoolCmd defines the getaddressmempool JSON-RPC command.
+type GetAddressMempoolCmd struct {
+	Request AddressIndexRequest
+}
+
+// NewGetAddressMempoolCmd returns a new instance which can be used to issue a
+// getaddressmempool JSON-RPC command.
+func NewGetAddressMempoolCmd(addresses []string) *GetAddressMempoolCmd {
+	return &GetAddressMempoolCmd{
+		Request: AddressIndexRequest{Addresses: addresses},
+	}
+}
+
+// SpentInfoRequest models the request object of the getspentinfo command.
+type SpentInfoRequest struct {
+	TxID  string `json:"txid"`
+	Index uint32 `json:"index"`
+}
+
+// GetSpentInfoCmd defines the getspentinfo JSON-RPC command.
+type GetSpentInfoCmd struct {
+	Request SpentInfoRequest
+}
+
+// NewGetSpentInfoCmd returns a new instance which can be used to issue a
+// getspentinfo JSON-RPC command.
+func NewGetSpentInfoCmd(txID string, index uint32) *GetSpentInfoCmd {
+	return &GetSpentInfoCmd{
+		Request: SpentInfoRequest{
+			TxID:  txID,
+			Index: index,
+		},
+	}
+}
+
+func init() {
+	// No special flags for commands in this file.
+	flags := btcjson.UsageFlag(0)
+
+	btcjson.MustRegisterCmd("getaddressbalance", (*GetAddressBalanceCmd)(nil), flags)
+	btcjson.MustRegisterCmd("getaddressdeltas", (*GetAddressDeltasCmd)(nil), flags)
+	btcjson.MustRegisterCmd("getaddressmempool", (*GetAddressMempoolCmd)(nil), flags)
+	btcjson.MustRegisterCmd("getaddresstxids", (*GetAddressTxIDsCmd)(nil), flags)
+	btcjson.MustRegisterCmd("getaddressutxos", (*GetAddressUtxosCmd)(nil), flags)
+	btcjson.MustRegisterCmd("getspentinfo", (*GetSpentInfoCmd)(nil), flags)
+}
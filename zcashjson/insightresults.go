@@ -0,0 +1,83 @@
+// Copyright (c) 2016 arithmetric
+// Based on btcd by the btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashjson
+
+import (
+	"github.com/btcsuite/btcutil"
+)
+
+// GetAddressBalanceResult models the data from the getaddressbalance command.
+// Received is the total ever received by the addresses, including change.
+type GetAddressBalanceResult struct {
+	Balance  btcutil.Amount `json:"balance"`
+	Received btcutil.Amount `json:"received"`
+}
+
+// AddressUtxo models an unspent output in the getaddressutxos result.
+type AddressUtxo struct {
+	Address     string         `json:"address"`
+	TxID        string         `json:"txid"`
+	OutputIndex uint32         `json:"outputIndex"`
+	Script      string         `json:"script"`
+	Satoshis    btcutil.Amount `json:"satoshis"`
+	Height      int64          `json:"height"`
+}
+
+// GetAddressUtxosResult models the data from the getaddressutxos command when
+// chain info is requested.  Hash and Height identify the best block the
+// outputs were read at.
+type GetAddressUtxosResult struct {
+	Utxos  []AddressUtxo `json:"utxos"`
+	Hash   string        `json:"hash"`
+	Height int64         `json:"height"`
+}
+
+// AddressDelta models a change in the balance of an address in the
+// getaddressdeltas result.  Satoshis is negative for spends.
+type AddressDelta struct {
+	Satoshis btcutil.Amount `json:"satoshis"`
+	TxID     string         `json:"txid"`
+	Index    uint32         `json:"index"`
+	Height   int64          `json:"height"`
+	Address  string         `json:"address"`
+}
+
+// AddressIndexBlock models a block the address index was read at.
+type AddressIndexBlock struct {
+	Hash   string `json:"hash"`
+	Height int64  `json:"height"`
+}
+
+// GetAddressDeltasResult models the data from the getaddressdeltas command
+// when chain info is requested.  Start and End identify the blocks at the
+// ends of the requested height range.
+type GetAddressDeltasResult struct {
+	Deltas []AddressDelta    `json:"deltas"`
+	Start  AddressIndexBlock `json:"start"`
+	End    AddressIndexBlock `json:"end"`
+}
+
+// AddressMempoolDelta models a change in the balance of an address by a
+// mempool transaction in the getaddressmempool result.  PrevTxID and PrevOut
+// identify the output spent by a negative delta.
+type AddressMempoolDelta struct {
+	Address   string         `json:"address"`
+	TxID      string         `json:"txid"`
+	Index     uint32         `json:"index"`
+	Satoshis  btcutil.Amount `json:"satoshis"`
+	Timestamp int64          `json:"timestamp"`
+	PrevTxID  string         `json:"prevtxid,omitempty"`
+	PrevOut   *uint32        `json:"prevout,omitempty"`
+}
+
+// GetSpentInfoResult models the data from the getspentinfo command.  TxID and
+// Index identify the input spending the output, and Height the block it was
+// mined in.
+type GetSpentInfoResult struct {
+	TxID   string `json:"txid"`
+	Index  uint32 `json:"index"`
+	Height int64  `json:"height"`
+}
@@ -12,6 +12,22 @@ import (
 	"github.com/btcsuite/btcd/btcjson"
 )
 
+// GetBlockSubsidyCmd defines the getblocksubsidy JSON-RPC command.
+type GetBlockSubsidyCmd struct {
+	Height *int
+}
+
+// NewGetBlockSubsidyCmd returns a new instance which can be used to issue a
+// getblocksubsidy JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetBlockSubsidyCmd(height *int) *GetBlockSubsidyCmd {
+	return &GetBlockSubsidyCmd{
+		Height: height,
+	}
+}
+
 // ZGetBalanceCmd defines the z_getbalance JSON-RPC command.
 type ZGetBalanceCmd struct {
 	Address *string
@@ -32,16 +48,95 @@ func NewZGetBalanceCmd(address *string, minConf *int) *ZGetBalanceCmd {
 
 // ZGetNewAddressCmd defines the z_getnewaddress JSON-RPC command.
 type ZGetNewAddressCmd struct {
-	MinConf *int `jsonrpcdefault:"1"`
+	AddressType *ValuePool
 }
 
-// NewGetNewAddressCmd returns a new instance which can be used to issue a
-// getnewaddress JSON-RPC command.
+// NewZGetNewAddressCmd returns a new instance which can be used to issue a
+// z_getnewaddress JSON-RPC command.
 //
 // The parameters which are pointers indicate they are optional. Passing nil
 // for optional parameters will use the default value.
-func NewZGetNewAddressCmd() *ZGetNewAddressCmd {
-	return &ZGetNewAddressCmd{}
+func NewZGetNewAddressCmd(addressType *ValuePool) *ZGetNewAddressCmd {
+	return &ZGetNewAddressCmd{
+		AddressType: addressType,
+	}
+}
+
+// ZGetNewAccountCmd defines the z_getnewaccount JSON-RPC command.
+type ZGetNewAccountCmd struct{}
+
+// NewZGetNewAccountCmd returns a new instance which can be used to issue a
+// z_getnewaccount JSON-RPC command.
+func NewZGetNewAccountCmd() *ZGetNewAccountCmd {
+	return &ZGetNewAccountCmd{}
+}
+
+// ZGetAddressForAccountCmd defines the z_getaddressforaccount JSON-RPC
+// command.
+type ZGetAddressForAccountCmd struct {
+	Account          uint32
+	ReceiverTypes    *[]ReceiverType
+	DiversifierIndex *uint64
+}
+
+// NewZGetAddressForAccountCmd returns a new instance which can be used to
+// issue a z_getaddressforaccount JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.  The default receiver
+// types are P2PKH, Sapling and Orchard, and the default diversifier index is
+// the next unused one.
+func NewZGetAddressForAccountCmd(account uint32, receiverTypes *[]ReceiverType, diversifierIndex *uint64) *ZGetAddressForAccountCmd {
+	cmd := &ZGetAddressForAccountCmd{
+		Account:          account,
+		ReceiverTypes:    receiverTypes,
+		DiversifierIndex: diversifierIndex,
+	}
+
+	// Arguments are positional, so the receiver types must be filled in
+	// with their defaults when a diversifier index is given.
+	if cmd.ReceiverTypes == nil && cmd.DiversifierIndex != nil {
+		cmd.ReceiverTypes = &[]ReceiverType{ReceiverP2PKH,
+			ReceiverSapling, ReceiverOrchard}
+	}
+	return cmd
+}
+
+// ZListAccountsCmd defines the z_listaccounts JSON-RPC command.
+type ZListAccountsCmd struct{}
+
+// NewZListAccountsCmd returns a new instance which can be used to issue a
+// z_listaccounts JSON-RPC command.
+func NewZListAccountsCmd() *ZListAccountsCmd {
+	return &ZListAccountsCmd{}
+}
+
+// ZGetBalanceForAccountCmd defines the z_getbalanceforaccount JSON-RPC
+// command.
+type ZGetBalanceForAccountCmd struct {
+	Account uint32
+	MinConf *int `jsonrpcdefault:"1"`
+}
+
+// NewZGetBalanceForAccountCmd returns a new instance which can be used to
+// issue a z_getbalanceforaccount JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewZGetBalanceForAccountCmd(account uint32, minConf *int) *ZGetBalanceForAccountCmd {
+	return &ZGetBalanceForAccountCmd{
+		Account: account,
+		MinConf: minConf,
+	}
+}
+
+// ListAddressesCmd defines the listaddresses JSON-RPC command.
+type ListAddressesCmd struct{}
+
+// NewListAddressesCmd returns a new instance which can be used to issue a
+// listaddresses JSON-RPC command.
+func NewListAddressesCmd() *ListAddressesCmd {
+	return &ListAddressesCmd{}
 }
 
 // ZGetTotalBalanceCmd defines the z_gettotalbalance JSON-RPC command.
@@ -126,6 +221,66 @@ func NewZExportKeyCmd(address string) *ZExportKeyCmd {
 	}
 }
 
+// ZExportViewingKeyCmd defines the z_exportviewingkey JSON-RPC command.
+type ZExportViewingKeyCmd struct {
+	Address string
+}
+
+// NewZExportViewingKeyCmd returns a new instance which can be used to issue a
+// z_exportviewingkey JSON-RPC command.
+func NewZExportViewingKeyCmd(address string) *ZExportViewingKeyCmd {
+	return &ZExportViewingKeyCmd{
+		Address: address,
+	}
+}
+
+// ZImportViewingKeyCmd defines the z_importviewingkey JSON-RPC command.
+type ZImportViewingKeyCmd struct {
+	VKey        string
+	Rescan      *RescanMode `jsonrpcdefault:"\"whenkeyisnew\""`
+	StartHeight *int        `jsonrpcdefault:"0"`
+}
+
+// NewZImportViewingKeyCmd returns a new instance which can be used to issue a
+// z_importviewingkey JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewZImportViewingKeyCmd(vkey string, rescan *RescanMode, startHeight *int) *ZImportViewingKeyCmd {
+	cmd := &ZImportViewingKeyCmd{
+		VKey:        vkey,
+		Rescan:      rescan,
+		StartHeight: startHeight,
+	}
+
+	// Arguments are positional, so the rescan mode must be filled in with
+	// its default when a start height is given.
+	if cmd.Rescan == nil && cmd.StartHeight != nil {
+		rescan := RescanWhenKeyIsNew
+		cmd.Rescan = &rescan
+	}
+	return cmd
+}
+
+// ZGetBalanceForViewingKeyCmd defines the z_getbalanceforviewingkey JSON-RPC
+// command.
+type ZGetBalanceForViewingKeyCmd struct {
+	ViewingKey string
+	MinConf    *int `jsonrpcdefault:"1"`
+}
+
+// NewZGetBalanceForViewingKeyCmd returns a new instance which can be used to
+// issue a z_getbalanceforviewingkey JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewZGetBalanceForViewingKeyCmd(viewingKey string, minConf *int) *ZGetBalanceForViewingKeyCmd {
+	return &ZGetBalanceForViewingKeyCmd{
+		ViewingKey: viewingKey,
+		MinConf:    minConf,
+	}
+}
+
 // ZExportWalletCmd defines the z_exportwallet JSON-RPC command.
 type ZExportWalletCmd struct {
 	Filename string
@@ -142,13 +297,13 @@ func NewZExportWalletCmd(filename string) *ZExportWalletCmd {
 	}
 }
 
-// ZListAccountsCmd defines the z_listaccounts JSON-RPC command.
+// ZListAddressesCmd defines the z_listaddresses JSON-RPC command.
 type ZListAddressesCmd struct {
 	MinConf *int `jsonrpcdefault:"1"`
 }
 
-// NewZListAccountsCmd returns a new instance which can be used to issue a
-// z_listaccounts JSON-RPC command.
+// NewZListAddressesCmd returns a new instance which can be used to issue a
+// z_listaddresses JSON-RPC command.
 //
 // The parameters which are pointers indicate they are optional.  Passing nil
 // for optional parameters will use the default value.
@@ -158,89 +313,306 @@ func NewZListAddressesCmd(minConf *int) *ZListAddressesCmd {
 	}
 }
 
+// ZListUnspentCmd defines the z_listunspent JSON-RPC command.
+type ZListUnspentCmd struct {
+	MinConf          *int  `jsonrpcdefault:"1"`
+	MaxConf          *int  `jsonrpcdefault:"9999999"`
+	IncludeWatchOnly *bool `jsonrpcdefault:"false"`
+	Addresses        *[]string
+}
+
+// NewZListUnspentCmd returns a new instance which can be used to issue a
+// z_listunspent JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.  Addresses filters the
+// notes to those received by the passed addresses.
+func NewZListUnspentCmd(minConf, maxConf *int, includeWatchOnly *bool, addresses *[]string) *ZListUnspentCmd {
+	cmd := &ZListUnspentCmd{
+		MinConf:          minConf,
+		MaxConf:          maxConf,
+		IncludeWatchOnly: includeWatchOnly,
+		Addresses:        addresses,
+	}
+
+	// Arguments are positional, so the earlier ones must be filled in
+	// with their defaults when a later one is given.
+	if cmd.IncludeWatchOnly == nil && cmd.Addresses != nil {
+		includeWatchOnly := false
+		cmd.IncludeWatchOnly = &includeWatchOnly
+	}
+	if cmd.MaxConf == nil && cmd.IncludeWatchOnly != nil {
+		maxConf := 9999999
+		cmd.MaxConf = &maxConf
+	}
+	if cmd.MinConf == nil && cmd.MaxConf != nil {
+		minConf := 1
+		cmd.MinConf = &minConf
+	}
+	return cmd
+}
+
+// ZListUnifiedReceiversCmd defines the z_listunifiedreceivers JSON-RPC command.
+type ZListUnifiedReceiversCmd struct {
+	UnifiedAddress string
+}
+
+// NewZListUnifiedReceiversCmd returns a new instance which can be used to
+// issue a z_listunifiedreceivers JSON-RPC command.
+func NewZListUnifiedReceiversCmd(unifiedAddress string) *ZListUnifiedReceiversCmd {
+	return &ZListUnifiedReceiversCmd{
+		UnifiedAddress: unifiedAddress,
+	}
+}
+
+// ZViewTransactionCmd defines the z_viewtransaction JSON-RPC command.
+type ZViewTransactionCmd struct {
+	TxID string
+}
+
+// NewZViewTransactionCmd returns a new instance which can be used to issue a
+// z_viewtransaction JSON-RPC command.
+func NewZViewTransactionCmd(txID string) *ZViewTransactionCmd {
+	return &ZViewTransactionCmd{
+		TxID: txID,
+	}
+}
+
 // ZListOperationIdsCmd defines the z_listoperationids JSON-RPC command.
 type ZListOperationIdsCmd struct {
-	MinConf *int `jsonrpcdefault:"1"`
+	Status *string
 }
 
-// ZListOperationIdsCmd returns a new instance which can be used to issue a
+// NewZListOperationIdsCmd returns a new instance which can be used to issue a
 // z_listoperationids JSON-RPC command.
 //
 // The parameters which are pointers indicate they are optional.  Passing nil
 // for optional parameters will use the default value.
-func NewZListOperationIdsCmd(minConf *int) *ZListOperationIdsCmd {
+func NewZListOperationIdsCmd(status *string) *ZListOperationIdsCmd {
 	return &ZListOperationIdsCmd{
-		MinConf: minConf,
+		Status: status,
 	}
 }
 
 // ZGetOperationResultCmd defines the z_getoperationresult JSON-RPC command.
 type ZGetOperationResultCmd struct {
-	MinConf *int `jsonrpcdefault:"1"`
+	OperationIDs *[]string
 }
 
-// ZGetOperationResultCmd returns a new instance which can be used to issue a
-// z_getoperationresult JSON-RPC command.
+// NewZGetOperationResultCmd returns a new instance which can be used to issue
+// a z_getoperationresult JSON-RPC command.
 //
 // The parameters which are pointers indicate they are optional.  Passing nil
 // for optional parameters will use the default value.
-func NewZGetOperationResultCmd(minConf *int) *ZGetOperationResultCmd {
+func NewZGetOperationResultCmd(operationIDs *[]string) *ZGetOperationResultCmd {
 	return &ZGetOperationResultCmd{
-		MinConf: minConf,
+		OperationIDs: operationIDs,
 	}
 }
 
 // ZGetOperationStatusCmd defines the z_getoperationstatus JSON-RPC command.
 type ZGetOperationStatusCmd struct {
-	MinConf *int `jsonrpcdefault:"1"`
+	OperationIDs *[]string
 }
 
-// ZGetOperationStatusCmd returns a new instance which can be used to issue a
-// z_getoperationstatus JSON-RPC command.
+// NewZGetOperationStatusCmd returns a new instance which can be used to issue
+// a z_getoperationstatus JSON-RPC command.
 //
 // The parameters which are pointers indicate they are optional.  Passing nil
 // for optional parameters will use the default value.
-func NewZGetOperationStatusCmd(minConf *int) *ZGetOperationStatusCmd {
+func NewZGetOperationStatusCmd(operationIDs *[]string) *ZGetOperationStatusCmd {
 	return &ZGetOperationStatusCmd{
-		MinConf: minConf,
+		OperationIDs: operationIDs,
 	}
 }
 
+// ZMergeToAddressCmd defines the z_mergetoaddress JSON-RPC command.
+type ZMergeToAddressCmd struct {
+	FromAddresses    []string
+	ToAddress        string
+	Fee              *ZFee
+	TransparentLimit *int `jsonrpcdefault:"50"`
+	ShieldedLimit    *int `jsonrpcdefault:"20"`
+	Memo             *ZMemo
+	PrivacyPolicy    *PrivacyPolicy
+}
+
+// mergeAnyAddresses maps the value pools z_mergetoaddress can merge in their
+// entirety to the special addresses selecting every wallet address in them.
+var mergeAnyAddresses = map[ValuePool]string{
+	PoolTransparent: "ANY_TADDR",
+	PoolSprout:      "ANY_SPROUT",
+	PoolSapling:     "ANY_SAPLING",
+}
+
+// NewZMergeToAddressCmd returns a new instance which can be used to issue a
+// z_mergetoaddress JSON-RPC command.
+//
+// The options are optional.  Passing nil for options, or for any of its
+// fields, will use the default value.
+func NewZMergeToAddressCmd(fromAddresses []string, toAddress string, options *ZMergeToAddressOptions) *ZMergeToAddressCmd {
+	cmd := &ZMergeToAddressCmd{
+		FromAddresses: fromAddresses,
+		ToAddress:     toAddress,
+	}
+	if options == nil {
+		return cmd
+	}
+
+	// Pools without a special address are passed through for the server
+	// to reject.
+	if len(options.FromPools) > 0 {
+		addrs := make([]string, 0, len(fromAddresses)+
+			len(options.FromPools))
+		addrs = append(addrs, fromAddresses...)
+		for _, pool := range options.FromPools {
+			addr, ok := mergeAnyAddresses[pool]
+			if !ok {
+				addr = string(pool)
+			}
+			addrs = append(addrs, addr)
+		}
+		cmd.FromAddresses = addrs
+	}
+
+	// Arguments are positional, so the earlier ones must be filled in
+	// with their defaults when a later one is given.
+	if options.PrivacyPolicy != nil {
+		cmd.PrivacyPolicy = options.PrivacyPolicy
+		cmd.Memo = &ZMemo{}
+	}
+	if options.Memo != nil {
+		cmd.Memo = &ZMemo{Hex: options.Memo}
+	}
+	cmd.ShieldedLimit = options.ShieldedLimit
+	if cmd.ShieldedLimit == nil && cmd.Memo != nil {
+		shieldedLimit := 20
+		cmd.ShieldedLimit = &shieldedLimit
+	}
+	cmd.TransparentLimit = options.TransparentLimit
+	if cmd.TransparentLimit == nil && cmd.ShieldedLimit != nil {
+		transparentLimit := 50
+		cmd.TransparentLimit = &transparentLimit
+	}
+	if options.Fee != nil || cmd.TransparentLimit != nil {
+		cmd.Fee = &ZFee{Amount: options.Fee}
+	}
+	return cmd
+}
+
 // ZSendManyCmd defines the z_sendmany JSON-RPC command.
 type ZSendManyCmd struct {
-	FromAccount string
-	Amounts     []ZSendManyEntry `jsonrpcusage:"{\"address\":address,\"amount\":amount,...}"`
-	MinConf     *int             `jsonrpcdefault:"1"`
+	FromAddress   string
+	Amounts       []ZSendManyEntry `jsonrpcusage:"[{\"address\":address,\"amount\":amount,\"memo\":memo},...]"`
+	MinConf       *int             `jsonrpcdefault:"1"`
+	Fee           *ZFee
+	PrivacyPolicy *PrivacyPolicy
 }
 
 // NewZSendManyCmd returns a new instance which can be used to issue a z_sendmany
 // JSON-RPC command.
 //
-// The parameters which are pointers indicate they are optional.  Passing nil
-// for optional parameters will use the default value.
-func NewZSendManyCmd(fromAccount string, amounts []ZSendManyEntry, minConf *int) *ZSendManyCmd {
-	return &ZSendManyCmd{
-		FromAccount: fromAccount,
+// The options are optional.  Passing nil for options, or for any of its
+// fields, will use the default value.
+func NewZSendManyCmd(fromAddress string, amounts []ZSendManyEntry, options *ZSendManyOptions) *ZSendManyCmd {
+	cmd := &ZSendManyCmd{
+		FromAddress: fromAddress,
 		Amounts:     amounts,
-		MinConf:     minConf,
 	}
+	if options == nil {
+		return cmd
+	}
+
+	// Arguments are positional, so the earlier ones must be filled in
+	// when a later one is given.  A fee of null selects the ZIP 317
+	// conventional fee, which is also the server default.
+	cmd.MinConf = options.MinConf
+	if options.Fee != nil || options.PrivacyPolicy != nil {
+		if cmd.MinConf == nil {
+			minConf := 1
+			cmd.MinConf = &minConf
+		}
+		cmd.Fee = &ZFee{Amount: options.Fee}
+	}
+	cmd.PrivacyPolicy = options.PrivacyPolicy
+	return cmd
+}
+
+// ZShieldCoinbaseCmd defines the z_shieldcoinbase JSON-RPC command.
+type ZShieldCoinbaseCmd struct {
+	FromAddress   string
+	ToAddress     string
+	Fee           *ZFee
+	Limit         *int `jsonrpcdefault:"50"`
+	Memo          *ZMemo
+	PrivacyPolicy *PrivacyPolicy
+}
+
+// NewZShieldCoinbaseCmd returns a new instance which can be used to issue a
+// z_shieldcoinbase JSON-RPC command.
+//
+// The options are optional.  Passing nil for options, or for any of its
+// fields, will use the default value.
+func NewZShieldCoinbaseCmd(fromAddress, toAddress string, options *ZShieldCoinbaseOptions) *ZShieldCoinbaseCmd {
+	cmd := &ZShieldCoinbaseCmd{
+		FromAddress: fromAddress,
+		ToAddress:   toAddress,
+	}
+	if options == nil {
+		return cmd
+	}
+
+	// Arguments are positional, so the earlier ones must be filled in
+	// with their defaults when a later one is given.
+	if options.PrivacyPolicy != nil {
+		cmd.PrivacyPolicy = options.PrivacyPolicy
+		cmd.Memo = &ZMemo{}
+	}
+	if options.Memo != nil {
+		cmd.Memo = &ZMemo{Hex: options.Memo}
+	}
+	cmd.Limit = options.Limit
+	if cmd.Limit == nil && cmd.Memo != nil {
+		limit := 50
+		cmd.Limit = &limit
+	}
+	if options.Fee != nil || cmd.Limit != nil {
+		cmd.Fee = &ZFee{Amount: options.Fee}
+	}
+	return cmd
 }
 
 func init() {
 	// The commands in this file are only usable with a wallet server.
 	flags := btcjson.UFWalletOnly
 
+	btcjson.MustRegisterCmd("listaddresses", (*ListAddressesCmd)(nil), flags)
 	btcjson.MustRegisterCmd("z_exportkey", (*ZExportKeyCmd)(nil), flags)
+	btcjson.MustRegisterCmd("z_exportviewingkey", (*ZExportViewingKeyCmd)(nil), flags)
 	btcjson.MustRegisterCmd("z_exportwallet", (*ZExportWalletCmd)(nil), flags)
+	btcjson.MustRegisterCmd("z_getaddressforaccount", (*ZGetAddressForAccountCmd)(nil), flags)
 	btcjson.MustRegisterCmd("z_getbalance", (*ZGetBalanceCmd)(nil), flags)
+	btcjson.MustRegisterCmd("z_getbalanceforaccount", (*ZGetBalanceForAccountCmd)(nil), flags)
+	btcjson.MustRegisterCmd("z_getbalanceforviewingkey", (*ZGetBalanceForViewingKeyCmd)(nil), flags)
 	btcjson.MustRegisterCmd("z_getoperationresult", (*ZGetOperationResultCmd)(nil), flags)
 	btcjson.MustRegisterCmd("z_getoperationstatus", (*ZGetOperationStatusCmd)(nil), flags)
+	btcjson.MustRegisterCmd("z_getnewaccount", (*ZGetNewAccountCmd)(nil), flags)
 	btcjson.MustRegisterCmd("z_getnewaddress", (*ZGetNewAddressCmd)(nil), flags)
 	btcjson.MustRegisterCmd("z_gettotalbalance", (*ZGetTotalBalanceCmd)(nil), flags)
 	btcjson.MustRegisterCmd("z_importkey", (*ZImportKeyCmd)(nil), flags)
+	btcjson.MustRegisterCmd("z_importviewingkey", (*ZImportViewingKeyCmd)(nil), flags)
 	btcjson.MustRegisterCmd("z_importwallet", (*ZImportWalletCmd)(nil), flags)
+	btcjson.MustRegisterCmd("z_listaccounts", (*ZListAccountsCmd)(nil), flags)
 	btcjson.MustRegisterCmd("z_listaddresses", (*ZListAddressesCmd)(nil), flags)
 	btcjson.MustRegisterCmd("z_listoperationids", (*ZListOperationIdsCmd)(nil), flags)
 	btcjson.MustRegisterCmd("z_listreceivedbyaddress", (*ZListReceivedByAddressCmd)(nil), flags)
+	btcjson.MustRegisterCmd("z_listunifiedreceivers", (*ZListUnifiedReceiversCmd)(nil), flags)
+	btcjson.MustRegisterCmd("z_listunspent", (*ZListUnspentCmd)(nil), flags)
+	btcjson.MustRegisterCmd("z_mergetoaddress", (*ZMergeToAddressCmd)(nil), flags)
 	btcjson.MustRegisterCmd("z_sendmany", (*ZSendManyCmd)(nil), flags)
+	btcjson.MustRegisterCmd("z_shieldcoinbase", (*ZShieldCoinbaseCmd)(nil), flags)
+	btcjson.MustRegisterCmd("z_viewtransaction", (*ZViewTransactionCmd)(nil), flags)
+
+	// The block subsidy is reported by the chain server.
+	btcjson.MustRegisterCmd("getblocksubsidy", (*GetBlockSubsidyCmd)(nil), btcjson.UsageFlag(0))
 }
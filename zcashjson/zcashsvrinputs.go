@@ -5,9 +5,290 @@
 
 package zcashjson
 
-// ZSendManyEntry models the inputs for the z_sendmany command.
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/arithmetric/zcashrpcclient/zcashutil"
+	"github.com/btcsuite/btcutil"
+)
+
+// formatAmount formats the zatoshi amount as an exact decimal ZEC value so it
+// can be sent as a JSON number without passing through a float.
+func formatAmount(amount btcutil.Amount) json.Number {
+	sign := ""
+	zat := int64(amount)
+	if zat < 0 {
+		sign = "-"
+		zat = -zat
+	}
+	return json.Number(fmt.Sprintf("%s%d.%08d", sign,
+		zat/btcutil.SatoshiPerBitcoin, zat%btcutil.SatoshiPerBitcoin))
+}
+
+// parseAmount parses a decimal ZEC value into an exact zatoshi amount.
+func parseAmount(n json.Number) (btcutil.Amount, error) {
+	s := string(n)
+
+	// Servers format some amounts, such as echoed fees, as floating point
+	// numbers which may use exponent notation.  Those are rounded to the
+	// nearest zatoshi.
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ZEC amount %q", s)
+		}
+		return btcutil.NewAmount(f)
+	}
+
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if whole == "" || len(frac) > 8 || strings.ContainsAny(s, "+-") {
+		return 0, fmt.Errorf("invalid ZEC amount %q", string(n))
+	}
+	frac += strings.Repeat("0", 8-len(frac))
+
+	var zat int64
+	for _, c := range whole + frac {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid ZEC amount %q", string(n))
+		}
+		zat = zat*10 + int64(c-'0')
+		if zat > btcutil.MaxSatoshi {
+			return 0, fmt.Errorf("ZEC amount %q is out of range",
+				string(n))
+		}
+	}
+	if neg {
+		zat = -zat
+	}
+	return btcutil.Amount(zat), nil
+}
+
+// ZSendManyEntry models the inputs for the z_sendmany command.  Amount is the
+// exact number of zatoshis to send and is encoded as a decimal ZEC value.
 type ZSendManyEntry struct {
-	Address string  `json:"address"`
-	Amount  float64 `json:"amount"`
-	Memo    *string `json:"memo"`
+	Address zcashutil.Address `json:"address"`
+	Amount  btcutil.Amount    `json:"amount"`
+	Memo    *string           `json:"memo"`
+}
+
+// zSendManyEntryJSON is the wire form of ZSendManyEntry.
+type zSendManyEntryJSON struct {
+	Address string      `json:"address"`
+	Amount  json.Number `json:"amount"`
+	Memo    *string     `json:"memo"`
+}
+
+// MarshalJSON provides a custom Marshal method for ZSendManyEntry that
+// encodes the amount as an exact decimal ZEC value.
+func (e ZSendManyEntry) MarshalJSON() ([]byte, error) {
+	if e.Address == nil {
+		return nil, fmt.Errorf("z_sendmany entry has no address")
+	}
+	return json.Marshal(zSendManyEntryJSON{
+		Address: e.Address.EncodeAddress(),
+		Amount:  formatAmount(e.Amount),
+		Memo:    e.Memo,
+	})
+}
+
+// UnmarshalJSON provides a custom Unmarshal method for ZSendManyEntry that
+// decodes the decimal ZEC amount without loss of precision.
+func (e *ZSendManyEntry) UnmarshalJSON(b []byte) error {
+	var entry zSendManyEntryJSON
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return err
+	}
+	addr, err := zcashutil.DecodeAddress(entry.Address)
+	if err != nil {
+		return err
+	}
+	amount, err := parseAmount(entry.Amount)
+	if err != nil {
+		return err
+	}
+
+	e.Address = addr
+	e.Amount = amount
+	e.Memo = entry.Memo
+	return nil
+}
+
+// PrivacyPolicy describes which information a shielded operation such as
+// z_sendmany is permitted to reveal.
+type PrivacyPolicy string
+
+// These constants define the privacy policies zcashd accepts, from the most
+// to the least private.
+const (
+	// FullPrivacy only allows fully shielded transactions.
+	FullPrivacy PrivacyPolicy = "FullPrivacy"
+
+	// AllowRevealedAmounts allows crossing between shielded pools, which
+	// reveals the amount moved.
+	AllowRevealedAmounts PrivacyPolicy = "AllowRevealedAmounts"
+
+	// AllowRevealedRecipients additionally allows transparent recipients.
+	AllowRevealedRecipients PrivacyPolicy = "AllowRevealedRecipients"
+
+	// AllowRevealedSenders additionally allows spending transparent
+	// funds.
+	AllowRevealedSenders PrivacyPolicy = "AllowRevealedSenders"
+
+	// AllowFullyTransparent additionally allows transactions with only
+	// transparent inputs and outputs.
+	AllowFullyTransparent PrivacyPolicy = "AllowFullyTransparent"
+
+	// AllowLinkingAccountAddresses additionally allows spending funds
+	// received by multiple transparent addresses of the same account in
+	// one transaction.
+	AllowLinkingAccountAddresses PrivacyPolicy = "AllowLinkingAccountAddresses"
+
+	// NoPrivacy allows any transaction.
+	NoPrivacy PrivacyPolicy = "NoPrivacy"
+)
+
+// ZFee models the optional fee argument of shielded operations such as
+// z_sendmany.  A nil Amount is sent as null, which makes zcashd pay the ZIP 317
+// conventional fee.
+type ZFee struct {
+	Amount *btcutil.Amount
+}
+
+// MarshalJSON provides a custom Marshal method for ZFee.
+func (f ZFee) MarshalJSON() ([]byte, error) {
+	if f.Amount == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(formatAmount(*f.Amount))
 }
+
+// UnmarshalJSON provides a custom Unmarshal method for ZFee.
+func (f *ZFee) UnmarshalJSON(b []byte) error {
+	var n *json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	if n == nil {
+		f.Amount = nil
+		return nil
+	}
+	amount, err := parseAmount(*n)
+	if err != nil {
+		return err
+	}
+	f.Amount = &amount
+	return nil
+}
+
+// ZSendManyOptions models the optional arguments of the z_sendmany command.
+type ZSendManyOptions struct {
+	// MinConf is the minimum number of confirmations of the notes and
+	// UTXOs to spend.  Nil uses the server default of 1.
+	MinConf *int
+
+	// Fee is the exact fee to pay.  Nil pays the ZIP 317 conventional
+	// fee.
+	Fee *btcutil.Amount
+
+	// PrivacyPolicy limits what the transaction may reveal.  Nil uses
+	// the server default, which depends on the addresses involved.
+	PrivacyPolicy *PrivacyPolicy
+}
+
+// ZMemo models the optional memo argument of shielded operations such as
+// z_shieldcoinbase.  A nil Hex is sent as null, which leaves the memo empty.
+type ZMemo struct {
+	Hex *string
+}
+
+// MarshalJSON provides a custom Marshal method for ZMemo.
+func (m ZMemo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Hex)
+}
+
+// UnmarshalJSON provides a custom Unmarshal method for ZMemo.
+func (m *ZMemo) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &m.Hex)
+}
+
+// ZShieldCoinbaseOptions models the optional arguments of the
+// z_shieldcoinbase command.
+type ZShieldCoinbaseOptions struct {
+	// Fee is the exact fee to pay.  Nil pays the ZIP 317 conventional
+	// fee.
+	Fee *btcutil.Amount
+
+	// Limit is the maximum number of coinbase UTXOs to shield.  Zero means
+	// no limit.  Nil uses the server default of 50.
+	Limit *int
+
+	// Memo is the hex encoded memo of the shielded output.  Nil leaves it
+	// empty.
+	Memo *string
+
+	// PrivacyPolicy limits what the transaction may reveal.  Nil uses
+	// the server default.
+	PrivacyPolicy *PrivacyPolicy
+}
+
+// ZMergeToAddressOptions models the optional arguments of the
+// z_mergetoaddress command.
+type ZMergeToAddressOptions struct {
+	// FromPools selects every wallet address in each of the passed pools,
+	// in addition to the addresses passed explicitly.  Only
+	// PoolTransparent, PoolSprout and PoolSapling may be selected this
+	// way.
+	FromPools []ValuePool
+
+	// Fee is the exact fee to pay.  Nil pays the ZIP 317 conventional
+	// fee.
+	Fee *btcutil.Amount
+
+	// TransparentLimit is the maximum number of UTXOs to merge.  Zero
+	// means no limit.  Nil uses the server default of 50.
+	TransparentLimit *int
+
+	// ShieldedLimit is the maximum number of notes to merge.  Zero means
+	// no limit.  Nil uses the server default of 20.
+	ShieldedLimit *int
+
+	// Memo is the hex encoded memo of the output when it is shielded.
+	// Nil leaves it empty.
+	Memo *string
+
+	// PrivacyPolicy limits what the transaction may reveal.  Nil uses
+	// the server default.
+	PrivacyPolicy *PrivacyPolicy
+}
+
+// ReceiverType identifies a kind of receiver of a unified address.
+type ReceiverType string
+
+// These constants define the receiver types z_getaddressforaccount accepts.
+const (
+	ReceiverP2PKH   ReceiverType = "p2pkh"
+	ReceiverSapling ReceiverType = "sapling"
+	ReceiverOrchard ReceiverType = "orchard"
+)
+
+// RescanMode describes whether importing a key rescans the block chain for
+// transactions involving it.
+type RescanMode string
+
+// These constants define the rescan modes accepted by key import commands.
+const (
+	RescanYes          RescanMode = "yes"
+	RescanNo           RescanMode = "no"
+	RescanWhenKeyIsNew RescanMode = "whenkeyisnew"
+)
@@ -5,21 +5,95 @@
 
 package zcashjson
 
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/btcsuite/btcutil"
+)
+
+// ValuePool identifies one of the pools Zcash value is held in.
+type ValuePool string
+
+// These constants define the value pools reported by the server.
+const (
+	PoolTransparent ValuePool = "transparent"
+	PoolSprout      ValuePool = "sprout"
+	PoolSapling     ValuePool = "sapling"
+	PoolOrchard     ValuePool = "orchard"
+
+	// PoolLockbox holds the development fund lockbox created by NU6.
+	PoolLockbox ValuePool = "lockbox"
+)
+
+// ZOperationStatus describes the state of an asynchronous operation such as
+// one started by z_sendmany.
+type ZOperationStatus string
+
+// These constants define the states an asynchronous operation moves through.
+// An operation is queued, then executing and then finishes in one of the
+// success, failed or cancelled states.
+const (
+	OperationQueued    ZOperationStatus = "queued"
+	OperationExecuting ZOperationStatus = "executing"
+	OperationSuccess   ZOperationStatus = "success"
+	OperationFailed    ZOperationStatus = "failed"
+	OperationCancelled ZOperationStatus = "cancelled"
+)
+
+// IsTerminal returns whether the status is a final state the operation will
+// not leave.
+func (s ZOperationStatus) IsTerminal() bool {
+	return s == OperationSuccess || s == OperationFailed ||
+		s == OperationCancelled
+}
+
+// IsSuccess returns whether the operation finished successfully.
+func (s ZOperationStatus) IsSuccess() bool {
+	return s == OperationSuccess
+}
+
 // ZOperationStatusError models the error data in ZGetOperationStatusResult.
 type ZOperationStatusError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 }
 
+// ZOperationResult models the result data of a successful operation in
+// ZGetOperationStatusResult.
+type ZOperationResult struct {
+	TxID string `json:"txid"`
+}
+
+// ZOperationParams models the arguments of the RPC that started an operation,
+// which are echoed back in ZGetOperationStatusResult.  Only the fields used by
+// the method that started the operation are set.
+type ZOperationParams struct {
+	FromAddress   string           `json:"fromaddress,omitempty"`
+	FromAddresses []string         `json:"fromaddresses,omitempty"`
+	ToAddress     string           `json:"toaddress,omitempty"`
+	Amounts       []ZSendManyEntry `json:"amounts,omitempty"`
+	MinConf       *int             `json:"minconf,omitempty"`
+	Fee           *ZFee            `json:"fee,omitempty"`
+	PrivacyPolicy *PrivacyPolicy   `json:"privacyPolicy,omitempty"`
+}
+
 // ZGetOperationStatusResult models the data from the z_getoperationresult and
-// z_getoperationstatus commands.
+// z_getoperationstatus commands.  Result is only set for successful operations
+// and Error only for failed ones.
 type ZGetOperationStatusResult struct {
-	Id            string                `json:"id"`
-	Status        string                `json:"status"`
-	CreationTime  int                   `json:"creation_time"`
-	Result        map[string]string     `json:"result"`
-	Error         ZOperationStatusError `json:"error"`
-	ExecutionSecs float64               `json:"execution_secs"`
+	ID            string                 `json:"id"`
+	Status        ZOperationStatus       `json:"status"`
+	CreationTime  int64                  `json:"creation_time"`
+	Method        string                 `json:"method,omitempty"`
+	Params        *ZOperationParams      `json:"params,omitempty"`
+	Result        *ZOperationResult      `json:"result,omitempty"`
+	Error         *ZOperationStatusError `json:"error,omitempty"`
+	ExecutionSecs float64                `json:"execution_secs,omitempty"`
 }
 
 // ZGetTotalBalanceResult models the data from the z_gettotalbalance command.
@@ -30,9 +104,546 @@ type ZGetTotalBalanceResult struct {
 }
 
 // ZListReceivedByAddressResult models the data from the z_listreceivedbyaddress
-// command.
+// command.  Memo holds the raw 512-byte memo field, which can be interpreted
+// with zcashutil.DecodeMemo.  Which of the index fields are set depends on the
+// pool of the note, and the block fields are only set once it is mined.
 type ZListReceivedByAddressResult struct {
-	TxID   string  `json:"txid"`
-	Amount float64 `json:"amount"`
-	Memo   string  `json:"memo"`
+	Pool          ValuePool `json:"pool,omitempty"`
+	TxID          string    `json:"txid"`
+	Amount        float64   `json:"amount"`
+	AmountZat     int64     `json:"amountZat,omitempty"`
+	Memo          []byte    `json:"-"`
+	Confirmations int64     `json:"confirmations"`
+	BlockHeight   *int32    `json:"blockheight,omitempty"`
+	BlockIndex    *int      `json:"blockindex,omitempty"`
+	BlockTime     int64     `json:"blocktime,omitempty"`
+	JSIndex       *int      `json:"jsindex,omitempty"`
+	JSOutIndex    *int      `json:"jsoutindex,omitempty"`
+	OutIndex      *int      `json:"outindex,omitempty"`
+	ActionIndex   *int      `json:"actionindex,omitempty"`
+	Change        bool      `json:"change"`
+}
+
+// UnmarshalJSON provides a custom Unmarshal method for
+// ZListReceivedByAddressResult that decodes the hex encoded memo.
+func (r *ZListReceivedByAddressResult) UnmarshalJSON(b []byte) error {
+	type result ZListReceivedByAddressResult
+	var raw struct {
+		*result
+		Memo string `json:"memo"`
+	}
+	raw.result = (*result)(r)
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	memo, err := hex.DecodeString(raw.Memo)
+	if err != nil {
+		return err
+	}
+	r.Memo = memo
+	return nil
+}
+
+// ZListUnifiedReceiversResult models the data from the z_listunifiedreceivers
+// command.  Receivers the unified address does not contain are empty.  The
+// Orchard receiver is encoded as a unified address containing only it.
+type ZListUnifiedReceiversResult struct {
+	P2PKH   string `json:"p2pkh,omitempty"`
+	P2SH    string `json:"p2sh,omitempty"`
+	Sapling string `json:"sapling,omitempty"`
+	Orchard string `json:"orchard,omitempty"`
+}
+
+// ZMergeToAddressResult models the data from the z_mergetoaddress command.
+// OperationID identifies the asynchronous operation performing the merge.
+type ZMergeToAddressResult struct {
+	RemainingUTXOs            int     `json:"remainingUTXOs"`
+	RemainingTransparentValue float64 `json:"remainingTransparentValue"`
+	RemainingNotes            int     `json:"remainingNotes"`
+	RemainingShieldedValue    float64 `json:"remainingShieldedValue"`
+	MergingUTXOs              int     `json:"mergingUTXOs"`
+	MergingTransparentValue   float64 `json:"mergingTransparentValue"`
+	MergingNotes              int     `json:"mergingNotes"`
+	MergingShieldedValue      float64 `json:"mergingShieldedValue"`
+	OperationID               string  `json:"opid"`
+}
+
+// ZShieldCoinbaseResult models the data from the z_shieldcoinbase command.
+// OperationID identifies the asynchronous operation performing the shielding.
+type ZShieldCoinbaseResult struct {
+	RemainingUTXOs int     `json:"remainingUTXOs"`
+	RemainingValue float64 `json:"remainingValue"`
+	ShieldingUTXOs int     `json:"shieldingUTXOs"`
+	ShieldingValue float64 `json:"shieldingValue"`
+	OperationID    string  `json:"opid"`
+}
+
+// ZListUnspentResult models a successful response from the z_listunspent
+// request.  Which of JSIndex, JSOutIndex, OutIndex and ActionIndex are set
+// depends on the pool of the note.  Amount is the exact value of the note in
+// zatoshis and Memo holds the raw 512-byte memo field.
+type ZListUnspentResult struct {
+	TxID          string         `json:"txid"`
+	Pool          ValuePool      `json:"pool"`
+	JSIndex       *int           `json:"jsindex,omitempty"`
+	JSOutIndex    *int           `json:"jsoutindex,omitempty"`
+	OutIndex      *int           `json:"outindex,omitempty"`
+	ActionIndex   *int           `json:"actionindex,omitempty"`
+	Confirmations int64          `json:"confirmations"`
+	Spendable     bool           `json:"spendable"`
+	Account       *int           `json:"account,omitempty"`
+	Address       string         `json:"address,omitempty"`
+	Amount        btcutil.Amount `json:"-"`
+	Memo          []byte         `json:"-"`
+	MemoStr       string         `json:"memoStr,omitempty"`
+	Change        bool           `json:"change"`
+}
+
+// UnmarshalJSON provides a custom Unmarshal method for ZListUnspentResult
+// that decodes the amount without loss of precision and the hex encoded memo.
+func (r *ZListUnspentResult) UnmarshalJSON(b []byte) error {
+	type result ZListUnspentResult
+	var raw struct {
+		*result
+		Amount json.Number `json:"amount"`
+		Memo   string      `json:"memo"`
+	}
+	raw.result = (*result)(r)
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	amount, err := parseAmount(raw.Amount)
+	if err != nil {
+		return err
+	}
+	memo, err := hex.DecodeString(raw.Memo)
+	if err != nil {
+		return err
+	}
+
+	r.Amount = amount
+	r.Memo = memo
+	return nil
+}
+
+// ZViewTransactionSpend models a shielded spend of a wallet note in the
+// z_viewtransaction result.  Which of the index fields are set depends on the
+// pool of the spent note.
+type ZViewTransactionSpend struct {
+	Pool         ValuePool      `json:"pool"`
+	JSIndex      *int           `json:"js,omitempty"`
+	JSSpend      *int           `json:"jsSpend,omitempty"`
+	Spend        *int           `json:"spend,omitempty"`
+	Action       *int           `json:"action,omitempty"`
+	TxIDPrev     string         `json:"txidPrev"`
+	JSPrev       *int           `json:"jsPrev,omitempty"`
+	JSOutputPrev *int           `json:"jsOutputPrev,omitempty"`
+	OutputPrev   *int           `json:"outputPrev,omitempty"`
+	ActionPrev   *int           `json:"actionPrev,omitempty"`
+	Address      string         `json:"address,omitempty"`
+	Value        btcutil.Amount `json:"-"`
+}
+
+// UnmarshalJSON provides a custom Unmarshal method for ZViewTransactionSpend.
+func (s *ZViewTransactionSpend) UnmarshalJSON(b []byte) error {
+	type spend ZViewTransactionSpend
+	var raw struct {
+		*spend
+		zViewTransactionEntryJSON
+	}
+	raw.spend = (*spend)(s)
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	value, err := raw.value()
+	if err != nil {
+		return err
+	}
+	s.Pool = raw.pool(s.Pool)
+	s.Value = value
+	return nil
+}
+
+// ZViewTransactionOutput models a shielded output the wallet can decrypt in
+// the z_viewtransaction result.  Outgoing is set for outputs the wallet sent
+// to addresses it does not own.  Memo holds the raw 512-byte memo field, which
+// can be interpreted with zcashutil.DecodeMemo.
+type ZViewTransactionOutput struct {
+	Pool           ValuePool      `json:"pool"`
+	JSIndex        *int           `json:"js,omitempty"`
+	JSOutput       *int           `json:"jsOutput,omitempty"`
+	Output         *int           `json:"output,omitempty"`
+	Action         *int           `json:"action,omitempty"`
+	Address        string         `json:"address,omitempty"`
+	Outgoing       bool           `json:"outgoing"`
+	WalletInternal bool           `json:"walletInternal"`
+	Value          btcutil.Amount `json:"-"`
+	Memo           []byte         `json:"-"`
+	MemoStr        string         `json:"memoStr,omitempty"`
+}
+
+// UnmarshalJSON provides a custom Unmarshal method for ZViewTransactionOutput.
+func (o *ZViewTransactionOutput) UnmarshalJSON(b []byte) error {
+	type output ZViewTransactionOutput
+	var raw struct {
+		*output
+		zViewTransactionEntryJSON
+		Memo string `json:"memo"`
+	}
+	raw.output = (*output)(o)
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	value, err := raw.value()
+	if err != nil {
+		return err
+	}
+	memo, err := hex.DecodeString(raw.Memo)
+	if err != nil {
+		return err
+	}
+	o.Pool = raw.pool(o.Pool)
+	o.Value = value
+	o.Memo = memo
+	return nil
+}
+
+// zViewTransactionEntryJSON holds the fields common to the spends and outputs
+// of the z_viewtransaction result that need converting.
+type zViewTransactionEntryJSON struct {
+	Type     ValuePool   `json:"type"`
+	Value    json.Number `json:"value"`
+	ValueZat *int64      `json:"valueZat"`
+}
+
+// pool returns the pool of the entry.  Older servers report it as type.
+func (e *zViewTransactionEntryJSON) pool(pool ValuePool) ValuePool {
+	if pool == "" {
+		return e.Type
+	}
+	return pool
+}
+
+// value returns the exact value of the entry, preferring the zatoshi value
+// when the server reports it.
+func (e *zViewTransactionEntryJSON) value() (btcutil.Amount, error) {
+	if e.ValueZat != nil {
+		return btcutil.Amount(*e.ValueZat), nil
+	}
+	return parseAmount(e.Value)
+}
+
+// ZViewTransactionResult models the data from the z_viewtransaction command.
+type ZViewTransactionResult struct {
+	TxID    string                   `json:"txid"`
+	Spends  []ZViewTransactionSpend  `json:"spends"`
+	Outputs []ZViewTransactionOutput `json:"outputs"`
+}
+
+// ZGetNewAccountResult models the data from the z_getnewaccount command.
+type ZGetNewAccountResult struct {
+	Account uint32 `json:"account"`
+}
+
+// ZGetAddressForAccountResult models the data from the z_getaddressforaccount
+// command.
+type ZGetAddressForAccountResult struct {
+	Account          uint32         `json:"account"`
+	DiversifierIndex uint64         `json:"diversifier_index"`
+	ReceiverTypes    []ReceiverType `json:"receiver_types"`
+	Address          string         `json:"address"`
+}
+
+// ZListAccountsAddress models an address derived for an account in the
+// z_listaccounts result.
+type ZListAccountsAddress struct {
+	DiversifierIndex uint64 `json:"diversifier_index"`
+	UnifiedAddress   string `json:"ua"`
+}
+
+// ZListAccountsResult models the data from the z_listaccounts command.
+type ZListAccountsResult struct {
+	Account   uint32                 `json:"account"`
+	UFVK      string                 `json:"ufvk,omitempty"`
+	Addresses []ZListAccountsAddress `json:"addresses"`
+}
+
+// ZPoolBalance models the balance of a single value pool.
+type ZPoolBalance struct {
+	ValueZat btcutil.Amount `json:"valueZat"`
+}
+
+// ZGetBalanceForAccountResult models the data from the z_getbalanceforaccount
+// command.  Pools the account holds no funds in are omitted.
+type ZGetBalanceForAccountResult struct {
+	Pools                map[ValuePool]ZPoolBalance `json:"pools"`
+	MinimumConfirmations int                        `json:"minimum_confirmations"`
+}
+
+// AddressSource describes where the keys of the addresses in a listaddresses
+// group come from.
+type AddressSource string
+
+// These constants define the address sources reported by listaddresses.
+const (
+	SourceImported          AddressSource = "imported"
+	SourceImportedWatchOnly AddressSource = "imported_watchonly"
+	SourceKeyPool           AddressSource = "keypool"
+	SourceLegacyRandom      AddressSource = "legacy_random"
+	SourceLegacyHDSeed      AddressSource = "legacy_hdseed"
+	SourceMnemonicSeed      AddressSource = "mnemonic_seed"
+)
+
+// ListAddressesTransparent models the transparent addresses of a
+// listaddresses group.
+type ListAddressesTransparent struct {
+	Addresses       []string `json:"addresses"`
+	ChangeAddresses []string `json:"changeAddresses,omitempty"`
+}
+
+// ListAddressesSprout models the Sprout addresses of a listaddresses group.
+type ListAddressesSprout struct {
+	Addresses []string `json:"addresses"`
+}
+
+// ListAddressesSapling models Sapling addresses derived from the same key in
+// a listaddresses group.  ZIP32KeyPath is empty for keys not derived from a
+// seed.
+type ListAddressesSapling struct {
+	ZIP32KeyPath string   `json:"zip32KeyPath,omitempty"`
+	Addresses    []string `json:"addresses"`
+}
+
+// ListAddressesUnifiedAddress models a unified address of an account in a
+// listaddresses group.
+type ListAddressesUnifiedAddress struct {
+	DiversifierIndex uint64         `json:"diversifier_index"`
+	ReceiverTypes    []ReceiverType `json:"receiver_types"`
+	Address          string         `json:"address"`
+}
+
+// ListAddressesUnified models the unified addresses of an account in a
+// listaddresses group.
+type ListAddressesUnified struct {
+	Account   uint32                        `json:"account"`
+	SeedFP    string                        `json:"seedfp"`
+	Addresses []ListAddressesUnifiedAddress `json:"addresses"`
+}
+
+// ListAddressesResult models a group of addresses with the same source from
+// the listaddresses command.  Address kinds the group has none of are nil.
+type ListAddressesResult struct {
+	Source      AddressSource             `json:"source"`
+	Transparent *ListAddressesTransparent `json:"transparent,omitempty"`
+	Sprout      *ListAddressesSprout      `json:"sprout,omitempty"`
+	Sapling     []ListAddressesSapling    `json:"sapling,omitempty"`
+	Unified     []ListAddressesUnified    `json:"unified,omitempty"`
+}
+
+// ZGetBalanceForViewingKeyResult models the data from the
+// z_getbalanceforviewingkey command.  Pools the viewing key has received no
+// funds in are omitted.
+type ZGetBalanceForViewingKeyResult struct {
+	Pools                map[ValuePool]ZPoolBalance `json:"pools"`
+	MinimumConfirmations int                        `json:"minimum_confirmations"`
+}
+
+// ZImportKeyResult models the data from the z_importkey and
+// z_importviewingkey commands.
+type ZImportKeyResult struct {
+	AddressType string `json:"address_type"`
+	Address     string `json:"address"`
+}
+
+// UpgradeStatus describes whether a network upgrade is in effect.
+type UpgradeStatus string
+
+// These constants define the states of a network upgrade.
+const (
+	UpgradeDisabled UpgradeStatus = "disabled"
+	UpgradePending  UpgradeStatus = "pending"
+	UpgradeActive   UpgradeStatus = "active"
+)
+
+// NetworkUpgradeResult models a network upgrade in the getblockchaininfo
+// result.
+type NetworkUpgradeResult struct {
+	Name             string        `json:"name"`
+	ActivationHeight int32         `json:"activationheight"`
+	Status           UpgradeStatus `json:"status"`
+	Info             string        `json:"info"`
+}
+
+// ConsensusResult models the consensus branch IDs in the getblockchaininfo
+// result.  ChainTip is the branch of the best block and NextBlock the branch
+// the next block will be validated under.
+type ConsensusResult struct {
+	ChainTip  zcashcfg.BranchID `json:"chaintip"`
+	NextBlock zcashcfg.BranchID `json:"nextblock"`
+}
+
+// ValuePoolResult models the total value held by a value pool.  ChainValue is
+// nil when the node does not monitor the pool, which is the case for the
+// Sprout pool on nodes that did not validate the whole chain.
+type ValuePoolResult struct {
+	ID         ValuePool       `json:"id"`
+	Monitored  bool            `json:"monitored"`
+	ChainValue *btcutil.Amount `json:"chainValueZat,omitempty"`
+	ValueDelta *btcutil.Amount `json:"valueDeltaZat,omitempty"`
+}
+
+// CommitmentTreeResult models the size of a note commitment tree.
+type CommitmentTreeResult struct {
+	Size uint64 `json:"size"`
+}
+
+// CommitmentTreesResult models the sizes of the Sapling and Orchard note
+// commitment trees.  Trees that do not exist yet are nil.
+type CommitmentTreesResult struct {
+	Sapling *CommitmentTreeResult `json:"sapling,omitempty"`
+	Orchard *CommitmentTreeResult `json:"orchard,omitempty"`
+}
+
+// GetBlockchainInfoResult models the data from the getblockchaininfo command
+// of zcashd.  Commitments is the number of Sprout note commitments.
+type GetBlockchainInfoResult struct {
+	Chain                        string                                     `json:"chain"`
+	Blocks                       int32                                      `json:"blocks"`
+	InitialBlockDownloadComplete bool                                       `json:"initial_block_download_complete"`
+	Headers                      int32                                      `json:"headers"`
+	BestBlockHash                string                                     `json:"bestblockhash"`
+	Difficulty                   float64                                    `json:"difficulty"`
+	VerificationProgress         float64                                    `json:"verificationprogress"`
+	EstimatedHeight              int32                                      `json:"estimatedheight"`
+	ChainWork                    string                                     `json:"chainwork"`
+	Pruned                       bool                                       `json:"pruned"`
+	PruneHeight                  int32                                      `json:"pruneheight,omitempty"`
+	SizeOnDisk                   int64                                      `json:"size_on_disk"`
+	Commitments                  uint64                                     `json:"commitments"`
+	Trees                        *CommitmentTreesResult                     `json:"trees,omitempty"`
+	ChainSupply                  *ValuePoolResult                           `json:"chainSupply,omitempty"`
+	ValuePools                   []ValuePoolResult                          `json:"valuePools"`
+	Upgrades                     map[zcashcfg.BranchID]NetworkUpgradeResult `json:"upgrades"`
+	Consensus                    ConsensusResult                            `json:"consensus"`
+}
+
+// ValuePool returns the value of the passed pool, or nil when the result does
+// not include it.
+func (r *GetBlockchainInfoResult) ValuePool(pool ValuePool) *ValuePoolResult {
+	for i := range r.ValuePools {
+		if r.ValuePools[i].ID == pool {
+			return &r.ValuePools[i]
+		}
+	}
+	return nil
+}
+
+// ActivationHeight returns the height the network upgrade with the passed
+// branch ID activates at, and whether the upgrade is scheduled on the network
+// at all.
+func (r *GetBlockchainInfoResult) ActivationHeight(branch zcashcfg.BranchID) (int32, bool) {
+	upgrade, ok := r.Upgrades[branch]
+	if !ok {
+		return 0, false
+	}
+	return upgrade.ActivationHeight, true
+}
+
+// DefaultRootsResult models the roots the server computed for a block template,
+// which are valid as long as the transactions of the template are included
+// unmodified.  The hashes are encoded in the byte-reversed order used for
+// hashes by the RPC interface.
+type DefaultRootsResult struct {
+	MerkleRoot           string `json:"merkleroot"`
+	ChainHistoryRoot     string `json:"chainhistoryroot"`
+	AuthDataRoot         string `json:"authdataroot"`
+	BlockCommitmentsHash string `json:"blockcommitmentshash"`
+}
+
+// GetBlockTemplateResultTx models a transaction of a block template.  The fee
+// of the coinbase transaction is the negated sum of the fees of the other
+// transactions.  FoundersReward is the founders' reward paid by the coinbase
+// transaction, which is only set before Canopy; afterwards the coinbase
+// transaction pays the funding streams instead.
+type GetBlockTemplateResultTx struct {
+	Data           string         `json:"data"`
+	Hash           string         `json:"hash"`
+	AuthDigest     string         `json:"authdigest"`
+	Depends        []int64        `json:"depends"`
+	Fee            btcutil.Amount `json:"fee"`
+	SigOps         int64          `json:"sigops"`
+	FoundersReward btcutil.Amount `json:"foundersreward,omitempty"`
+	Required       bool           `json:"required,omitempty"`
+}
+
+// GetBlockTemplateResult models the data from the getblocktemplate command of
+// zcashd.  The coinbase transaction is always created by the server.
+// BlockCommitmentsHash, LightClientRootHash and FinalSaplingRootHash are
+// deprecated aliases of DefaultRoots.BlockCommitmentsHash.
+type GetBlockTemplateResult struct {
+	Capabilities         []string                   `json:"capabilities"`
+	Version              int32                      `json:"version"`
+	PreviousHash         string                     `json:"previousblockhash"`
+	BlockCommitmentsHash string                     `json:"blockcommitmentshash"`
+	LightClientRootHash  string                     `json:"lightclientroothash"`
+	FinalSaplingRootHash string                     `json:"finalsaplingroothash"`
+	DefaultRoots         DefaultRootsResult         `json:"defaultroots"`
+	Transactions         []GetBlockTemplateResultTx `json:"transactions"`
+	CoinbaseTxn          GetBlockTemplateResultTx   `json:"coinbasetxn"`
+	LongPollID           string                     `json:"longpollid,omitempty"`
+	Target               string                     `json:"target"`
+	MinTime              int64                      `json:"mintime"`
+	Mutable              []string                   `json:"mutable"`
+	NonceRange           string                     `json:"noncerange"`
+	SigOpLimit           int64                      `json:"sigoplimit"`
+	SizeLimit            int64                      `json:"sizelimit"`
+	CurTime              int64                      `json:"curtime"`
+	Bits                 string                     `json:"bits"`
+	Height               int64                      `json:"height"`
+}
+
+// CompactBits returns the difficulty bits of the template in the compact
+// form used by the block header.
+func (r *GetBlockTemplateResult) CompactBits() (uint32, error) {
+	bits, err := strconv.ParseUint(r.Bits, 16, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(bits), nil
+}
+
+// TargetValue returns the target the hash of a block built from the template
+// must not exceed.
+func (r *GetBlockTemplateResult) TargetValue() (*big.Int, error) {
+	target, ok := new(big.Int).SetString(r.Target, 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid target %q", r.Target)
+	}
+	return target, nil
+}
+
+// FundingStreamResult models a funding stream paid by a block as reported by
+// getblocksubsidy.  Address is not set for lockbox streams.
+type FundingStreamResult struct {
+	Recipient     string         `json:"recipient"`
+	Specification string         `json:"specification"`
+	Value         float64        `json:"value"`
+	ValueZat      btcutil.Amount `json:"valueZat"`
+	Address       string         `json:"address,omitempty"`
+}
+
+// GetBlockSubsidyResult models the data from the getblocksubsidy command of
+// zcashd.  The amounts other than those of the streams are in ZEC.
+type GetBlockSubsidyResult struct {
+	Miner               float64               `json:"miner"`
+	Founders            float64               `json:"founders"`
+	FundingStreamsTotal float64               `json:"fundingstreamstotal"`
+	LockboxTotal        float64               `json:"lockboxtotal"`
+	TotalBlockSubsidy   float64               `json:"totalblocksubsidy"`
+	FundingStreams      []FundingStreamResult `json:"fundingstreams"`
+	LockboxStreams      []FundingStreamResult `json:"lockboxstreams"`
 }
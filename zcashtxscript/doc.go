@@ -0,0 +1,42 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package zcashtxscript computes the signature hashes of transparent Zcash
+transaction inputs so transactions can be signed outside of the node.
+
+Zcash commits to different data than Bitcoin when signing transparent inputs.
+Version 4 Sapling transactions use the ZIP 243 signature hash, which is a
+BLAKE2b digest personalized with the consensus branch ID of the network
+upgrade the transaction is mined under.  Version 5 NU5 transactions use the
+ZIP 244 digest tree, which additionally commits to the amounts and scripts of
+every output spent by the transaction.  The ZIP 244 digests shared with the
+transaction identifier are computed by the zcashwire package, so the signature
+hash and the TxHash of a v5 transaction always agree.
+
+Signature Hashes
+
+The digests that do not depend on the input being signed are computed once by
+NewTxSigHashes, which needs the outputs spent by every input of a v5
+transaction:
+
+	sigHashes, err := zcashtxscript.NewTxSigHashes(tx,
+		zcashcfg.BranchNU5, prevOuts)
+	if err != nil {
+		return err
+	}
+	hash, err := zcashtxscript.CalcSignatureHash(prevOuts[0].PkScript,
+		sigHashes, txscript.SigHashAll, tx, 0, prevOuts[0].Value)
+
+The signature hash types are those of the txscript package.  Only the defined
+types (SigHashAll, SigHashNone and SigHashSingle, optionally combined with
+SigHashAnyOneCanPay) are accepted.
+
+Payment Scripts
+
+PayToAddrScript creates the pay-to-pubkey-hash and pay-to-script-hash output
+scripts of transparent zcashutil addresses, in place of the txscript function
+of the same name which only understands Bitcoin addresses.
+*/
+package zcashtxscript
@@ -0,0 +1,361 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashtxscript
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/arithmetric/zcashrpcclient/internal/blake2b"
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+var (
+	// ErrUnsupportedVersion describes an error where the signature hash of
+	// a transaction version other than v4 or v5 is requested.
+	ErrUnsupportedVersion = errors.New("signature hashes are only " +
+		"supported for v4 and v5 transactions")
+
+	// ErrInvalidHashType describes an error where a signature hash type is
+	// not one of the types defined by the consensus rules.
+	ErrInvalidHashType = errors.New("invalid signature hash type")
+
+	// ErrBranchIDMismatch describes an error where the consensus branch ID
+	// passed for a v5 transaction differs from the one it commits to.
+	ErrBranchIDMismatch = errors.New("consensus branch ID does not match " +
+		"the transaction")
+)
+
+// sigHashMask defines the bits of the hash type which identify the base
+// signature hash type, excluding the SigHashAnyOneCanPay flag.
+const sigHashMask = 0x1f
+
+// BLAKE2b personalizations of the ZIP 243 signature hash.
+var (
+	zip243SigHashPersonalPrefix = []byte("ZcashSigHash")
+	zip243PrevoutsPersonal      = []byte("ZcashPrevoutHash")
+	zip243SequencePersonal      = []byte("ZcashSequencHash")
+	zip243OutputsPersonal       = []byte("ZcashOutputsHash")
+	zip243JoinSplitsPersonal    = []byte("ZcashJSplitsHash")
+	zip243SSpendsPersonal       = []byte("ZcashSSpendsHash")
+	zip243SOutputPersonal       = []byte("ZcashSOutputHash")
+)
+
+// BLAKE2b personalizations of the ZIP 244 transparent signature digest.
+var (
+	zip244TransparentPersonal = []byte("ZTxIdTranspaHash")
+	zip244AmountsPersonal     = []byte("ZTxTrAmountsHash")
+	zip244ScriptsPersonal     = []byte("ZTxTrScriptsHash")
+	zip244TxInPersonal        = []byte("Zcash___TxInHash")
+)
+
+// TxSigHashes houses the digests of a transaction that are shared by the
+// signature hashes of all of its inputs, so they are only computed once when
+// signing several inputs.
+type TxSigHashes struct {
+	branchID zcashcfg.BranchID
+
+	// ZIP 243 digests of v4 transactions.
+	hashPrevouts        [32]byte
+	hashSequence        [32]byte
+	hashOutputs         [32]byte
+	hashJoinSplits      [32]byte
+	hashShieldedSpends  [32]byte
+	hashShieldedOutputs [32]byte
+
+	// ZIP 244 digests of v5 transactions.
+	txid    *zcashwire.TxIDDigests
+	amounts [32]byte
+	scripts [32]byte
+}
+
+// NewTxSigHashes computes the digests of the transaction shared by the
+// signature hashes of its inputs under the passed consensus branch ID, which
+// must be the branch ID of the network upgrade active at the height the
+// transaction will be mined at.
+//
+// The outputs spent by the transaction are committed to by v5 transactions,
+// so for those prevOuts must hold the spent output of every input in order.
+// They are not used for v4 transactions and may be nil.
+func NewTxSigHashes(tx *zcashwire.MsgTx, branchID zcashcfg.BranchID,
+	prevOuts []*wire.TxOut) (*TxSigHashes, error) {
+
+	h := &TxSigHashes{branchID: branchID}
+	switch {
+	case isSaplingV4(tx):
+		h.computeV4(tx)
+
+	case isNU5(tx):
+		if uint32(branchID) != tx.ConsensusBranchID {
+			return nil, ErrBranchIDMismatch
+		}
+		if len(prevOuts) != len(tx.TxIn) {
+			return nil, fmt.Errorf("%d spent outputs given for %d "+
+				"inputs", len(prevOuts), len(tx.TxIn))
+		}
+		h.computeV5(tx, prevOuts)
+
+	default:
+		return nil, ErrUnsupportedVersion
+	}
+	return h, nil
+}
+
+// computeV4 computes the ZIP 243 digests of a v4 transaction.  Digests of
+// empty components are left zero.
+func (h *TxSigHashes) computeV4(tx *zcashwire.MsgTx) {
+	var prevouts, sequence, outputs bytes.Buffer
+	for _, ti := range tx.TxIn {
+		writeOutPoint(&prevouts, &ti.PreviousOutPoint)
+		writeUint32(&sequence, ti.Sequence)
+	}
+	for _, to := range tx.TxOut {
+		zcashwire.WriteTxOut(&outputs, to)
+	}
+	h.hashPrevouts = blake2b.Sum256(zip243PrevoutsPersonal,
+		prevouts.Bytes())
+	h.hashSequence = blake2b.Sum256(zip243SequencePersonal,
+		sequence.Bytes())
+	h.hashOutputs = blake2b.Sum256(zip243OutputsPersonal, outputs.Bytes())
+
+	if len(tx.JoinSplits) != 0 {
+		var buf bytes.Buffer
+		for _, js := range tx.JoinSplits {
+			writeUint64(&buf, js.VPubOld)
+			writeUint64(&buf, js.VPubNew)
+			writeFields(&buf, js.Anchor[:], js.Nullifiers[0][:],
+				js.Nullifiers[1][:], js.Commitments[0][:],
+				js.Commitments[1][:], js.EphemeralKey[:],
+				js.RandomSeed[:], js.Macs[0][:], js.Macs[1][:],
+				js.Proof, js.Ciphertexts[0][:],
+				js.Ciphertexts[1][:])
+		}
+		buf.Write(tx.JoinSplitPubKey[:])
+		h.hashJoinSplits = blake2b.Sum256(zip243JoinSplitsPersonal,
+			buf.Bytes())
+	}
+
+	if len(tx.SaplingSpends) != 0 {
+		var buf bytes.Buffer
+		for _, sp := range tx.SaplingSpends {
+			writeFields(&buf, sp.CV[:], sp.Anchor[:],
+				sp.Nullifier[:], sp.RK[:], sp.ZKProof[:])
+		}
+		h.hashShieldedSpends = blake2b.Sum256(zip243SSpendsPersonal,
+			buf.Bytes())
+	}
+
+	if len(tx.SaplingOutputs) != 0 {
+		var buf bytes.Buffer
+		for _, out := range tx.SaplingOutputs {
+			writeFields(&buf, out.CV[:], out.CMU[:],
+				out.EphemeralKey[:], out.EncCiphertext[:],
+				out.OutCiphertext[:], out.ZKProof[:])
+		}
+		h.hashShieldedOutputs = blake2b.Sum256(zip243SOutputPersonal,
+			buf.Bytes())
+	}
+}
+
+// computeV5 computes the ZIP 244 digests of a v5 transaction.
+func (h *TxSigHashes) computeV5(tx *zcashwire.MsgTx, prevOuts []*wire.TxOut) {
+	h.txid = tx.TxIDDigests()
+
+	var amounts, scripts bytes.Buffer
+	for _, prevOut := range prevOuts {
+		writeUint64(&amounts, uint64(prevOut.Value))
+		wire.WriteVarBytes(&scripts, 0, prevOut.PkScript)
+	}
+	h.amounts = blake2b.Sum256(zip244AmountsPersonal, amounts.Bytes())
+	h.scripts = blake2b.Sum256(zip244ScriptsPersonal, scripts.Bytes())
+}
+
+// CalcSignatureHash computes the signature hash of the transparent input idx
+// of the transaction for the passed hash type.  The script is the script the
+// input is signed against, which is the script of the spent output, or the
+// redeem script when spending a pay-to-script-hash output, and amt is the
+// value of the spent output.
+//
+// The shared digests of the transaction must have been computed with
+// NewTxSigHashes.  The signature hash of v4 transactions is computed as
+// described by ZIP 243 and that of v5 transactions as described by ZIP 244.
+func CalcSignatureHash(script []byte, sigHashes *TxSigHashes,
+	hashType txscript.SigHashType, tx *zcashwire.MsgTx, idx int,
+	amt int64) ([]byte, error) {
+
+	if idx < 0 || idx >= len(tx.TxIn) {
+		return nil, fmt.Errorf("input index %d is out of range for %d "+
+			"inputs", idx, len(tx.TxIn))
+	}
+	if !isDefinedHashType(hashType) {
+		return nil, ErrInvalidHashType
+	}
+
+	switch {
+	case isSaplingV4(tx):
+		return calcSignatureHashV4(script, sigHashes, hashType, tx, idx,
+			amt), nil
+	case isNU5(tx):
+		return calcSignatureHashV5(script, sigHashes, hashType, tx, idx,
+			amt), nil
+	}
+	return nil, ErrUnsupportedVersion
+}
+
+// calcSignatureHashV4 computes the ZIP 243 signature hash of a transparent
+// input of a v4 transaction.
+func calcSignatureHashV4(script []byte, h *TxSigHashes,
+	hashType txscript.SigHashType, tx *zcashwire.MsgTx, idx int,
+	amt int64) []byte {
+
+	anyoneCanPay := hashType&txscript.SigHashAnyOneCanPay != 0
+	baseType := hashType & sigHashMask
+
+	var hashPrevouts, hashSequence, hashOutputs [32]byte
+	if !anyoneCanPay {
+		hashPrevouts = h.hashPrevouts
+	}
+	if !anyoneCanPay && baseType != txscript.SigHashSingle &&
+		baseType != txscript.SigHashNone {
+
+		hashSequence = h.hashSequence
+	}
+	switch {
+	case baseType != txscript.SigHashSingle &&
+		baseType != txscript.SigHashNone:
+		hashOutputs = h.hashOutputs
+	case baseType == txscript.SigHashSingle && idx < len(tx.TxOut):
+		var buf bytes.Buffer
+		zcashwire.WriteTxOut(&buf, tx.TxOut[idx])
+		hashOutputs = blake2b.Sum256(zip243OutputsPersonal, buf.Bytes())
+	}
+
+	var buf bytes.Buffer
+	writeUint32(&buf, tx.Header())
+	writeUint32(&buf, tx.VersionGroupID)
+	writeFields(&buf, hashPrevouts[:], hashSequence[:], hashOutputs[:],
+		h.hashJoinSplits[:], h.hashShieldedSpends[:],
+		h.hashShieldedOutputs[:])
+	writeUint32(&buf, tx.LockTime)
+	writeUint32(&buf, tx.ExpiryHeight)
+	writeUint64(&buf, uint64(tx.ValueBalanceSapling))
+	writeUint32(&buf, uint32(hashType))
+
+	ti := tx.TxIn[idx]
+	writeOutPoint(&buf, &ti.PreviousOutPoint)
+	wire.WriteVarBytes(&buf, 0, script)
+	writeUint64(&buf, uint64(amt))
+	writeUint32(&buf, ti.Sequence)
+
+	personal := make([]byte, blake2b.PersonalSize)
+	copy(personal, zip243SigHashPersonalPrefix)
+	binary.LittleEndian.PutUint32(personal[12:], uint32(h.branchID))
+	sum := blake2b.Sum256(personal, buf.Bytes())
+	return sum[:]
+}
+
+// calcSignatureHashV5 computes the ZIP 244 signature hash of a transparent
+// input of a v5 transaction.  It is the root of the transaction identifier
+// digest tree with the transparent digest replaced by the transparent
+// signature digest.
+func calcSignatureHashV5(script []byte, h *TxSigHashes,
+	hashType txscript.SigHashType, tx *zcashwire.MsgTx, idx int,
+	amt int64) []byte {
+
+	anyoneCanPay := hashType&txscript.SigHashAnyOneCanPay != 0
+	baseType := hashType & sigHashMask
+
+	var prevouts, amounts, scripts, sequence, outputs [32]byte
+	if anyoneCanPay {
+		prevouts = zcashwire.PrevoutsDigest(nil)
+		amounts = blake2b.Sum256(zip244AmountsPersonal, nil)
+		scripts = blake2b.Sum256(zip244ScriptsPersonal, nil)
+		sequence = zcashwire.SequenceDigest(nil)
+	} else {
+		prevouts = h.txid.Prevouts
+		amounts = h.amounts
+		scripts = h.scripts
+		sequence = h.txid.Sequence
+	}
+	switch {
+	case baseType != txscript.SigHashSingle &&
+		baseType != txscript.SigHashNone:
+		outputs = h.txid.Outputs
+	case baseType == txscript.SigHashSingle && idx < len(tx.TxOut):
+		outputs = zcashwire.OutputsDigest(tx.TxOut[idx : idx+1])
+	default:
+		outputs = zcashwire.OutputsDigest(nil)
+	}
+
+	var txIn bytes.Buffer
+	ti := tx.TxIn[idx]
+	writeOutPoint(&txIn, &ti.PreviousOutPoint)
+	writeUint64(&txIn, uint64(amt))
+	wire.WriteVarBytes(&txIn, 0, script)
+	writeUint32(&txIn, ti.Sequence)
+	txInDigest := blake2b.Sum256(zip244TxInPersonal, txIn.Bytes())
+
+	var buf bytes.Buffer
+	buf.WriteByte(byte(hashType))
+	writeFields(&buf, prevouts[:], amounts[:], scripts[:], sequence[:],
+		outputs[:], txInDigest[:])
+
+	d := *h.txid
+	d.Transparent = blake2b.Sum256(zip244TransparentPersonal, buf.Bytes())
+	sum := d.Hash(uint32(h.branchID))
+	return sum[:]
+}
+
+// isDefinedHashType returns whether the hash type is one of the signature
+// hash types defined by the consensus rules.
+func isDefinedHashType(hashType txscript.SigHashType) bool {
+	switch hashType &^ txscript.SigHashAnyOneCanPay {
+	case txscript.SigHashAll, txscript.SigHashNone, txscript.SigHashSingle:
+		return true
+	}
+	return false
+}
+
+// isSaplingV4 returns whether the transaction uses the v4 Sapling format.
+func isSaplingV4(tx *zcashwire.MsgTx) bool {
+	return tx.Overwintered && tx.Version == zcashwire.SaplingTxVersion
+}
+
+// isNU5 returns whether the transaction uses the v5 ZIP 225 format.
+func isNU5(tx *zcashwire.MsgTx) bool {
+	return tx.Overwintered && tx.Version == zcashwire.NU5TxVersion
+}
+
+// writeUint32 writes the little endian encoding of v to buf.
+func writeUint32(buf *bytes.Buffer, v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	buf.Write(b[:])
+}
+
+// writeUint64 writes the little endian encoding of v to buf.
+func writeUint64(buf *bytes.Buffer, v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	buf.Write(b[:])
+}
+
+// writeOutPoint writes the encoding of op to buf.
+func writeOutPoint(buf *bytes.Buffer, op *wire.OutPoint) {
+	buf.Write(op.Hash[:])
+	writeUint32(buf, op.Index)
+}
+
+// writeFields writes each of the passed fields to buf in order.
+func writeFields(buf *bytes.Buffer, fields ...[]byte) {
+	for _, field := range fields {
+		buf.Write(field)
+	}
+}
@@ -0,0 +1,421 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashtxscript
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// The test transactions are random in the manner of the ZIP test vectors, so
+// every component committed to by the digests is present.  The expected
+// digests were computed from the ZIP 243 and ZIP 244 specifications
+// independently of this package.
+
+// sigHashTestV4Tx is a v4 transaction with two transparent inputs, one
+// transparent output, a JoinSplit and a Sapling spend and output.
+var sigHashTestV4Tx = "0400008085202f8902906e8c867d1e9b3f92cb3b2dbd5720ba4806e8a997e2a6" +
+	"70eab5987fde0b4d88b0eacc8d012cac817e183a2d096a2642e037a14e80407d" +
+	"1d4bb4224d7df77118d89c839feae0cbad77f7372c59740156ea539799014b91" +
+	"fc51086d050023727387b43dbbbbf63e799c60fbc8627b7fc2f360ce0b5757d7" +
+	"fc027630b6fe13105807c4be2534fff4750f535d4013deffffff01bac8e98837" +
+	"d0b1daa1c4d6aade86fe403c0cbebdf6ae8d37618df19bcf9e2dd62b7841747b" +
+	"3a40f59c5e3070d413d236f795032eb0137edfffb160cbc57cafc87004482043" +
+	"c9b8974efd03628b0954bb189b6a13b7293957c424bfa12f6987906478881c30" +
+	"f3800645222e00e9f5563f9a71367b27324b26b72be81953633018a29348ab53" +
+	"3a7608282a663f93817521cb39fa5ac09a190091103ea9fda8477d73962342f5" +
+	"d96eb4e26aa2615bb489eedce16f9cef2567c141b50c78dd53894ed51b35d7ce" +
+	"447ff2550152b9930508a6b2b26a3db197a40697c512b8f32ad22e9178a3238d" +
+	"b2b7ce8fe931089c98d2309addb25d58749b1a176f841a4093db29076c6f4824" +
+	"53472448b94c60a33f876c7151e4899720ffd280684c07147e7b80c54b3c592a" +
+	"5794597719c4841251127f34285d0467e51d9e8e59b738ab2cf3a0e96aa4855b" +
+	"83ed95747bce158cfb84d3d120b93f4443ee58ef50b26711d11644b8864af935" +
+	"6c080a7c6859fe14a11236d06badbf94993c11798f9c7dacf6e9690166c5b535" +
+	"0feb929ca9f54e6a274b14775354ad2a4a440bec89b4dd09914107136510f37b" +
+	"c9e46a44b0f1ad0a93227ebc6f03f2911916fe548ed3852a5a7c6935b83cbfd6" +
+	"1ea5cc3ee546ec13094da98416bb27f502cabcd07900b0ca298df7374e8bc8fa" +
+	"1807b8d442925b466d9ee5f22b5de48fe2026ed5ea6ed77308f784bf1755a1cc" +
+	"8825ff20f5ec0bbb6489a84c79868ab57b446ba1da3277b2063aedc61d10f02d" +
+	"579eab587bd61d323ec66b7315e97c57480018fd097538b826960151637ae21d" +
+	"19f37235901e838f701f5f10d4773d58f307b8be5a599709c546e26683c42f42" +
+	"1eb3428442401545662d169806301cd3ba7f8db1ebc8d43ba492b0152b9a6e47" +
+	"c0f5e2d4d1657b1c9f2bd330cfa59c9a4575ebf2da2643b378bb25966e3bf7b8" +
+	"bfc9225247e746f9a4d4bfaebbff7edf6840cd36b0aa7cbdc4cf412ec666ec2b" +
+	"ea50cf24ce76b0ea65334f39198533878296bd5b8823b16270d9e019c3a28dbc" +
+	"b30d8f4727eb8273e2e0596633d560c6b1234e2c92a24d16fadcb9de1ff9db7c" +
+	"e58f9fbad62a90c583f33d813df69e4db7d7b39105c8905ef93be33988cc39ae" +
+	"80b40d3b4973a198ae9e1e77400694f49f7842d5af5e38c392f1a81ff50e5767" +
+	"1e162394a082ccd777467165a50b98b25130d6096f0ff3bcabeb696f1715c03a" +
+	"9ec9aa933ce88be90837a7d332560ef4a6652f1310fb0739a87942833804ab1a" +
+	"365ae248ae0879aa91f8af9168b6d8cb72f73a861160bd16dbaf169dbef4e90b" +
+	"1dea305bf09cc5b224814e9050ac2d2f3d7f0e342df4fc0739b2e65e7a4dba8b" +
+	"a86cc2ed15cea1cb6fb54d97f197956cadd6c4ec819b3a7baf6d8cbae51e67c8" +
+	"234a7d85252cf014484aa4c1306fda44d605e0b0414b3964e79ae030b97e51e4" +
+	"8bc39f22cae16c9cf60e5a366bdfc043824cc3f7ebf54259cebafe88e3227cd3" +
+	"8d6ede4904d84287458095ba4031114241dccb0871ca4127d39d3ff98f156b4a" +
+	"3585d586f55ae49f27c535eb756a9d592743a5a06ac67012bc3b0a6f2b3b3a03" +
+	"1dd1f34991ed0c94400df43953c5609a70e24971832faab92d09b6d13a9dddf0" +
+	"a1556dee9c5d871d68444890309d1d76a790f16dd4433c175880815bf8429aff" +
+	"13a1046dacf39ee76260be23ad9fecddb1db36041a44e0b4aae533ae2591e890" +
+	"890ccbb99b800dca680b1f131db3cda359fee1f79d5c927c1acd70f495a86b34" +
+	"fd3d9b25abc06ebd9dbc24ea000e28b6e59d0f20638c63fb7c1789f30ee8ecaf" +
+	"e9e20c1c3ad2a55f8dd576e5a28e868844adba159c453aa2483469bb92443fa0" +
+	"e9c660fcadc123e9513097e00397166d015f96787715000000812f22d8170000" +
+	"00f85bb212b6f2b7d392ba3f397bd9b55f17805009f5eebc79f14bec5ca437ce" +
+	"c8e17c74c54f69217b140ee7401f2d7079910deec148a858ae7124effbfdefdc" +
+	"77bdb50ad8673f340dc9c40c54aa2b7fc79de11dcfc85077be973963cf1cfe2d" +
+	"1e163b41033122bf92718303a235c107001a3f717ea1f2003926c89febf84cb8" +
+	"21060653caf97b121995a4ce3e14f3547515e576b1e384349b4e5cdebc57794b" +
+	"e23ff4ccf156500f0f86ae6fea8641b515aa8b8f0a6590d6978b2a739ba38a92" +
+	"40f4f0bb2086935747b9e608ce383d71a3c0153744a836d4d214a62f39fd47f5" +
+	"1eeceebd3f42598a35ad5fbda4e6c88f9875bc0237b45c23d379b3ffaaf1a01f" +
+	"4083cf5b691c0ebba3e8189e6f5dd0ce56ffe0070e749d4b7cb2887b9a939ef9" +
+	"888a8aae1acd1ea70586e29b5fe6646b3d06ed1114b1fafdf3b7e6da7532d422" +
+	"915e22a027030db1d60222681154ece52b5b6e8ce073afc8f579b6755b3c438a" +
+	"c6b4c67b34782467819484a7f5aa56c393e7fc109f0245c63c5a055a3e22ab51" +
+	"6eef2fc39ea3f1ea24a2d77c40420cffc8277d507de8b5637a1ba802e970a9b9" +
+	"0b37d68f037020bb3d3aec1dc542fed81bd553102154685e36dc7f96d29fef30" +
+	"b6357149a1d9a403125b9fa47d2417e5d88a56fe2fabc5a7b989da56e6feaee3" +
+	"4d28f6ad004c8d9309da4522ed01352a7022e57055a442e4262d8e7ef76721f2" +
+	"ebd069558f0f0b093c54bdc2e35579a08cefe62bda221b1efa84c80c5f02552d" +
+	"fbd726ec0c151ab5608b08c7fe2d78afaaa571557b661746a4f838f4a801d796" +
+	"6e5ea184bef5fbcb722679f4f8de4e0cec8d47a76ae1c0c02ff98180dc55c35c" +
+	"65f6be7ea097a853bcc720ce7e151ca76fd6e3abeecdc3fdabee9fe32a3a9f8b" +
+	"303daa9cfe2bcab54f3e52c48d822e63681cfc8cbfe77011dee5a946f000e360" +
+	"e9cfafd3bd671b2706e4f96ce005d4d617c137d12fd3b3867e76fffdfe2decac" +
+	"bfc847fc9fbfd77412bb8031dc3b7ae51d072e5f1ccbb31d938e1b138fa91717" +
+	"c81f82ef415f6fc147df4706ffa600132f1a6b8cfff6dd9796f5b2c118dc7a1f" +
+	"03dc73fa76e2fde6454435134b95ac5247c65a17994f3ba1a8e87b9a658e08c7" +
+	"4acbcf56d6c7fcf98516444f065c582344fcb85df574b5cc16fb594a956a981e" +
+	"feaa69f821f050f2885c5f9f962403961beb5a549ddac7ee745683d3590bafd1" +
+	"a27eed476968e3fb81985c8c5e25271a3f7faed9751bd12a5c795da7ef56a520" +
+	"34a53e6ed0757b6a1a8ba24e4b546ca99dde122a2ddfd72d314f0adce72a99e0" +
+	"8390814d6423a9d2889537b2e6736dee497c92b651ea6402be6f650c74b492dc" +
+	"3453670c6dafe76c7329626ff04e5c3f695797a98ba2e380f68ee2588923607d" +
+	"0fcb6df70222fcb2c4b9ef1aecc77b667d50ac12da75d97a3eb6bd651a0ec5c7" +
+	"e8c182709d8f97855a486e4abcaf3f3f203ff85216c56185f9aa81a9412e08ad" +
+	"d65b83713f3f4984d5017f599c4ea6f5e1f27ddcc85a11b671cef6aabed2737a" +
+	"c1ad1e2d47d1e84b327ca2a325525bda67cc8ea4558f1e428a79bd905678959d" +
+	"65f3900086f5271d99ba504ec711d042259286b25129474e0645b21acf78a6b1" +
+	"489455a454becb8ae037310356b573b3e54e96329fe6d47895bda79e5f4ae050" +
+	"c03a2fa79198e34ae492cc3c0650f9de69f560071adb866cf56faffb8408b98c" +
+	"6fc9c8d15895ce7441fc01bd8fbb44428c63a1c05e36135e11d542ea93d04924" +
+	"1c5a2a400954834d6a4c89149d833f0228f2539c60a76b064fbc1c06a24466ba" +
+	"a8d1e1a0994355f34d74aa3c1bc96bef1cda0f86654e6ec48e3ea32ff5b1a493" +
+	"bdf0ce437e0fae6faf918a1bf22ab82f546bcf5e0e73f526b42ff068885a7a2d" +
+	"d582c190ba41cb19a2d26da02e5b17b169115cb8332161805f109e4ec0bf264b" +
+	"fa2f2d164befde82a5910526cf234576b4a44d27c753c5a39704ac5c5ecff8af" +
+	"ccd2751b7b37989e4cc100ada4baf99ad142007022a41604a4dd90cd0ad4ed59" +
+	"74706e86a3d0a1b05b6e6407e4d2a0a36c9d61166e2e9d376f72f717c73fbad5" +
+	"9620e67508e87f0f1ea70c2e802f907174133b7c2d39a253c0a437c06a2baf45" +
+	"9262bedb1c70bf7034f9d5410ac6b1239b9ca0cf06550e64834cf0925ce327ed" +
+	"37aab245c5ce275272313a93fe1bb968af86fc9bea38c808b1033b10a43d2931" +
+	"d8f3c5b209ddb86fac6d7b76b863c1e20362054f7dcaac0675ef0b0b29ef274e" +
+	"d2a0b2ebcdc6745328be9e12599560af89bf415beff016278bec19232cc7970a" +
+	"03165b88c3be74fdf184d8f2d86a5a41a5248e4b40f5364b2208527736176320" +
+	"58b6b198627a6cfee83ef97cd304e47f3669d4fdef526a41d893ad474777081f" +
+	"eae8be8bec6e3ef3a1b8ae0e0cac0a147dcfb7d2d574b40833e4d0a2199656bb" +
+	"7717a527f761a6a7a983b154a5a8a8eccabe0e7b22e0ea7411243337ecef75a4" +
+	"06a153b4082b0f5980d1570c544e5b940490cd0856e6a67fd743cca421650461" +
+	"1a94b88d29fa9dea6a3a36d8d407b873daaaf37c197ed8a665017be24644db8c" +
+	"abd47d476d943ace3ccf46bdc15d6560a8fa4f"
+
+// sigHashTestV4Script and sigHashTestV4Amount are the script and value of the
+// output spent by the signed inputs of sigHashTestV4Tx.
+var (
+	sigHashTestV4Script = "76a91479cdc490345ad93554d12f551169d44e2c9eea2188ac"
+	sigHashTestV4Amount = int64(50000000)
+)
+
+// sigHashTestV5Tx is a v5 transaction for NU5 with two transparent inputs, one
+// transparent output, a Sapling spend and output and an Orchard action.
+var sigHashTestV5Tx = "050000800a27a726b4d0d6c2715977065c60be060217b59b220ecf52faf8118d" +
+	"18fb16424bfb695a1da44fb1feb974d79936c231e39e5c730016122c721296be" +
+	"f761ea08a6e625535572edea68fb04d93b6c468c9516083811100d727753f6a2" +
+	"c5f05e76c1c5568644c65629feab22f54aa83269e24780d716aeeea3105b3617" +
+	"4cdf192ceeec32643866005407698c4265cbe70176adeadecdbc04000001d903" +
+	"8cf66f9c2f6988632d46b2fdd1e1c96b5418e6b546d18629d15aebf5150f8ab8" +
+	"30396770fc68682e7fd47475b829cdc950d255c94ddf31bff6b61218f460742c" +
+	"c6059cbf24fba6346d49fd3aad38d90931613d445bece706da4a91105670018d" +
+	"be42d5faa7f784c10cc7be92f01bbb14a2448d7eb9b3b4d5a15efcca5c67a8dc" +
+	"5ba7876e7266e94d3d8c5734d73a050bf273681429276a7bcea8b12224b92baa" +
+	"395b0691aad23f0ab26b1391f76fb0a9cc42cb5e4655a5133c35468797b1ceab" +
+	"0a886595042246c4770aded03d86860e3e2fefaf57cf149834f35da63eff71c7" +
+	"4fa1603f46d6d930407a1aec7b4e6bc9cb56873a72e9b8b327fdceaf1e93d2c8" +
+	"dc4a39e47caea4688306ee970f73ab17a708d9b11fb7b87d90e863f62ad3e4c0" +
+	"5fff97a62558cdae9ad84c5a2275248f2f23b3ec1b5845eb80846e60a985398a" +
+	"a8c941d650ae6ea871c069ce85504f0172f4f741098eb9e631bda1670b79fc9d" +
+	"c42f78fd5089c0e63ab0a694a811676334a5cfe6146e7b8e346c8f0b71cf47eb" +
+	"d8d10c4ccae7bf8cd48e77ae9f58e72cdb5364f911cb9d45fcb39067b7c71295" +
+	"150bb524cf87a7f80340b6fce370be9d40ac3a12b38094f6ae4cc8772366ca6b" +
+	"83c754ae2fb13142580de192a742ad1149bbc87c9be0183d59bf58fdef826c63" +
+	"c8819175843476ca7ab387c22f60f9b34d794126800177367326f041cb4b56cc" +
+	"d2b8b7a77f0630d51569c7ede2851eb18d75ab5277884738df0a43eae6299ab2" +
+	"ddda55451d32e93f11291cd7eb7fed209a3a6b9609e13cd1ffb6cfa6db9cd7c4" +
+	"68b80315bfa6cde0c6f1fa2e291a5cc17af373c6cc1dbea4414b30e86a2ec66a" +
+	"c12bb77cc3de9bac2a480e3a7baed3a09bd2d284b1546df00279d98e3c0b36a8" +
+	"6c56682224ee77b1a5a5021276ace611ab32fc14f9988af064cb8b55440972d4" +
+	"1daf8cc64e1f45b28e37becfb1f77717b4534626c27f0b0770c80972e770a634" +
+	"21dd5e090dc9d18b9149629a86e3c553dab7d43cea50a86c0caadf3f730a3a9f" +
+	"abed665b3397d83ebd833515637e71c54224cafa5fd2894f7a462e69da8a0e08" +
+	"f8a30bab6f09bfd27af89b6e82e040517a8545ec501a215225e7b75f493906d5" +
+	"5c6ce38ef9962c6f20d2293abed1255248b9402266960928983d5cada0de5e5c" +
+	"eb24b67f019e2f66877ddf42027f784259c23d0c6b2c0be60000001ee9b89c14" +
+	"9149b1c28bbebf05789d6a881dde1f166c09cc95bc139aa97c155143b82197d3" +
+	"beb363f82cba4228ea5b60dc3b88a77d8fc05500662d447518ea4ed546dc2e5c" +
+	"b963c198d3559cc9b9dc7cf81b1df7ba293714703f510b00b9d63f6957298fcd" +
+	"f96ff4e264bcb882e1c12b071ef7a7c3f112b5cabf8251322900cc818df5fa00" +
+	"7a44b5138defb0310a18a78d88f30ab768b240803a4af37e1b23e2c80838626f" +
+	"9c6c68d99dcd1c7e0745c68dea0793cff81f5fe893dc7f9592354b8840670c85" +
+	"2b1c3dad5f26bbb91b8efadda89235938be5cf2e290ca3e4fdfce822939c4218" +
+	"cce35eb122a06a3fe16a6ffd2d0b245139792b79b07d60bc2f482560596df5ee" +
+	"6437762f1624f245cf8480346f7b9c81a5b1d671b87d9bd6554858b4753d0f9d" +
+	"2ecbf0d17bf5fd4d97ee5bd1658d2f15751980e0304b8c96d4a812a10592a65a" +
+	"4e028f6227beabae7a0ac11cd496d4db6e3903bfe30b917aeda5a4092726cd2d" +
+	"ce130251cc989f7014eb8748406d95fc131cd545278f4a6c2e2ab753bfcd761c" +
+	"c6d8d6c4f9c7c8fa3f2adb7e76b7984ea45ea9bb753c277140ca0333a971e4b8" +
+	"c7de507032bd8bd136c281a61bf978877c672d3c1d20439040723b0bc7ed222a" +
+	"b71a5c083772f428a4afc54efd9c5b4b193f8017eb1ccb32fba7e37ebce5abe6" +
+	"a12c1ba892d0dcf21bcee7f221bfff85ecee3ed9d2c0fda158ff02c77fcae2d2" +
+	"de58d332da38493457d475372b72f7a492f407924f2021da6ba93b016331be99" +
+	"531f140d6c4c32b63258cb42a77fe126748fdc9205aec7e780e284cd4596455a" +
+	"c4c708a6a7d1aeff46f930955e7b09a4234417a9f0db5f0ddef97421efad5e56" +
+	"6f7c9ba602691dacef58cac3b46ad0e0949c2e7ef512e0f18b7e89e11394b36b" +
+	"087098032a0765371e14a7cba0361d7d903cf82a34955c78683635945b037e33" +
+	"c686c138bc40c0914276cc59ac44a2b45281507b2dcd26cca37a91b76a32f315" +
+	"6f86d40ff6127d810cbabdb97c3242fc67cafe6037758195963a00c5b74b924d" +
+	"fe183e1428c8cf12a7c1ad0a8cb45ac8783f22b850c4cf4f57dbaacf140703c9" +
+	"07922a50e19dcbfa25baea3966bafd76d26b0d06c9919ce9aff3e6be7f094563" +
+	"aca2fa8fc2d372d73aa9030d795ae40ecccabef022edf5019828defaea90fed0" +
+	"cb67189d38cfb52bf4bff00d7c2fcba3fc9a568103922fcb6834a81d46ca6de2" +
+	"fe093b69113dacaaa5b59fd1eadfc9639095d871be44c53ff9fc4ba66e02c9b5" +
+	"9796e9189e80abb3f3ac723bb4072100cc0bf78ac07a69530aa228c8c8ab7c10" +
+	"01882a56304799734ada6479875fb1287f48359b9c9ff26c3ac2bc7d9059fab3" +
+	"72ed62fd04bfd77a2abe109251fe3b6447df816c2eb2412289285756eb029c08" +
+	"f39ce73404f50400a944006d4d622d703707fa7f298335b718f160966e93158c" +
+	"8adb63b65f3ed7a24f17eae6607106c6f24d96179f294066dd5ed5f6b5a1d284" +
+	"8f023537294a8d959178423e8df0dba95dd3fa07d8d2856c8ee8b39fcfbbc2d8" +
+	"17bf10c5a8644b733c021ce2c11ce34d6f4eed7ab558bb7b7108bda3c3b2e09a" +
+	"723c1d32341a1626b19db0517edccea34ba84e5a8422ae2d94456cb8adc409ed" +
+	"1ad5b911348d319d03a7a6aeb6bac306b5c4db05ca5d54d55508d542bc9a1136" +
+	"eeaacbf7a790002c9c6804ca0b8ffeed45bd883599bd05b0a4332a7d7dcf2770" +
+	"63357e21e899f8fa48080d7d2973835a994c704d571e547a4f037ba52b08e741" +
+	"5cf62c3ac86928732dd2e82fb11c287d1b0b9d939d3f48aa1f7d8ceeceef3f53" +
+	"786a419402d714c108537f13b82fa5ad7f087b96b18af4217c893d5c706b809e" +
+	"a91a8304c7822b56b455724d891ab112f1651cd650c2aa32c4c7a58f77bb1073" +
+	"9ed4d5c0604cde09dfd4b53aaec33c170233152bfbe10000000e972413b8864a" +
+	"594e1e6aafe2b5e9ae72ac4550355460587dce9745df7417dae360cd4aaffceb" +
+	"ad104db2cf60eadc94733c43438a33f50023a2b5d871d415825c74d611388303" +
+	"91f433fe07d3ab161997248f89698ee84ad6066976aecd3c59670b151847f3b5" +
+	"5fbad17ba712beefab560646a790abf726b32653d7a801b39f295a1fc6f2e5f9" +
+	"8b593a86dec2d3fa4d138138baa10be5ec6768b39bbeadd5ee22440a3e183a17" +
+	"4340ef43cc6c1f527a1bf9a11abecd66283dd6c47f047abcbfe450d4b2f0c518" +
+	"ef21847307f5af983a73eb9b3cd653b5be494ac89da310f4752bc76d48a580d9" +
+	"32ceade9ab78ebc6cb619ce8e57686bdacc2c476c33efe7e0fcde82f84a14d08" +
+	"aa7a43d7500b9a59cdd4f705b11967230d1e426457b42744f125ab153b5ba863" +
+	"8a6857ff5b02351dc3b49a0fc7ba99a5e644d9ba751204a4a427c52288cbbe49" +
+	"51554b2165c4ea89a65a1490d86ff99f8a1b3788b5ecbc465cd30114cad589af" +
+	"b0a5efd782d133cbd4906805d443cdec76675b3ee6efcea9c95a4b5134"
+
+// sigHashTestV5TxID is the transaction identifier of sigHashTestV5Tx.
+const sigHashTestV5TxID = "c56af2cbda7843d5b8ca4b204a45a41226cdc22f872edf17c05b3c16c1521832"
+
+// sigHashTestV5PrevOuts are the outputs spent by sigHashTestV5Tx.
+var sigHashTestV5PrevOuts = []struct {
+	value  int64
+	script string
+}{
+	{1607748322110990, "a41f476e6c01cba7ef58bdd6aab9a9485534818083e7f8fee08d84b7bc937c4ce470a3"},
+	{217070594075646, "29"},
+}
+
+// sigHashTest describes the signature hash of an input for a hash type.  The
+// second input of the test transactions has no matching output, so signing it
+// with SigHashSingle commits to no outputs.
+type sigHashTest struct {
+	idx      int
+	hashType txscript.SigHashType
+	want     string
+}
+
+// sigHashTestsV4 are the ZIP 243 signature hashes of sigHashTestV4Tx for the
+// Sapling consensus branch.
+var sigHashTestsV4 = []sigHashTest{
+	{0, txscript.SigHashAll,
+		"33c2a79ac291a547347446cef5fa239b8c7db041ea3715bb8ade977021e68c77"},
+	{0, txscript.SigHashNone,
+		"280ea61359817c8155c584191837bf9db6e1abe8d848e14937d50b51db942dad"},
+	{0, txscript.SigHashSingle,
+		"882323b9785cc861d1cbe33417b70468682c14179f187b5cd64a50f712ccac3e"},
+	{0, txscript.SigHashAll | txscript.SigHashAnyOneCanPay,
+		"b63c4a50e2a9f43af145c36d4fcd335c3c14e17baa34dfa6365145edcb472632"},
+	{0, txscript.SigHashNone | txscript.SigHashAnyOneCanPay,
+		"35db9bd59dcab8ad829aad07e369aa48a2e676bf72757f8f4e9758960a7bdc10"},
+	{0, txscript.SigHashSingle | txscript.SigHashAnyOneCanPay,
+		"bfa7ded3a9971fdfc5480e3152fd0afea30b30288b91d6749e11494424428567"},
+	{1, txscript.SigHashSingle,
+		"6a3d75654c8d677e9e76f94217aa8ded842d735144c4fd9d6b268611eb903520"},
+	{1, txscript.SigHashSingle | txscript.SigHashAnyOneCanPay,
+		"05fd145e6ffc3b7853c58a577a0d984d0b8a5b6ba9c87fa01881747a0d278f44"},
+}
+
+// sigHashTestsV5 are the ZIP 244 signature hashes of sigHashTestV5Tx.
+var sigHashTestsV5 = []sigHashTest{
+	{0, txscript.SigHashAll,
+		"593fac911e2f38c74f247a25ee11181123268b66487db09be9ef651ca714f5ba"},
+	{0, txscript.SigHashNone,
+		"3f73fed549e79e192cc25e2451ab5c5c16cc1177c9beb63a1dfccf62285822d1"},
+	{0, txscript.SigHashSingle,
+		"31a8b66b56043325f3ee2b82d7a60236b9b0a3d3d9c40da345b19e2884648584"},
+	{0, txscript.SigHashAll | txscript.SigHashAnyOneCanPay,
+		"c73433141cfefd990dc718ab137a9159654218adb86eeac1786441ab6fc3e9ec"},
+	{0, txscript.SigHashNone | txscript.SigHashAnyOneCanPay,
+		"9e8cf0600140498f039cddf568271f89bbdf0d6d62ffb6d98b914e705ecd7fcf"},
+	{0, txscript.SigHashSingle | txscript.SigHashAnyOneCanPay,
+		"5a7aa09de27755dc9d1064ce411a1422dfed2a1c8b66e8a77ab00612cfd3fa14"},
+	{1, txscript.SigHashSingle,
+		"a22f1cbc34a33765a1f033142b652fc14e75e8f4b14c62ae5b087895759ffc9f"},
+	{1, txscript.SigHashSingle | txscript.SigHashAnyOneCanPay,
+		"2da8e6fd686d2a44090681aa6a3339e8082b32bc1275be095d70d6555be1b0ec"},
+}
+
+// decodeTestTx deserializes a hex encoded test transaction.
+func decodeTestTx(t *testing.T, s string) *zcashwire.MsgTx {
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("invalid test transaction: %v", err)
+	}
+	var tx zcashwire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	return &tx
+}
+
+// decodeTestHex decodes a hex encoded test value.
+func decodeTestHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("invalid test value %q: %v", s, err)
+	}
+	return b
+}
+
+// TestCalcSignatureHashV4 ensures the ZIP 243 signature hashes of a v4
+// transaction are computed correctly for every hash type.
+func TestCalcSignatureHashV4(t *testing.T) {
+	tx := decodeTestTx(t, sigHashTestV4Tx)
+	sigHashes, err := NewTxSigHashes(tx, zcashcfg.BranchSapling, nil)
+	if err != nil {
+		t.Fatalf("NewTxSigHashes: %v", err)
+	}
+
+	script := decodeTestHex(t, sigHashTestV4Script)
+	for _, test := range sigHashTestsV4 {
+		hash, err := CalcSignatureHash(script, sigHashes, test.hashType,
+			tx, test.idx, sigHashTestV4Amount)
+		if err != nil {
+			t.Errorf("CalcSignatureHash(%d, %#x): %v", test.idx,
+				test.hashType, err)
+			continue
+		}
+		if got := hex.EncodeToString(hash); got != test.want {
+			t.Errorf("CalcSignatureHash(%d, %#x): got %s, want %s",
+				test.idx, test.hashType, got, test.want)
+		}
+	}
+}
+
+// TestCalcSignatureHashV5 ensures the ZIP 244 signature hashes of a v5
+// transaction are computed correctly for every hash type.
+func TestCalcSignatureHashV5(t *testing.T) {
+	tx := decodeTestTx(t, sigHashTestV5Tx)
+	prevOuts := make([]*wire.TxOut, 0, len(sigHashTestV5PrevOuts))
+	for _, prevOut := range sigHashTestV5PrevOuts {
+		prevOuts = append(prevOuts, wire.NewTxOut(prevOut.value,
+			decodeTestHex(t, prevOut.script)))
+	}
+	sigHashes, err := NewTxSigHashes(tx, zcashcfg.BranchNU5, prevOuts)
+	if err != nil {
+		t.Fatalf("NewTxSigHashes: %v", err)
+	}
+
+	for _, test := range sigHashTestsV5 {
+		prevOut := prevOuts[test.idx]
+		hash, err := CalcSignatureHash(prevOut.PkScript, sigHashes,
+			test.hashType, tx, test.idx, prevOut.Value)
+		if err != nil {
+			t.Errorf("CalcSignatureHash(%d, %#x): %v", test.idx,
+				test.hashType, err)
+			continue
+		}
+		if got := hex.EncodeToString(hash); got != test.want {
+			t.Errorf("CalcSignatureHash(%d, %#x): got %s, want %s",
+				test.idx, test.hashType, got, test.want)
+		}
+	}
+}
+
+// TestTxIDDigestsV5 ensures the root of the ZIP 244 digest tree shared with
+// the signature hashes is the transaction identifier.
+func TestTxIDDigestsV5(t *testing.T) {
+	tx := decodeTestTx(t, sigHashTestV5Tx)
+	txid := tx.TxIDDigests().Hash(uint32(zcashcfg.BranchNU5))
+	if got := txid.String(); got != sigHashTestV5TxID {
+		t.Errorf("TxIDDigests().Hash: got %s, want %s", got,
+			sigHashTestV5TxID)
+	}
+	if got := tx.TxHash().String(); got != sigHashTestV5TxID {
+		t.Errorf("TxHash: got %s, want %s", got, sigHashTestV5TxID)
+	}
+}
+
+// TestCalcSignatureHashErrors ensures invalid signature hash requests are
+// rejected.
+func TestCalcSignatureHashErrors(t *testing.T) {
+	v4 := decodeTestTx(t, sigHashTestV4Tx)
+	v5 := decodeTestTx(t, sigHashTestV5Tx)
+
+	if _, err := NewTxSigHashes(v5, zcashcfg.BranchNU6,
+		make([]*wire.TxOut, len(v5.TxIn))); err != ErrBranchIDMismatch {
+		t.Errorf("NewTxSigHashes with another branch: got %v, want %v",
+			err, ErrBranchIDMismatch)
+	}
+	if _, err := NewTxSigHashes(v5, zcashcfg.BranchNU5, nil); err == nil {
+		t.Errorf("NewTxSigHashes without spent outputs: got no error")
+	}
+	if _, err := NewTxSigHashes(zcashwire.NewMsgTx(zcashwire.SproutTxVersion),
+		zcashcfg.BranchSprout, nil); err != ErrUnsupportedVersion {
+		t.Errorf("NewTxSigHashes of v2 transaction: got %v, want %v",
+			err, ErrUnsupportedVersion)
+	}
+
+	sigHashes, err := NewTxSigHashes(v4, zcashcfg.BranchSapling, nil)
+	if err != nil {
+		t.Fatalf("NewTxSigHashes: %v", err)
+	}
+	script := decodeTestHex(t, sigHashTestV4Script)
+	for _, hashType := range []txscript.SigHashType{0, 0x04, 0x41, 0x80} {
+		_, err := CalcSignatureHash(script, sigHashes, hashType, v4, 0,
+			sigHashTestV4Amount)
+		if err != ErrInvalidHashType {
+			t.Errorf("CalcSignatureHash(%#x): got %v, want %v",
+				hashType, err, ErrInvalidHashType)
+		}
+	}
+	for _, idx := range []int{-1, len(v4.TxIn)} {
+		_, err := CalcSignatureHash(script, sigHashes,
+			txscript.SigHashAll, v4, idx, sigHashTestV4Amount)
+		if err == nil {
+			t.Errorf("CalcSignatureHash(%d): got no error", idx)
+		}
+	}
+}
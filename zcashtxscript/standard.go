@@ -0,0 +1,32 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashtxscript
+
+import (
+	"fmt"
+
+	"github.com/arithmetric/zcashrpcclient/zcashutil"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// PayToAddrScript creates a new script to pay a transaction output to the
+// passed transparent address.  Shielded addresses cannot be paid by a
+// transparent output, so an error is returned for them.
+func PayToAddrScript(addr zcashutil.Address) ([]byte, error) {
+	switch addr := addr.(type) {
+	case *zcashutil.AddressPubKeyHash:
+		return txscript.NewScriptBuilder().AddOp(txscript.OP_DUP).
+			AddOp(txscript.OP_HASH160).AddData(addr.ScriptAddress()).
+			AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG).
+			Script()
+
+	case *zcashutil.AddressScriptHash:
+		return txscript.NewScriptBuilder().AddOp(txscript.OP_HASH160).
+			AddData(addr.ScriptAddress()).AddOp(txscript.OP_EQUAL).
+			Script()
+	}
+	return nil, fmt.Errorf("unable to generate payment script for "+
+		"unsupported address type %T", addr)
+}
@@ -0,0 +1,429 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashutil/bech32"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+)
+
+var (
+	// ErrChecksumMismatch describes an error where decoding failed due
+	// to a bad checksum.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrUnknownAddressType describes an error where an address can not
+	// decoded as a specific address type due to the string encoding
+	// beginning with an identifier byte unknown to any standard or
+	// registered (via zcashcfg.Register) network.
+	ErrUnknownAddressType = errors.New("unknown address type")
+
+	// ErrInvalidFormat describes an error where decoding failed due to
+	// an encoding that is malformed for the address type it claims to be.
+	ErrInvalidFormat = errors.New("invalid format")
+)
+
+const (
+	// SproutPaymentAddrSize is the size of a raw Sprout payment address,
+	// which is the paying key a_pk followed by the transmission key pk_enc.
+	SproutPaymentAddrSize = 64
+
+	// SaplingPaymentAddrSize is the size of a raw Sapling payment address,
+	// which is the 11-byte diversifier followed by the diversified
+	// transmission key pk_d.
+	SaplingPaymentAddrSize = 43
+
+	// SaplingDiversifierSize is the size of a Sapling diversifier.
+	SaplingDiversifierSize = 11
+
+	// OrchardRawAddrSize is the size of a raw Orchard address, which is
+	// only encoded as a receiver of a unified address.
+	OrchardRawAddrSize = 43
+)
+
+// Address is an interface type for any type of destination a Zcash
+// transaction output may spend to.  This includes transparent pay-to-pubkey-
+// hash (P2PKH) and pay-to-script-hash (P2SH) addresses as well as Sprout,
+// Sapling and unified shielded payment addresses.  Address is designed to be
+// generic enough that other kinds of addresses may be added in the future
+// without changing the decoding and encoding API.
+//
+// The concrete Address types are pointers, so two values for the same address
+// are not equal.  Key maps on the result of EncodeAddress instead.
+type Address interface {
+	// String returns the string encoding of the address.  This is the
+	// same as EncodeAddress and is provided to satisfy fmt.Stringer.
+	String() string
+
+	// EncodeAddress returns the string encoding of the address in the
+	// form zcashd accepts and returns over RPC.
+	EncodeAddress() string
+
+	// ScriptAddress returns the raw bytes of the address.  For
+	// transparent addresses these are the bytes inserted into a txout's
+	// script.  For shielded addresses they are the raw payment address
+	// encoded by the string form.
+	ScriptAddress() []byte
+
+	// IsForNet returns whether or not the address is associated with the
+	// passed Zcash network.
+	IsForNet(*zcashcfg.Params) bool
+}
+
+// encodeBase58Check prepends the two-byte version to the payload, appends the
+// first four bytes of its double SHA-256 and encodes the result with base58.
+func encodeBase58Check(version [2]byte, payload []byte) string {
+	b := make([]byte, 0, 2+len(payload)+4)
+	b = append(b, version[:]...)
+	b = append(b, payload...)
+	cksum := chainhash.DoubleHashB(b)
+	b = append(b, cksum[:4]...)
+	return base58.Encode(b)
+}
+
+// decodeBase58Check decodes a base58check string carrying a two-byte version,
+// returning the version and payload.
+func decodeBase58Check(s string) ([2]byte, []byte, error) {
+	var version [2]byte
+	decoded := base58.Decode(s)
+	if len(decoded) < 6 {
+		return version, nil, ErrInvalidFormat
+	}
+	cksum := chainhash.DoubleHashB(decoded[:len(decoded)-4])
+	if !bytes.Equal(cksum[:4], decoded[len(decoded)-4:]) {
+		return version, nil, ErrChecksumMismatch
+	}
+	copy(version[:], decoded[:2])
+	return version, decoded[2 : len(decoded)-4], nil
+}
+
+// DecodeAddress decodes the string encoding of an address and returns the
+// Address if addr is a valid encoding for a known address type.
+//
+// The network the address is associated with is determined from its prefix
+// and may be checked with IsForNet.  The test and regression test networks
+// share their transparent and Sprout prefixes, so such addresses report being
+// for both networks.
+func DecodeAddress(addr string) (Address, error) {
+	// Shielded Sapling and unified addresses use bech32 and bech32m, which
+	// are recognized by the human-readable part preceding the last '1'.
+	if sep := strings.LastIndex(addr, "1"); sep > 0 {
+		hrp := strings.ToLower(addr[:sep])
+		switch {
+		case zcashcfg.IsSaplingPaymentAddrHRP(hrp):
+			return decodeSaplingAddress(addr)
+		case zcashcfg.IsUnifiedAddrHRP(hrp):
+			return decodeUnifiedAddress(addr)
+		}
+	}
+
+	netID, payload, err := decodeBase58Check(addr)
+	if err != nil {
+		if err == ErrChecksumMismatch {
+			return nil, ErrChecksumMismatch
+		}
+		return nil, fmt.Errorf("decoded address is of unknown format")
+	}
+
+	switch {
+	case zcashcfg.IsPubKeyHashAddrID(netID):
+		if len(payload) != ripemd160Size {
+			return nil, ErrInvalidFormat
+		}
+		return newAddressPubKeyHash(payload, netID)
+
+	case zcashcfg.IsScriptHashAddrID(netID):
+		if len(payload) != ripemd160Size {
+			return nil, ErrInvalidFormat
+		}
+		return newAddressScriptHashFromHash(payload, netID)
+
+	case zcashcfg.IsSproutPaymentAddrID(netID):
+		if len(payload) != SproutPaymentAddrSize {
+			return nil, ErrInvalidFormat
+		}
+		addr := &AddressSprout{netID: netID}
+		copy(addr.addr[:], payload)
+		return addr, nil
+	}
+
+	return nil, ErrUnknownAddressType
+}
+
+// ripemd160Size is the size of a hash160 carried by transparent addresses.
+const ripemd160Size = 20
+
+// AddressPubKeyHash is an Address for a transparent pay-to-pubkey-hash
+// (P2PKH) transaction.
+type AddressPubKeyHash struct {
+	hash  [ripemd160Size]byte
+	netID [2]byte
+}
+
+// NewAddressPubKeyHash returns a new AddressPubKeyHash.  pkHash must be 20
+// bytes.
+func NewAddressPubKeyHash(pkHash []byte, net *zcashcfg.Params) (*AddressPubKeyHash, error) {
+	return newAddressPubKeyHash(pkHash, net.PubKeyHashAddrID)
+}
+
+// newAddressPubKeyHash is the internal API to create a pubkey hash address
+// with a known leading identifier.  The identifier is not checked for
+// validity.
+func newAddressPubKeyHash(pkHash []byte, netID [2]byte) (*AddressPubKeyHash, error) {
+	// Check for a valid pubkey hash length.
+	if len(pkHash) != ripemd160Size {
+		return nil, errors.New("pkHash must be 20 bytes")
+	}
+
+	addr := &AddressPubKeyHash{netID: netID}
+	copy(addr.hash[:], pkHash)
+	return addr, nil
+}
+
+// EncodeAddress returns the string encoding of a pay-to-pubkey-hash address.
+// Part of the Address interface.
+func (a *AddressPubKeyHash) EncodeAddress() string {
+	return encodeBase58Check(a.netID, a.hash[:])
+}
+
+// ScriptAddress returns the bytes to be included in a txout script to pay
+// to a pubkey hash.  Part of the Address interface.
+func (a *AddressPubKeyHash) ScriptAddress() []byte {
+	return a.hash[:]
+}
+
+// IsForNet returns whether or not the pay-to-pubkey-hash address is
+// associated with the passed Zcash network.
+func (a *AddressPubKeyHash) IsForNet(net *zcashcfg.Params) bool {
+	return a.netID == net.PubKeyHashAddrID
+}
+
+// String returns a human-readable string for the pay-to-pubkey-hash address.
+// This is equivalent to calling EncodeAddress, but is provided so the type can
+// be used as a fmt.Stringer.
+func (a *AddressPubKeyHash) String() string {
+	return a.EncodeAddress()
+}
+
+// Hash160 returns the underlying array of the pubkey hash.  This can be useful
+// when an array is more appropriate than a slice (for example, when used as map
+// keys).
+func (a *AddressPubKeyHash) Hash160() *[ripemd160Size]byte {
+	return &a.hash
+}
+
+// AddressScriptHash is an Address for a transparent pay-to-script-hash (P2SH)
+// transaction.
+type AddressScriptHash struct {
+	hash  [ripemd160Size]byte
+	netID [2]byte
+}
+
+// NewAddressScriptHash returns a new AddressScriptHash.
+func NewAddressScriptHash(serializedScript []byte, net *zcashcfg.Params) (*AddressScriptHash, error) {
+	scriptHash := btcutil.Hash160(serializedScript)
+	return newAddressScriptHashFromHash(scriptHash, net.ScriptHashAddrID)
+}
+
+// NewAddressScriptHashFromHash returns a new AddressScriptHash.  scriptHash
+// must be 20 bytes.
+func NewAddressScriptHashFromHash(scriptHash []byte, net *zcashcfg.Params) (*AddressScriptHash, error) {
+	return newAddressScriptHashFromHash(scriptHash, net.ScriptHashAddrID)
+}
+
+// newAddressScriptHashFromHash is the internal API to create a script hash
+// address with a known leading identifier.  The identifier is not checked for
+// validity.
+func newAddressScriptHashFromHash(scriptHash []byte, netID [2]byte) (*AddressScriptHash, error) {
+	// Check for a valid script hash length.
+	if len(scriptHash) != ripemd160Size {
+		return nil, errors.New("scriptHash must be 20 bytes")
+	}
+
+	addr := &AddressScriptHash{netID: netID}
+	copy(addr.hash[:], scriptHash)
+	return addr, nil
+}
+
+// EncodeAddress returns the string encoding of a pay-to-script-hash address.
+// Part of the Address interface.
+func (a *AddressScriptHash) EncodeAddress() string {
+	return encodeBase58Check(a.netID, a.hash[:])
+}
+
+// ScriptAddress returns the bytes to be included in a txout script to pay
+// to a script hash.  Part of the Address interface.
+func (a *AddressScriptHash) ScriptAddress() []byte {
+	return a.hash[:]
+}
+
+// IsForNet returns whether or not the pay-to-script-hash address is associated
+// with the passed Zcash network.
+func (a *AddressScriptHash) IsForNet(net *zcashcfg.Params) bool {
+	return a.netID == net.ScriptHashAddrID
+}
+
+// String returns a human-readable string for the pay-to-script-hash address.
+// This is equivalent to calling EncodeAddress, but is provided so the type can
+// be used as a fmt.Stringer.
+func (a *AddressScriptHash) String() string {
+	return a.EncodeAddress()
+}
+
+// Hash160 returns the underlying array of the script hash.  This can be useful
+// when an array is more appropriate than a slice (for example, when used as map
+// keys).
+func (a *AddressScriptHash) Hash160() *[ripemd160Size]byte {
+	return &a.hash
+}
+
+// AddressSprout is an Address for a shielded Sprout payment address.
+type AddressSprout struct {
+	addr  [SproutPaymentAddrSize]byte
+	netID [2]byte
+}
+
+// NewAddressSprout returns a new AddressSprout from the raw payment address,
+// which is the 32-byte paying key followed by the 32-byte transmission key.
+func NewAddressSprout(paymentAddr []byte, net *zcashcfg.Params) (*AddressSprout, error) {
+	if len(paymentAddr) != SproutPaymentAddrSize {
+		return nil, fmt.Errorf("paymentAddr must be %d bytes",
+			SproutPaymentAddrSize)
+	}
+
+	addr := &AddressSprout{netID: net.SproutPaymentAddrID}
+	copy(addr.addr[:], paymentAddr)
+	return addr, nil
+}
+
+// EncodeAddress returns the string encoding of a Sprout payment address.
+// Part of the Address interface.
+func (a *AddressSprout) EncodeAddress() string {
+	return encodeBase58Check(a.netID, a.addr[:])
+}
+
+// ScriptAddress returns the raw Sprout payment address.  Part of the Address
+// interface.
+func (a *AddressSprout) ScriptAddress() []byte {
+	return a.addr[:]
+}
+
+// IsForNet returns whether or not the Sprout address is associated with the
+// passed Zcash network.
+func (a *AddressSprout) IsForNet(net *zcashcfg.Params) bool {
+	return a.netID == net.SproutPaymentAddrID
+}
+
+// String returns a human-readable string for the Sprout address.  This is
+// equivalent to calling EncodeAddress, but is provided so the type can be
+// used as a fmt.Stringer.
+func (a *AddressSprout) String() string {
+	return a.EncodeAddress()
+}
+
+// PayingKey returns the paying key a_pk of the Sprout address.
+func (a *AddressSprout) PayingKey() [32]byte {
+	var k [32]byte
+	copy(k[:], a.addr[:32])
+	return k
+}
+
+// TransmissionKey returns the transmission key pk_enc of the Sprout address.
+func (a *AddressSprout) TransmissionKey() [32]byte {
+	var k [32]byte
+	copy(k[:], a.addr[32:])
+	return k
+}
+
+// AddressSapling is an Address for a shielded Sapling payment address.
+type AddressSapling struct {
+	addr [SaplingPaymentAddrSize]byte
+	hrp  string
+}
+
+// NewAddressSapling returns a new AddressSapling from the raw 43-byte payment
+// address, which is the diversifier followed by the diversified transmission
+// key.
+func NewAddressSapling(paymentAddr []byte, net *zcashcfg.Params) (*AddressSapling, error) {
+	if len(paymentAddr) != SaplingPaymentAddrSize {
+		return nil, fmt.Errorf("paymentAddr must be %d bytes",
+			SaplingPaymentAddrSize)
+	}
+
+	addr := &AddressSapling{hrp: net.SaplingPaymentAddrHRP}
+	copy(addr.addr[:], paymentAddr)
+	return addr, nil
+}
+
+// decodeSaplingAddress decodes the bech32 encoding of a Sapling payment
+// address.
+func decodeSaplingAddress(s string) (*AddressSapling, error) {
+	hrp, data, version, err := bech32.DecodeToBase256(s)
+	if err != nil {
+		return nil, err
+	}
+	if version != bech32.Bech32 {
+		return nil, fmt.Errorf("sapling address must use %v, not %v",
+			bech32.Bech32, version)
+	}
+	if len(data) != SaplingPaymentAddrSize {
+		return nil, ErrInvalidFormat
+	}
+
+	addr := &AddressSapling{hrp: hrp}
+	copy(addr.addr[:], data)
+	return addr, nil
+}
+
+// EncodeAddress returns the string encoding of a Sapling payment address.
+// Part of the Address interface.
+func (a *AddressSapling) EncodeAddress() string {
+	// The only possible error is an invalid human-readable part, which
+	// is not possible for an address created by this package.
+	s, _ := bech32.EncodeFromBase256(a.hrp, a.addr[:], bech32.Bech32)
+	return s
+}
+
+// ScriptAddress returns the raw Sapling payment address.  Part of the Address
+// interface.
+func (a *AddressSapling) ScriptAddress() []byte {
+	return a.addr[:]
+}
+
+// IsForNet returns whether or not the Sapling address is associated with the
+// passed Zcash network.
+func (a *AddressSapling) IsForNet(net *zcashcfg.Params) bool {
+	return a.hrp == net.SaplingPaymentAddrHRP
+}
+
+// String returns a human-readable string for the Sapling address.  This is
+// equivalent to calling EncodeAddress, but is provided so the type can be
+// used as a fmt.Stringer.
+func (a *AddressSapling) String() string {
+	return a.EncodeAddress()
+}
+
+// Diversifier returns the diversifier d of the Sapling address.
+func (a *AddressSapling) Diversifier() [SaplingDiversifierSize]byte {
+	var d [SaplingDiversifierSize]byte
+	copy(d[:], a.addr[:SaplingDiversifierSize])
+	return d
+}
+
+// TransmissionKey returns the diversified transmission key pk_d of the
+// Sapling address.
+func (a *AddressSapling) TransmissionKey() [32]byte {
+	var k [32]byte
+	copy(k[:], a.addr[SaplingDiversifierSize:])
+	return k
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bech32
+
+import (
+	"fmt"
+	"strings"
+)
+
+// charset is the set of characters used in the data section of bech32
+// strings.
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// checksumLength is the number of 5-bit groups in the checksum.
+const checksumLength = 6
+
+// Version identifies which checksum constant a string is encoded with.
+type Version int
+
+const (
+	// Bech32 is the original checksum defined by BIP 173.  It is used by
+	// Sapling addresses and keys.
+	Bech32 Version = iota
+
+	// Bech32m is the modified checksum defined by BIP 350.  It is used by
+	// unified addresses and viewing keys.
+	Bech32m
+)
+
+// checksumConst returns the constant the polymod of a valid string with the
+// given checksum version evaluates to.
+func (v Version) checksumConst() uint32 {
+	if v == Bech32m {
+		return 0x2bc830a3
+	}
+	return 1
+}
+
+// String returns the name of the checksum version.
+func (v Version) String() string {
+	if v == Bech32m {
+		return "bech32m"
+	}
+	return "bech32"
+}
+
+// charsetRev maps characters of charset back to their 5-bit values, with -1
+// marking characters that are not part of the charset.
+var charsetRev [128]int8
+
+func init() {
+	for i := range charsetRev {
+		charsetRev[i] = -1
+	}
+	for i := 0; i < len(charset); i++ {
+		charsetRev[charset[i]] = int8(i)
+	}
+}
+
+// gen holds the generator constants of the BCH code.
+var gen = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+// polymod computes the checksum polynomial of the passed 5-bit values.
+func polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		b := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (b>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+// hrpExpand expands the human-readable part for use in checksum computation.
+func hrpExpand(hrp string) []byte {
+	v := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		v = append(v, hrp[i]>>5)
+	}
+	v = append(v, 0)
+	for i := 0; i < len(hrp); i++ {
+		v = append(v, hrp[i]&31)
+	}
+	return v
+}
+
+// createChecksum computes the checksum for the hrp and 5-bit data.
+func createChecksum(hrp string, data []byte, version Version) []byte {
+	values := append(hrpExpand(hrp), data...)
+	values = append(values, make([]byte, checksumLength)...)
+	mod := polymod(values) ^ version.checksumConst()
+	checksum := make([]byte, checksumLength)
+	for i := 0; i < checksumLength; i++ {
+		checksum[i] = byte((mod >> uint(5*(5-i))) & 31)
+	}
+	return checksum
+}
+
+// Encode encodes the human-readable part and 5-bit data into a string using
+// the given checksum version.  Unlike BIP 173 no limit is imposed on the
+// length of the result, since Zcash unified addresses and viewing keys are
+// considerably longer than 90 characters.
+func Encode(hrp string, data []byte, version Version) (string, error) {
+	if len(hrp) < 1 {
+		return "", fmt.Errorf("invalid human-readable part length %d",
+			len(hrp))
+	}
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", fmt.Errorf("invalid character in "+
+				"human-readable part at position %d", i)
+		}
+	}
+	if strings.ToLower(hrp) != hrp {
+		return "", fmt.Errorf("human-readable part must be lowercase")
+	}
+
+	var sb []byte
+	sb = append(sb, hrp...)
+	sb = append(sb, '1')
+	for _, b := range data {
+		if b >= 32 {
+			return "", fmt.Errorf("invalid data value %d", b)
+		}
+		sb = append(sb, charset[b])
+	}
+	for _, b := range createChecksum(hrp, data, version) {
+		sb = append(sb, charset[b])
+	}
+	return string(sb), nil
+}
+
+// Decode decodes a bech32 or bech32m string into its human-readable part and
+// 5-bit data, returning the checksum version it was encoded with.  The
+// checksum is removed from the returned data.  No limit is imposed on the
+// length of the string.
+func Decode(s string) (string, []byte, Version, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, 0, fmt.Errorf("string uses mixed case")
+	}
+	s = strings.ToLower(s)
+
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+checksumLength+1 > len(s) {
+		return "", nil, 0, fmt.Errorf("invalid separator position %d",
+			sep)
+	}
+
+	hrp := s[:sep]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, 0, fmt.Errorf("invalid character in "+
+				"human-readable part at position %d", i)
+		}
+	}
+
+	data := make([]byte, 0, len(s)-sep-1)
+	for i := sep + 1; i < len(s); i++ {
+		c := s[i]
+		if c >= 128 || charsetRev[c] == -1 {
+			return "", nil, 0, fmt.Errorf("invalid character %q "+
+				"at position %d", c, i)
+		}
+		data = append(data, byte(charsetRev[c]))
+	}
+
+	var version Version
+	switch polymod(append(hrpExpand(hrp), data...)) {
+	case Bech32.checksumConst():
+		version = Bech32
+	case Bech32m.checksumConst():
+		version = Bech32m
+	default:
+		return "", nil, 0, fmt.Errorf("invalid checksum")
+	}
+
+	return hrp, data[:len(data)-checksumLength], version, nil
+}
+
+// ConvertBits regroups the passed data from fromBits bits per element to
+// toBits bits per element.  When pad is true any remaining bits are padded
+// with zeros into a final element.  Otherwise excess padding must be zero
+// and shorter than fromBits, or an error is returned.
+func ConvertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	if fromBits < 1 || fromBits > 8 || toBits < 1 || toBits > 8 {
+		return nil, fmt.Errorf("invalid bit group sizes %d and %d",
+			fromBits, toBits)
+	}
+
+	var acc uint32
+	var bits uint
+	maxv := uint32(1)<<toBits - 1
+	ret := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, value := range data {
+		if uint32(value)>>fromBits != 0 {
+			return nil, fmt.Errorf("invalid data value %d", value)
+		}
+		acc = acc<<fromBits | uint32(value)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			ret = append(ret, byte((acc>>bits)&maxv))
+		}
+	}
+
+	if pad {
+		if bits > 0 {
+			ret = append(ret, byte((acc<<(toBits-bits))&maxv))
+		}
+	} else if bits >= fromBits {
+		return nil, fmt.Errorf("illegal zero padding")
+	} else if (acc<<(toBits-bits))&maxv != 0 {
+		return nil, fmt.Errorf("non-zero padding")
+	}
+
+	return ret, nil
+}
+
+// EncodeFromBase256 converts the passed 8-bit data to 5-bit groups and
+// encodes it with the human-readable part and checksum version.
+func EncodeFromBase256(hrp string, data []byte, version Version) (string, error) {
+	conv, err := ConvertBits(data, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return Encode(hrp, conv, version)
+}
+
+// DecodeToBase256 decodes the passed string and converts its data back to
+// 8-bit bytes.
+func DecodeToBase256(s string) (string, []byte, Version, error) {
+	hrp, data, version, err := Decode(s)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	conv, err := ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	return hrp, conv, version, nil
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bech32
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestDecodeValid decodes the valid test vectors of BIP 173 and BIP 350 and
+// ensures they encode back to the same string.
+func TestDecodeValid(t *testing.T) {
+	tests := []struct {
+		s       string
+		version Version
+	}{
+		// BIP 350 valid Bech32m strings.
+		{"A1LQFN3A", Bech32m},
+		{"a1lqfn3a", Bech32m},
+		{"an83characterlonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11sg7hg6", Bech32m},
+		{"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx", Bech32m},
+		{"11" + strings.Repeat("l", 83) + "udsr8", Bech32m},
+		{"split1checkupstagehandshakeupstreamerranterredcaperredlc445v", Bech32m},
+		{"?1v759aa", Bech32m},
+
+		// BIP 173 valid Bech32 strings.
+		{"A12UEL5L", Bech32},
+		{"a12uel5l", Bech32},
+		{"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs", Bech32},
+		{"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", Bech32},
+		{"11" + strings.Repeat("q", 82) + "c8247j", Bech32},
+		{"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w", Bech32},
+		{"?1ezyfcl", Bech32},
+
+		// BIP 350 rejects strings longer than 90 characters, which
+		// Zcash unified encodings are.
+		{"an84characterslonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11d6pts4", Bech32m},
+	}
+
+	for _, test := range tests {
+		hrp, data, version, err := Decode(test.s)
+		if err != nil {
+			t.Errorf("Decode(%q): %v", test.s, err)
+			continue
+		}
+		if version != test.version {
+			t.Errorf("Decode(%q): got %v, want %v", test.s, version,
+				test.version)
+		}
+		s, err := Encode(hrp, data, version)
+		if err != nil {
+			t.Errorf("Encode(%q): %v", hrp, err)
+			continue
+		}
+		if s != strings.ToLower(test.s) {
+			t.Errorf("Encode(%q): got %q, want %q", hrp, s,
+				strings.ToLower(test.s))
+		}
+
+		// Changing any character invalidates the checksum.
+		b := []byte(strings.ToLower(test.s))
+		sep := bytes.LastIndexByte(b, '1')
+		for i := sep + 1; i < len(b); i++ {
+			c := b[i]
+			b[i] = charset[(strings.IndexByte(charset, c)+1)%32]
+			if _, _, _, err := Decode(string(b)); err == nil {
+				t.Errorf("Decode(%q): got no error", b)
+			}
+			b[i] = c
+		}
+	}
+}
+
+// TestDecodeInvalid ensures the invalid test vectors of BIP 350 are rejected.
+func TestDecodeInvalid(t *testing.T) {
+	tests := []string{
+		"\x201xj0phk",    // HRP character out of range
+		"\x7f1g6xzxy",    // HRP character out of range
+		"\x801vctc34",    // HRP character out of range
+		"qyrz8wqd2c9m",   // No separator character
+		"1qyrz8wqd2c9m",  // Empty HRP
+		"y1b0jsk6g",      // Invalid data character
+		"lt1igcx5c0",     // Invalid data character
+		"in1muywd",       // Too short checksum
+		"mm1crxm3i",      // Invalid character in checksum
+		"au1s5cgom",      // Invalid character in checksum
+		"M1VUXWEZ",       // Checksum calculated with uppercase HRP
+		"16plkw9",        // Empty HRP
+		"1p2gdwpf",       // Empty HRP
+		"A1LQFN3a",       // Mixed case
+		"abcdef1l7aum6e", // Invalid checksum
+	}
+
+	for _, s := range tests {
+		if _, _, _, err := Decode(s); err == nil {
+			t.Errorf("Decode(%q): got no error", s)
+		}
+	}
+}
+
+// TestEncodeInvalid ensures strings that cannot be decoded are not encoded.
+func TestEncodeInvalid(t *testing.T) {
+	tests := []struct {
+		hrp  string
+		data []byte
+	}{
+		{"", nil},
+		{"A", nil},
+		{"a b", nil},
+		{"a\x7f", nil},
+		{"a", []byte{0, 32}},
+	}
+
+	for _, test := range tests {
+		if s, err := Encode(test.hrp, test.data, Bech32m); err == nil {
+			t.Errorf("Encode(%q, %v): got %q", test.hrp, test.data, s)
+		}
+	}
+}
+
+func TestConvertBits(t *testing.T) {
+	tests := []struct {
+		in       []byte
+		from, to uint
+		pad      bool
+		out      []byte
+		err      bool
+	}{
+		{[]byte{0xff}, 8, 5, true, []byte{31, 28}, false},
+		{[]byte{0xff}, 8, 5, false, nil, true},
+		{[]byte{31, 28}, 5, 8, false, []byte{0xff}, false},
+		{[]byte{31, 29}, 5, 8, false, nil, true},
+		{[]byte{31, 28, 0}, 5, 8, false, nil, true},
+		{[]byte{0, 1, 2, 3, 4}, 8, 5, true,
+			[]byte{0, 0, 0, 16, 4, 0, 24, 4}, false},
+		{[]byte{0, 0, 0, 16, 4, 0, 24, 4}, 5, 8, false,
+			[]byte{0, 1, 2, 3, 4}, false},
+		{[]byte{32}, 5, 8, true, nil, true},
+		{[]byte{1}, 0, 8, true, nil, true},
+		{[]byte{1}, 8, 9, true, nil, true},
+	}
+
+	for _, test := range tests {
+		out, err := ConvertBits(test.in, test.from, test.to, test.pad)
+		if (err != nil) != test.err {
+			t.Errorf("ConvertBits(%v, %d, %d, %v): got error %v",
+				test.in, test.from, test.to, test.pad, err)
+			continue
+		}
+		if !bytes.Equal(out, test.out) {
+			t.Errorf("ConvertBits(%v, %d, %d, %v): got %v, want %v",
+				test.in, test.from, test.to, test.pad, out,
+				test.out)
+		}
+	}
+
+	// Round trips of every length pad to whole 5-bit groups.
+	for n := 0; n < 64; n++ {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i*37 + n)
+		}
+		s, err := EncodeFromBase256("zs", data, Bech32)
+		if err != nil {
+			t.Fatalf("EncodeFromBase256: %v", err)
+		}
+		hrp, decoded, version, err := DecodeToBase256(s)
+		if err != nil || hrp != "zs" || version != Bech32 ||
+			!bytes.Equal(decoded, data) {
+
+			t.Errorf("DecodeToBase256(%q): got %q %x %v %v", s, hrp,
+				decoded, version, err)
+		}
+	}
+}
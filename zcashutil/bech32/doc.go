@@ -0,0 +1,14 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package bech32 implements the bech32 format specified in BIP 173 and the
+bech32m variant specified in BIP 350.
+
+Zcash encodes Sapling addresses and keys with bech32 and unified addresses and
+viewing keys with bech32m.  Both are considerably longer than the 90 character
+limit BIP 173 imposes on Bitcoin addresses, so this package does not enforce
+any length limit.
+*/
+package bech32
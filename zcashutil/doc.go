@@ -0,0 +1,46 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package zcashutil provides Zcash-specific convenience functions and types.
+
+Addresses
+
+The btcutil Address types cannot represent Zcash addresses, since Zcash uses
+two-byte Base58Check prefixes for transparent addresses and adds several kinds
+of shielded addresses.  This package provides an Address interface with
+implementations for:
+
+  - transparent pay-to-pubkey-hash addresses (t1 on mainnet, tm on testnet and
+    regtest)
+  - transparent pay-to-script-hash addresses (t3 on mainnet, t2 on testnet and
+    regtest)
+  - Sprout payment addresses (zc on mainnet, zt on testnet and regtest)
+  - Sapling payment addresses encoded with bech32 (zs, ztestsapling and
+    zregtestsapling)
+  - ZIP 316 unified addresses encoded with F4Jumble and bech32m (u, utest and
+    uregtest)
+
+DecodeAddress determines the address type and network from the encoding, so
+addresses returned by zcashd can be decoded without knowing which network it
+is running on.  The network parameters are defined by the zcashcfg package.
+
+Unified Addresses and Viewing Keys
+
+Unified addresses are built with NewAddressUnified from a set of receivers and
+checked against the ZIP 316 rules.  The receivers of a decoded unified address
+can be inspected with Items, or with ListReceivers, which returns each known
+receiver as an Address just like the z_listunifiedreceivers RPC does.  Unified
+full and incoming viewing keys are handled the same way by the
+UnifiedFullViewingKey and UnifiedIncomingViewingKey types.
+
+Memos
+
+Shielded notes carry a 512-byte memo field, which RPCs such as
+z_viewtransaction and z_listreceivedbyaddress return as raw bytes.  DecodeMemo
+interprets the field according to ZIP 302 as UTF-8 text, an empty memo,
+arbitrary data or a memo reserved for future use.  NewTextMemo and
+NewArbitraryMemo build memos whose Hex encoding can be passed to z_sendmany.
+*/
+package zcashutil
@@ -0,0 +1,107 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashutil
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/arithmetric/zcashrpcclient/internal/blake2b"
+)
+
+const (
+	// f4JumbleMinLength is the minimum length of a message that may be
+	// jumbled.
+	f4JumbleMinLength = 48
+
+	// f4JumbleMaxLength is the maximum length of a message that may be
+	// jumbled, which is 2^16 + 1 BLAKE2b-512 outputs.
+	f4JumbleMaxLength = 4194368
+)
+
+// f4JumbleHash implements the H_i function of F4Jumble, which is BLAKE2b with
+// an output of leftLen bytes personalized by the round number.
+func f4JumbleHash(round byte, leftLen int, u []byte) []byte {
+	personal := []byte("UA_F4Jumble_H\x00\x00\x00")
+	personal[13] = round
+	h, _ := blake2b.New(leftLen, personal)
+	h.Write(u)
+	return h.Sum(nil)
+}
+
+// f4JumbleExpand implements the G_i function of F4Jumble, which concatenates
+// BLAKE2b-512 outputs personalized by the round number and a block counter
+// and truncates the result to rightLen bytes.
+func f4JumbleExpand(round byte, rightLen int, u []byte) []byte {
+	out := make([]byte, 0, rightLen+blake2b.MaxSize)
+	personal := []byte("UA_F4Jumble_G\x00\x00\x00")
+	personal[13] = round
+	for j := 0; len(out) < rightLen; j++ {
+		binary.LittleEndian.PutUint16(personal[14:], uint16(j))
+		h, _ := blake2b.New(blake2b.MaxSize, personal)
+		h.Write(u)
+		out = h.Sum(out)
+	}
+	return out[:rightLen]
+}
+
+// xorInto sets dst to dst XOR src.  Both must be of the same length.
+func xorInto(dst, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+// f4JumbleSplit validates the message length and returns the lengths of its
+// left and right halves.
+func f4JumbleSplit(msgLen int) (int, error) {
+	if msgLen < f4JumbleMinLength || msgLen > f4JumbleMaxLength {
+		return 0, fmt.Errorf("invalid F4Jumble message length %d",
+			msgLen)
+	}
+	leftLen := msgLen / 2
+	if leftLen > blake2b.MaxSize {
+		leftLen = blake2b.MaxSize
+	}
+	return leftLen, nil
+}
+
+// F4Jumble applies the unkeyed 4-round Feistel construction defined by ZIP
+// 316 to the passed message, which is how the encoding of unified addresses
+// and viewing keys is made non-malleable.  The input is not modified.
+func F4Jumble(msg []byte) ([]byte, error) {
+	leftLen, err := f4JumbleSplit(len(msg))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(msg))
+	copy(out, msg)
+	a, b := out[:leftLen], out[leftLen:]
+
+	xorInto(b, f4JumbleExpand(0, len(b), a)) // x = b ^ G0(a)
+	xorInto(a, f4JumbleHash(0, leftLen, b))  // y = a ^ H0(x)
+	xorInto(b, f4JumbleExpand(1, len(b), a)) // d = x ^ G1(y)
+	xorInto(a, f4JumbleHash(1, leftLen, b))  // c = y ^ H1(d)
+	return out, nil
+}
+
+// F4JumbleInv reverses F4Jumble.  The input is not modified.
+func F4JumbleInv(msg []byte) ([]byte, error) {
+	leftLen, err := f4JumbleSplit(len(msg))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(msg))
+	copy(out, msg)
+	c, d := out[:leftLen], out[leftLen:]
+
+	xorInto(c, f4JumbleHash(1, leftLen, d))  // y = c ^ H1(d)
+	xorInto(d, f4JumbleExpand(1, len(d), c)) // x = d ^ G1(y)
+	xorInto(c, f4JumbleHash(0, leftLen, d))  // a = y ^ H0(x)
+	xorInto(d, f4JumbleExpand(0, len(d), c)) // b = x ^ G0(a)
+	return out, nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashutil
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// testBytes returns n bytes following a simple pattern selected by seed.
+func testBytes(n, seed int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i*13 + seed)
+	}
+	return b
+}
+
+// f4JumbleTests are F4Jumble of testBytes(n, n), computed from the ZIP 316
+// specification independently of this package.
+var f4JumbleTests = []struct {
+	n    int
+	want string
+}{
+	{48, "06c9223afe6b1484719683be406dfb90c67cb40c7041a37062ae77b0e33fb8f8" +
+		"5399dfef0f4b3349966d32dabe3e6b67"},
+	{49, "996e6fa549ef8f9ab58fbc7c465af217d5b170014b6f58144b1126e089fd360a" +
+		"8a74d8ca4f2805192ff0193e71f49addb0"},
+	{83, "c43fbd8f00d876c63fe6e24485375f9e2219f77414ba5b3a7c306b9a0b13c695" +
+		"646d77f225a28299a0ca3a51ba0fde68d422f9c5a15a1318cf97f50630af9f47" +
+		"d1b78e119a1603bb9ea7b45ff1542c5a3d25e3"},
+	{128, "938262b331cd97f1588c63ab6420b4b76b8ca8be3e319976f2eb81a205f21c04" +
+		"0e4d79803ea83e2e49be9359e545f1fd111f2d9e6c23e6101fac9414b42b6fd9" +
+		"1cf8904aa24214fe5a2bb2bb912f762fbef4f53847f8ad68c93cb576d35d7cc2" +
+		"c4403c9c6950987522c6904e0df2081ed053b2c392b7dd2eaeb8fdc425ea2515"},
+	{129, "b9bdab5ad4ba27501c05cc4aff112f268bde66ab01634c87722ba329ec34c93c" +
+		"a663ffbc275d8c7b2f0b0bb3b13d080a3300ac64d9a4ee2b368b973f610c5901" +
+		"524e7f0b0b0ea9128043b34c868fdd8e8fae75fc7e1461e5da165358bf0e80b2" +
+		"8e4580278cc3dce73be809a7fff0e01af8dbbd5f0b7a685530637879178bdf4d" +
+		"22"},
+	{191, "e5de71313ce4b7e68e27b15e8b02fdf037027559a2dfca84365a8d71458d88e6" +
+		"1a88b726ffbaf856ea80f509075729a5e8592b66046f39c26b039729e8a8c2e4" +
+		"233aa21d5ac0f110a8be5238590f34d96ff295a402389a3af3839ac221b25edb" +
+		"fa08c19ff43eaeb6ba6c350a3821979c560ff66055bf9eba516f7bd8a4784c8e" +
+		"53d24d91a8c903c7f5313ea8b76e785cc19fefdc99403997e237a320205521e9" +
+		"f03a870b080394a4462663c32dfbae1a336b225258a0b14a0ce0df9b584c55"},
+	{300, "416ec04627aac4e94611a6b80d51c6badbd00c4e88483d6271fbcd5be828ab9e" +
+		"49d2d822d0af120eb18582500df7967edbc4266feda09f01368d0c5d7c6a50ab" +
+		"1123ecf8d35fb2e2b7358e67d846b65948121ce4de566ac43d3087ef48fb0922" +
+		"9e60652580467e5e536e40326854e2a2c0ae9ab79686c7625206c46f145972cb" +
+		"84f88b88b635e33e931d61bff61e319a45ff00c40e5028b214f72da1888d3759" +
+		"471417846dd63dbdff2a38432331f7bdb36f306c516a5c0f4b312e5a84dc1ede" +
+		"40a1f99bce2164ad435d8800672e1b99e1e776a47cb1f88ee842be752936f08c" +
+		"fb24faab423564146b41cfda71c31122f26ef08b8922056642b2430135fce86a" +
+		"8624e9f55475da383f64e3bfe4e75e00df7a14d52a12c174e81850f7e9565402" +
+		"b751b567708c74cb1ddc7083"},
+}
+
+func TestF4Jumble(t *testing.T) {
+	for _, test := range f4JumbleTests {
+		msg := testBytes(test.n, test.n)
+		want, err := hex.DecodeString(test.want)
+		if err != nil {
+			t.Fatalf("%d: %v", test.n, err)
+		}
+
+		got, err := F4Jumble(msg)
+		if err != nil {
+			t.Errorf("F4Jumble %d: %v", test.n, err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("F4Jumble %d: got %x, want %x", test.n, got, want)
+		}
+		if !bytes.Equal(msg, testBytes(test.n, test.n)) {
+			t.Errorf("F4Jumble %d: modified its input", test.n)
+		}
+
+		inv, err := F4JumbleInv(want)
+		if err != nil {
+			t.Errorf("F4JumbleInv %d: %v", test.n, err)
+			continue
+		}
+		if !bytes.Equal(inv, msg) {
+			t.Errorf("F4JumbleInv %d: got %x, want %x", test.n, inv, msg)
+		}
+	}
+}
+
+func TestF4JumbleLength(t *testing.T) {
+	tests := []struct {
+		n     int
+		valid bool
+	}{
+		{0, false},
+		{47, false},
+		{48, true},
+		{4194368, true},
+		{4194369, false},
+	}
+
+	for _, test := range tests {
+		msg := make([]byte, test.n)
+		_, err := F4Jumble(msg)
+		if (err == nil) != test.valid {
+			t.Errorf("F4Jumble %d: got error %v", test.n, err)
+		}
+		_, err = F4JumbleInv(msg)
+		if (err == nil) != test.valid {
+			t.Errorf("F4JumbleInv %d: got error %v", test.n, err)
+		}
+	}
+}
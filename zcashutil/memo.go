@@ -0,0 +1,188 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashutil
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"unicode/utf8"
+)
+
+const (
+	// MemoSize is the size in bytes of the memo field of a shielded note.
+	MemoSize = 512
+
+	// memoTextMax is the largest first byte of a text memo.
+	memoTextMax = 0xf4
+
+	// memoEmpty is the first byte of a memo that carries no data.
+	memoEmpty = 0xf6
+
+	// memoArbitrary is the first byte of a memo that carries arbitrary
+	// data in its remaining bytes.
+	memoArbitrary = 0xff
+)
+
+var (
+	// ErrMemoTooLong describes an error where a memo is longer than
+	// MemoSize bytes.
+	ErrMemoTooLong = errors.New("memo is longer than 512 bytes")
+
+	// ErrMemoInvalidText describes an error where a memo marked as text
+	// is not valid UTF-8.
+	ErrMemoInvalidText = errors.New("text memo is not valid UTF-8")
+)
+
+// MemoKind describes how the contents of a memo field are interpreted
+// according to ZIP 302.
+type MemoKind int
+
+// These constants define the kinds of memos.
+const (
+	// MemoKindEmpty is a memo with no data.
+	MemoKindEmpty MemoKind = iota
+
+	// MemoKindText is a memo holding UTF-8 text.
+	MemoKindText
+
+	// MemoKindArbitrary is a memo holding 511 bytes of data whose meaning
+	// is agreed on by the sender and recipient.
+	MemoKindArbitrary
+
+	// MemoKindReserved is a memo whose first byte is reserved for future
+	// use by ZIP 302.
+	MemoKindReserved
+)
+
+// memoKindStrings is a map of memo kinds back to their constant names for
+// pretty printing.
+var memoKindStrings = map[MemoKind]string{
+	MemoKindEmpty:     "MemoKindEmpty",
+	MemoKindText:      "MemoKindText",
+	MemoKindArbitrary: "MemoKindArbitrary",
+	MemoKindReserved:  "MemoKindReserved",
+}
+
+// String returns the MemoKind in human-readable form.
+func (k MemoKind) String() string {
+	if s, ok := memoKindStrings[k]; ok {
+		return s
+	}
+	return "Unknown MemoKind"
+}
+
+// Memo is a decoded memo field.  Text is set for text memos.  Data holds the
+// 511 bytes following the first byte of arbitrary data memos, and the whole
+// memo field of reserved memos.
+type Memo struct {
+	Kind MemoKind
+	Text string
+	Data []byte
+}
+
+// DecodeMemo interprets a memo field according to ZIP 302.  Memos shorter
+// than MemoSize bytes are treated as if padded with zeros, which is how
+// senders fill the field.
+func DecodeMemo(memo []byte) (*Memo, error) {
+	if len(memo) > MemoSize {
+		return nil, ErrMemoTooLong
+	}
+	padded := make([]byte, MemoSize)
+	copy(padded, memo)
+
+	switch {
+	case padded[0] <= memoTextMax:
+		text := bytes.TrimRight(padded, "\x00")
+		if !utf8.Valid(text) {
+			return nil, ErrMemoInvalidText
+		}
+		return &Memo{Kind: MemoKindText, Text: string(text)}, nil
+
+	case padded[0] == memoEmpty && isZero(padded[1:]):
+		return &Memo{Kind: MemoKindEmpty}, nil
+
+	case padded[0] == memoArbitrary:
+		return &Memo{Kind: MemoKindArbitrary, Data: padded[1:]}, nil
+	}
+
+	// Any other first byte, including an empty memo marker followed by
+	// data, is reserved.
+	return &Memo{Kind: MemoKindReserved, Data: padded}, nil
+}
+
+// DecodeMemoHex interprets a hex encoded memo field, as returned by zcashd,
+// according to ZIP 302.
+func DecodeMemoHex(memo string) (*Memo, error) {
+	b, err := hex.DecodeString(memo)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeMemo(b)
+}
+
+// NewTextMemo returns a memo holding the passed UTF-8 text.
+func NewTextMemo(text string) (*Memo, error) {
+	if len(text) > MemoSize {
+		return nil, ErrMemoTooLong
+	}
+	if !utf8.ValidString(text) {
+		return nil, ErrMemoInvalidText
+	}
+	return &Memo{Kind: MemoKindText, Text: text}, nil
+}
+
+// NewArbitraryMemo returns a memo holding the passed data, which may be at
+// most MemoSize-1 bytes.
+func NewArbitraryMemo(data []byte) (*Memo, error) {
+	if len(data) > MemoSize-1 {
+		return nil, ErrMemoTooLong
+	}
+	padded := make([]byte, MemoSize-1)
+	copy(padded, data)
+	return &Memo{Kind: MemoKindArbitrary, Data: padded}, nil
+}
+
+// Bytes returns the memo encoded as a MemoSize byte memo field.
+func (m *Memo) Bytes() []byte {
+	b := make([]byte, MemoSize)
+	switch m.Kind {
+	case MemoKindEmpty:
+		b[0] = memoEmpty
+	case MemoKindText:
+		copy(b, m.Text)
+	case MemoKindArbitrary:
+		b[0] = memoArbitrary
+		copy(b[1:], m.Data)
+	default:
+		copy(b, m.Data)
+	}
+	return b
+}
+
+// Hex returns the memo encoded as a hex memo field, suitable for the memo
+// arguments of RPCs such as z_sendmany.
+func (m *Memo) Hex() string {
+	return hex.EncodeToString(m.Bytes())
+}
+
+// String returns the text of text memos and an empty string for any other
+// kind of memo.
+func (m *Memo) String() string {
+	if m.Kind != MemoKindText {
+		return ""
+	}
+	return m.Text
+}
+
+// isZero returns whether every byte of b is zero.
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
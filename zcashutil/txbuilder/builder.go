@@ -0,0 +1,321 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txbuilder
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashtxscript"
+	"github.com/arithmetric/zcashrpcclient/zcashutil"
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+const (
+	// DefaultExpiryDelta is the number of blocks after which a transaction
+	// expires when no other delta is set.  It matches the default of the
+	// zcashd -txexpirydelta option.
+	DefaultExpiryDelta = 40
+
+	// MinExpiryDelta is the smallest expiry delta accepted.  zcashd does
+	// not relay transactions that expire within this many blocks.
+	MinExpiryDelta = 4
+
+	// maxExpiryHeight is the first expiry height that is not valid.
+	maxExpiryHeight = 500000000
+
+	// p2pkhScriptSize is the size of a pay-to-pubkey-hash script.
+	p2pkhScriptSize = 25
+
+	// DustThreshold is the smallest change output created.  Change below
+	// it is added to the fee instead.
+	DustThreshold btcutil.Amount = 54
+)
+
+var (
+	// ErrInsufficientFunds describes an error where the unspent outputs do
+	// not cover the outputs and the fee.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+
+	// ErrNoOutputs describes an error where a transaction is built without
+	// any outputs.
+	ErrNoOutputs = errors.New("transaction has no outputs")
+
+	// ErrNoChangeAddress describes an error where a transaction needs a
+	// change output but no change address was set.
+	ErrNoChangeAddress = errors.New("no change address")
+)
+
+// utxo is an unspent output that may be selected as an input.
+type utxo struct {
+	outPoint wire.OutPoint
+	txOut    *wire.TxOut
+}
+
+// utxosByAmount implements sort.Interface to order unspent outputs by
+// decreasing amount, falling back on the outpoint so the order is
+// deterministic.
+type utxosByAmount []utxo
+
+func (s utxosByAmount) Len() int      { return len(s) }
+func (s utxosByAmount) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s utxosByAmount) Less(i, j int) bool {
+	if s[i].txOut.Value != s[j].txOut.Value {
+		return s[i].txOut.Value > s[j].txOut.Value
+	}
+	if s[i].outPoint.Hash != s[j].outPoint.Hash {
+		return bytes.Compare(s[i].outPoint.Hash[:],
+			s[j].outPoint.Hash[:]) < 0
+	}
+	return s[i].outPoint.Index < s[j].outPoint.Index
+}
+
+// Builder builds transparent-only Zcash transactions without the help of a
+// node.  Unspent outputs are added with AddUnspent and payments with
+// AddOutput, after which Build selects inputs covering the payments and the
+// ZIP 317 conventional fee and returns the unsigned transaction.
+type Builder struct {
+	net          *zcashcfg.Params
+	height       int32
+	version      int32
+	expiryDelta  uint32
+	utxos        []utxo
+	outputs      []*wire.TxOut
+	changeScript []byte
+}
+
+// New returns a builder for transactions on the passed network.  The height
+// is that of the current chain tip as returned by getblockcount.  The
+// transaction is built for the consensus rules of the next block, using the
+// v5 format once NU5 is active and the v4 format before.
+//
+// The activation heights of the network are taken from the parameters, so the
+// parameters of a regtest node should be those returned by GetCurrentNet.
+func New(net *zcashcfg.Params, height int32) *Builder {
+	version := int32(zcashwire.SaplingTxVersion)
+	if isActive(net, height+1, zcashcfg.BranchNU5) {
+		version = zcashwire.NU5TxVersion
+	}
+	return &Builder{
+		net:         net,
+		height:      height,
+		version:     version,
+		expiryDelta: DefaultExpiryDelta,
+	}
+}
+
+// isActive returns whether the upgrade is active at the passed height.
+func isActive(net *zcashcfg.Params, height int32, branch zcashcfg.BranchID) bool {
+	activation, ok := net.ActivationHeight(branch)
+	return ok && height >= activation
+}
+
+// SetVersion sets the transaction format to zcashwire.SaplingTxVersion or
+// zcashwire.NU5TxVersion, overriding the default chosen by New.
+func (b *Builder) SetVersion(version int32) {
+	b.version = version
+}
+
+// SetExpiryDelta sets the number of blocks after the next block in which the
+// transaction expires.
+func (b *Builder) SetExpiryDelta(delta uint32) {
+	b.expiryDelta = delta
+}
+
+// SetChangeAddress sets the transparent address change is paid to.
+func (b *Builder) SetChangeAddress(addr zcashutil.Address) error {
+	if !addr.IsForNet(b.net) {
+		return fmt.Errorf("address %s is not for %s", addr, b.net.Name)
+	}
+	pkScript, err := zcashtxscript.PayToAddrScript(addr)
+	if err != nil {
+		return err
+	}
+	b.changeScript = pkScript
+	return nil
+}
+
+// AddUnspent adds unspent outputs, as returned by ListUnspent, that may be
+// selected as inputs.  Only pay-to-pubkey-hash outputs are added, since other
+// scripts cannot be signed without additional information; the others are
+// ignored.
+func (b *Builder) AddUnspent(unspent ...btcjson.ListUnspentResult) error {
+	for _, u := range unspent {
+		hash, err := chainhash.NewHashFromStr(u.TxID)
+		if err != nil {
+			return err
+		}
+		pkScript, err := hex.DecodeString(u.ScriptPubKey)
+		if err != nil {
+			return err
+		}
+		if txscript.GetScriptClass(pkScript) != txscript.PubKeyHashTy {
+			continue
+		}
+		amount, err := btcutil.NewAmount(u.Amount)
+		if err != nil {
+			return err
+		}
+
+		b.utxos = append(b.utxos, utxo{
+			outPoint: *wire.NewOutPoint(hash, u.Vout),
+			txOut:    wire.NewTxOut(int64(amount), pkScript),
+		})
+	}
+	return nil
+}
+
+// AddOutput adds an output paying the amount to the transparent address.
+func (b *Builder) AddOutput(addr zcashutil.Address, amount btcutil.Amount) error {
+	if !addr.IsForNet(b.net) {
+		return fmt.Errorf("address %s is not for %s", addr, b.net.Name)
+	}
+	if amount <= 0 {
+		return fmt.Errorf("invalid output amount %v", amount)
+	}
+	pkScript, err := zcashtxscript.PayToAddrScript(addr)
+	if err != nil {
+		return err
+	}
+	b.outputs = append(b.outputs, wire.NewTxOut(int64(amount), pkScript))
+	return nil
+}
+
+// ExpiryHeight returns the expiry height of the transaction.  It is the
+// height of the next block plus the expiry delta, lowered to the last block
+// before the next network upgrade when the delta would cross it, since the
+// transaction would be invalid under the rules of the upgrade.
+func (b *Builder) ExpiryHeight() (uint32, error) {
+	if b.expiryDelta < MinExpiryDelta {
+		return 0, fmt.Errorf("expiry delta %d is below the minimum of %d",
+			b.expiryDelta, MinExpiryDelta)
+	}
+
+	next := b.height + 1
+	expiry := uint64(next) + uint64(b.expiryDelta)
+	for _, upgrade := range b.net.Upgrades {
+		if upgrade.ActivationHeight > next {
+			if uint64(upgrade.ActivationHeight) <= expiry {
+				expiry = uint64(upgrade.ActivationHeight) - 1
+			}
+			break
+		}
+	}
+	if expiry >= maxExpiryHeight {
+		return 0, fmt.Errorf("expiry height %d is too large", expiry)
+	}
+	return uint32(expiry), nil
+}
+
+// Build selects inputs from the unspent outputs, largest first, until they
+// cover the outputs and the conventional fee, and adds a change output for
+// the remainder unless it is dust.  The returned transaction is unsigned.
+func (b *Builder) Build() (*Tx, error) {
+	if len(b.outputs) == 0 {
+		return nil, ErrNoOutputs
+	}
+
+	branchID := b.net.BranchID(b.height + 1)
+	if !isActive(b.net, b.height+1, zcashcfg.BranchSapling) {
+		return nil, fmt.Errorf("Sapling is not active at height %d",
+			b.height+1)
+	}
+	if b.version == zcashwire.NU5TxVersion &&
+		!isActive(b.net, b.height+1, zcashcfg.BranchNU5) {
+
+		return nil, fmt.Errorf("v5 transactions are not valid before NU5")
+	}
+	if b.version != zcashwire.SaplingTxVersion &&
+		b.version != zcashwire.NU5TxVersion {
+
+		return nil, fmt.Errorf("unsupported transaction version %d",
+			b.version)
+	}
+	expiry, err := b.ExpiryHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	var target btcutil.Amount
+	for _, out := range b.outputs {
+		target += btcutil.Amount(out.Value)
+	}
+
+	utxos := make([]utxo, len(b.utxos))
+	copy(utxos, b.utxos)
+	sort.Sort(utxosByAmount(utxos))
+
+	var total btcutil.Amount
+	for i, u := range utxos {
+		total += btcutil.Amount(u.txOut.Value)
+		fee := estimateFee(i+1, b.outputs)
+		if total < target+fee {
+			continue
+		}
+		return b.newTx(utxos[:i+1], total-target, branchID, expiry)
+	}
+	return nil, ErrInsufficientFunds
+}
+
+// newTx creates the transaction spending the selected unspent outputs, which
+// exceed the outputs by the passed amount.
+func (b *Builder) newTx(selected []utxo, excess btcutil.Amount,
+	branchID zcashcfg.BranchID, expiry uint32) (*Tx, error) {
+
+	msgTx := zcashwire.NewMsgTx(b.version)
+	if b.version == zcashwire.NU5TxVersion {
+		msgTx.ConsensusBranchID = uint32(branchID)
+	}
+	msgTx.ExpiryHeight = expiry
+
+	prevOuts := make([]*wire.TxOut, 0, len(selected))
+	for _, u := range selected {
+		outPoint := u.outPoint
+		msgTx.AddTxIn(wire.NewTxIn(&outPoint, nil, nil))
+		prevOuts = append(prevOuts, u.txOut)
+	}
+	for _, out := range b.outputs {
+		msgTx.AddTxOut(wire.NewTxOut(out.Value, out.PkScript))
+	}
+
+	tx := &Tx{
+		MsgTx:       msgTx,
+		PrevOuts:    prevOuts,
+		Fee:         excess,
+		ChangeIndex: -1,
+		BranchID:    branchID,
+	}
+
+	// Add change when what is left after paying the fee of a transaction
+	// including the change output is not dust.  Without a change address,
+	// a pay-to-pubkey-hash script is assumed to find out whether change is
+	// needed at all.
+	change := wire.NewTxOut(0, b.changeScript)
+	if change.PkScript == nil {
+		change.PkScript = make([]byte, p2pkhScriptSize)
+	}
+	fee := estimateFee(len(selected), append(msgTx.TxOut, change))
+	if excess-fee < DustThreshold {
+		return tx, nil
+	}
+	if b.changeScript == nil {
+		return nil, ErrNoChangeAddress
+	}
+
+	change.Value = int64(excess - fee)
+	tx.ChangeIndex = len(msgTx.TxOut)
+	tx.Fee = fee
+	msgTx.AddTxOut(change)
+	return tx, nil
+}
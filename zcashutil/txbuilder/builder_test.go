@@ -0,0 +1,441 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txbuilder
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashutil"
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+)
+
+// testKey returns the private key made of 32 copies of the passed byte.
+func testKey(b byte) *btcec.PrivateKey {
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{b}, 32))
+	return key
+}
+
+// testAddress returns the mainnet pay-to-pubkey-hash address of the passed
+// serialized public key.
+func testAddress(t *testing.T, pubKey []byte) zcashutil.Address {
+	addr, err := zcashutil.NewAddressPubKeyHash(btcutil.Hash160(pubKey),
+		&zcashcfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: %v", err)
+	}
+	return addr
+}
+
+// testUnspent returns an unspent output of the transaction whose hash is made
+// of copies of the passed byte, paying the amount to the pubkey hash of the
+// passed public key.
+func testUnspent(b byte, vout uint32, pubKey []byte, amount float64) btcjson.ListUnspentResult {
+	return btcjson.ListUnspentResult{
+		TxID: strings.Repeat(fmt.Sprintf("%02x", b), 32),
+		Vout: vout,
+		ScriptPubKey: "76a914" +
+			hex.EncodeToString(btcutil.Hash160(pubKey)) + "88ac",
+		Amount: amount,
+	}
+}
+
+func TestConventionalFee(t *testing.T) {
+	tests := []struct {
+		inSize  int
+		outSize int
+		actions int
+		fee     btcutil.Amount
+	}{
+		{0, 0, 0, 10000},
+		{150, 34, 1, 10000},
+		{151, 34, 2, 10000},
+		{300, 35, 2, 10000},
+		{450, 34, 3, 15000},
+		{150, 5 * 34, 5, 25000},
+		{180, 34, 2, 10000},
+		{3 * 180, 34, 4, 20000},
+	}
+
+	for _, test := range tests {
+		actions := LogicalActions(test.inSize, test.outSize)
+		if actions != test.actions {
+			t.Errorf("LogicalActions(%d, %d): got %d, want %d",
+				test.inSize, test.outSize, actions, test.actions)
+		}
+		if fee := ConventionalFee(actions); fee != test.fee {
+			t.Errorf("ConventionalFee(%d): got %v, want %v", actions,
+				fee, test.fee)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	pubKey := testKey(1).PubKey().SerializeCompressed()
+	addr := testAddress(t, pubKey)
+	p2sh := testUnspent(0xdd, 0, pubKey, 9)
+	p2sh.ScriptPubKey = "a914" + hex.EncodeToString(btcutil.Hash160(pubKey)) +
+		"87"
+	unspent := []btcjson.ListUnspentResult{
+		testUnspent(0xcc, 0, pubKey, 0.001),
+		testUnspent(0xaa, 0, pubKey, 0.5),
+		p2sh,
+		testUnspent(0xbb, 1, pubKey, 0.3),
+	}
+
+	tests := []struct {
+		name     string
+		outputs  []btcutil.Amount
+		noChange bool
+		inputs   []byte
+		fee      btcutil.Amount
+		change   int64
+		err      error
+	}{
+		{
+			name:    "largest first",
+			outputs: []btcutil.Amount{40000000},
+			inputs:  []byte{0xaa},
+			fee:     10000,
+			change:  9990000,
+		},
+		{
+			name:    "second input",
+			outputs: []btcutil.Amount{60000000},
+			inputs:  []byte{0xaa, 0xbb},
+			fee:     10000,
+			change:  19990000,
+		},
+		{
+			// The fee grows with the third input, which still
+			// covers it.
+			name:    "third input",
+			outputs: []btcutil.Amount{80000000},
+			inputs:  []byte{0xaa, 0xbb, 0xcc},
+			fee:     15000,
+			change:  85000,
+		},
+		{
+			name:    "insufficient funds",
+			outputs: []btcutil.Amount{80090000},
+			err:     ErrInsufficientFunds,
+		},
+		{
+			name:    "fee of outputs",
+			outputs: []btcutil.Amount{10000000, 10000000, 10000000},
+			inputs:  []byte{0xaa},
+			fee:     20000,
+			change:  19980000,
+		},
+		{
+			name:    "exact",
+			outputs: []btcutil.Amount{49990000},
+			inputs:  []byte{0xaa},
+			fee:     10000,
+			change:  -1,
+		},
+		{
+			name:    "dust added to the fee",
+			outputs: []btcutil.Amount{49990000 - DustThreshold + 1},
+			inputs:  []byte{0xaa},
+			fee:     10000 + DustThreshold - 1,
+			change:  -1,
+		},
+		{
+			name:    "smallest change",
+			outputs: []btcutil.Amount{49990000 - DustThreshold},
+			inputs:  []byte{0xaa},
+			fee:     10000,
+			change:  int64(DustThreshold),
+		},
+		{
+			name:     "dust without change address",
+			outputs:  []btcutil.Amount{49990000 - DustThreshold + 1},
+			noChange: true,
+			inputs:   []byte{0xaa},
+			fee:      10000 + DustThreshold - 1,
+			change:   -1,
+		},
+		{
+			name:     "change without change address",
+			outputs:  []btcutil.Amount{49990000 - DustThreshold},
+			noChange: true,
+			err:      ErrNoChangeAddress,
+		},
+		{
+			name: "no outputs",
+			err:  ErrNoOutputs,
+		},
+	}
+
+	for _, test := range tests {
+		b := New(&zcashcfg.MainNetParams, 3000000)
+		if err := b.AddUnspent(unspent...); err != nil {
+			t.Fatalf("AddUnspent: %v", err)
+		}
+		for _, amount := range test.outputs {
+			if err := b.AddOutput(addr, amount); err != nil {
+				t.Fatalf("%s: AddOutput: %v", test.name, err)
+			}
+		}
+		if !test.noChange {
+			if err := b.SetChangeAddress(addr); err != nil {
+				t.Fatalf("%s: SetChangeAddress: %v", test.name, err)
+			}
+		}
+
+		tx, err := b.Build()
+		if err != test.err {
+			t.Errorf("%s: got error %v, want %v", test.name, err,
+				test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		msgTx := tx.MsgTx
+		if len(msgTx.TxIn) != len(test.inputs) ||
+			len(tx.PrevOuts) != len(test.inputs) {
+
+			t.Errorf("%s: got %d inputs, want %d", test.name,
+				len(msgTx.TxIn), len(test.inputs))
+			continue
+		}
+		for i, b := range test.inputs {
+			if msgTx.TxIn[i].PreviousOutPoint.Hash[0] != b {
+				t.Errorf("%s: input %d spends %v", test.name, i,
+					msgTx.TxIn[i].PreviousOutPoint)
+			}
+		}
+		if tx.Fee != test.fee {
+			t.Errorf("%s: got fee %v, want %v", test.name, tx.Fee,
+				test.fee)
+		}
+
+		var in, out int64
+		for _, prevOut := range tx.PrevOuts {
+			in += prevOut.Value
+		}
+		for _, txOut := range msgTx.TxOut {
+			out += txOut.Value
+		}
+		if in-out != int64(tx.Fee) {
+			t.Errorf("%s: inputs exceed outputs by %d, not the fee",
+				test.name, in-out)
+		}
+
+		if test.change < 0 {
+			if tx.ChangeIndex != -1 ||
+				len(msgTx.TxOut) != len(test.outputs) {
+
+				t.Errorf("%s: got change output %d", test.name,
+					tx.ChangeIndex)
+			}
+			continue
+		}
+		if tx.ChangeIndex != len(test.outputs) ||
+			len(msgTx.TxOut) != len(test.outputs)+1 {
+
+			t.Errorf("%s: got change output %d of %d", test.name,
+				tx.ChangeIndex, len(msgTx.TxOut))
+			continue
+		}
+		if value := msgTx.TxOut[tx.ChangeIndex].Value; value != test.change {
+			t.Errorf("%s: got change %d, want %d", test.name, value,
+				test.change)
+		}
+	}
+}
+
+func TestExpiryHeight(t *testing.T) {
+	// NU6 activates at 2726400 on mainnet.
+	tests := []struct {
+		height int32
+		delta  uint32
+		expiry uint32
+		err    bool
+	}{
+		{3000000, DefaultExpiryDelta, 3000041, false},
+		{2726400 - 42, DefaultExpiryDelta, 2726399, false},
+		{2726400 - 41, DefaultExpiryDelta, 2726399, false},
+		{2726400 - 10, DefaultExpiryDelta, 2726399, false},
+		{2726400 - 1, DefaultExpiryDelta, 2726440, false},
+		{2726400 - 2, MinExpiryDelta, 2726399, false},
+		{3000000, MinExpiryDelta, 3000005, false},
+		{3000000, MinExpiryDelta - 1, 0, true},
+		{3000000, maxExpiryHeight - 3000001, 0, true},
+	}
+
+	for _, test := range tests {
+		b := New(&zcashcfg.MainNetParams, test.height)
+		b.SetExpiryDelta(test.delta)
+		expiry, err := b.ExpiryHeight()
+		if (err != nil) != test.err {
+			t.Errorf("height %d delta %d: got error %v", test.height,
+				test.delta, err)
+			continue
+		}
+		if expiry != test.expiry {
+			t.Errorf("height %d delta %d: got expiry %d, want %d",
+				test.height, test.delta, expiry, test.expiry)
+		}
+	}
+
+	// The capped expiry height is the one the transaction is built with.
+	pubKey := testKey(1).PubKey().SerializeCompressed()
+	b := New(&zcashcfg.MainNetParams, 2726400-10)
+	b.AddUnspent(testUnspent(0xaa, 0, pubKey, 0.5))
+	b.AddOutput(testAddress(t, pubKey), 49990000)
+	tx, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if tx.MsgTx.ExpiryHeight != 2726399 {
+		t.Errorf("got expiry height %d, want 2726399",
+			tx.MsgTx.ExpiryHeight)
+	}
+	if tx.BranchID != zcashcfg.BranchNU5 ||
+		tx.MsgTx.ConsensusBranchID != uint32(zcashcfg.BranchNU5) {
+
+		t.Errorf("got branch ID %v", tx.BranchID)
+	}
+}
+
+func TestBuildVersion(t *testing.T) {
+	// NU5 activates at 1687104 and Sapling at 419200 on mainnet.
+	tests := []struct {
+		height  int32
+		version int32
+		setV5   bool
+		err     bool
+	}{
+		{1687103, zcashwire.NU5TxVersion, false, false},
+		{1687102, zcashwire.SaplingTxVersion, false, false},
+		{1687102, zcashwire.NU5TxVersion, true, true},
+		{419199, zcashwire.SaplingTxVersion, false, false},
+		{419198, zcashwire.SaplingTxVersion, false, true},
+	}
+
+	pubKey := testKey(1).PubKey().SerializeCompressed()
+	addr := testAddress(t, pubKey)
+	for _, test := range tests {
+		b := New(&zcashcfg.MainNetParams, test.height)
+		if test.setV5 {
+			b.SetVersion(zcashwire.NU5TxVersion)
+		}
+		b.AddUnspent(testUnspent(0xaa, 0, pubKey, 0.5))
+		b.AddOutput(addr, 49990000)
+		tx, err := b.Build()
+		if (err != nil) != test.err {
+			t.Errorf("height %d: got error %v", test.height, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if tx.MsgTx.Version != test.version {
+			t.Errorf("height %d: got version %d, want %d",
+				test.height, tx.MsgTx.Version, test.version)
+		}
+	}
+}
+
+func TestAddSignature(t *testing.T) {
+	key := testKey(1)
+	compressed := key.PubKey().SerializeCompressed()
+	uncompressed := key.PubKey().SerializeUncompressed()
+
+	// Three inputs are paid for as three logical actions, which those
+	// signed with an uncompressed key would exceed.
+	for _, pubKey := range [][]byte{compressed, uncompressed} {
+		b := New(&zcashcfg.MainNetParams, 3000000)
+		for i := byte(0); i < 3; i++ {
+			b.AddUnspent(testUnspent(0xa0+i, 0, pubKey, 0.001))
+		}
+		b.AddOutput(testAddress(t, pubKey), 300000-15000)
+		tx, err := b.Build()
+		if err != nil {
+			t.Fatalf("Build: %v", err)
+		}
+		if len(tx.MsgTx.TxIn) != 3 || tx.Fee != 15000 {
+			t.Fatalf("got %d inputs and fee %v", len(tx.MsgTx.TxIn),
+				tx.Fee)
+		}
+
+		for i := range tx.MsgTx.TxIn {
+			hash, err := tx.SignatureHash(i, txscript.SigHashAll)
+			if err != nil {
+				t.Fatalf("SignatureHash: %v", err)
+			}
+			sig, err := key.Sign(hash)
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+			sigBytes := append(sig.Serialize(), byte(txscript.SigHashAll))
+			err = tx.AddSignature(i, sigBytes, pubKey)
+			if len(pubKey) != len(compressed) {
+				if err == nil {
+					t.Fatalf("uncompressed public key accepted")
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("AddSignature: %v", err)
+			}
+		}
+		if len(pubKey) != len(compressed) {
+			continue
+		}
+
+		// The fee covers the signed transaction.
+		var inSize, outSize int
+		for _, txIn := range tx.MsgTx.TxIn {
+			inSize += txIn.SerializeSize()
+		}
+		for _, txOut := range tx.MsgTx.TxOut {
+			outSize += txOut.SerializeSize()
+		}
+		if fee := ConventionalFee(LogicalActions(inSize, outSize)); fee > tx.Fee {
+			t.Errorf("signed transaction needs fee %v, pays %v", fee,
+				tx.Fee)
+		}
+		if _, err := tx.Hex(); err != nil {
+			t.Errorf("Hex: %v", err)
+		}
+	}
+
+	// Signatures are only accepted for the key of the spent output, and
+	// when they fit the size the fee was computed for.
+	b := New(&zcashcfg.MainNetParams, 3000000)
+	b.AddUnspent(testUnspent(0xaa, 0, compressed, 0.5))
+	b.AddOutput(testAddress(t, compressed), 49990000)
+	tx, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	sig := make([]byte, maxSigSize)
+	other := testKey(2).PubKey().SerializeCompressed()
+	if err := tx.AddSignature(0, sig, other); err == nil {
+		t.Errorf("public key of another output accepted")
+	}
+	if err := tx.AddSignature(0, append(sig, 1), compressed); err == nil {
+		t.Errorf("signature larger than %d bytes accepted", maxSigSize)
+	}
+	if err := tx.AddSignature(1, sig, compressed); err == nil {
+		t.Errorf("input out of range accepted")
+	}
+	if err := tx.AddSignature(0, sig, compressed); err != nil {
+		t.Errorf("AddSignature: %v", err)
+	}
+}
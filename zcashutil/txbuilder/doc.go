@@ -0,0 +1,54 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package txbuilder builds transparent-only Zcash transactions offline.
+
+createrawtransaction leaves the expiry height and fee to the node, and the
+btcsuite wallet packages know nothing of either.  A Builder is given the
+unspent outputs returned by ListUnspent and the payments to make, and creates
+a transaction for the next block:
+
+  - the format is v5 once NU5 is active and v4 before, so the transaction
+    commits to the consensus branch ID of the next block
+  - the expiry height is the next block height plus the expiry delta, or the
+    block before the next network upgrade when the delta would cross it, as
+    zcashd does
+  - inputs are selected largest first until they cover the payments and the
+    ZIP 317 conventional fee, and the remainder is paid to the change address
+    unless it is dust
+
+The fee is computed from the ZIP 317 logical actions of the transaction, which
+for transparent transactions is the larger of the number of standard sized
+inputs and outputs, with a minimum of GraceActions.  ConventionalFee and
+LogicalActions expose that computation.
+
+The returned Tx carries the outputs spent by its inputs, so the signature hash
+of each input can be computed with SignatureHash and signed by an external
+signer.  Once AddSignature has been called for every input, Hex returns the
+transaction in the encoding accepted by sendrawtransaction:
+
+	b := txbuilder.New(&zcashcfg.MainNetParams, height)
+	if err := b.AddUnspent(unspent...); err != nil {
+		return err
+	}
+	if err := b.AddOutput(addr, amount); err != nil {
+		return err
+	}
+	if err := b.SetChangeAddress(changeAddr); err != nil {
+		return err
+	}
+	tx, err := b.Build()
+	if err != nil {
+		return err
+	}
+	for i := range tx.MsgTx.TxIn {
+		hash, err := tx.SignatureHash(i, txscript.SigHashAll)
+		...
+		err = tx.AddSignature(i, sig, pubKey)
+		...
+	}
+	txHex, err := tx.Hex()
+*/
+package txbuilder
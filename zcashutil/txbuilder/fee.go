@@ -0,0 +1,76 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txbuilder
+
+import (
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+// Constants of the ZIP 317 conventional fee.
+const (
+	// MarginalFee is the fee paid for each logical action.
+	MarginalFee btcutil.Amount = 5000
+
+	// GraceActions is the number of logical actions every transaction pays
+	// for, even if it has fewer.
+	GraceActions = 2
+
+	// P2PKHStandardInputSize is the size of the transparent input data
+	// counted as one logical action.
+	P2PKHStandardInputSize = 150
+
+	// P2PKHStandardOutputSize is the size of the transparent output data
+	// counted as one logical action.
+	P2PKHStandardOutputSize = 34
+)
+
+const (
+	// maxSigSize is the size of the largest DER encoded signature with
+	// the hash type appended.
+	maxSigSize = 73
+
+	// compressedPubKeySize is the size of a compressed public key.  With a
+	// signature no larger than maxSigSize, an input spending a
+	// pay-to-pubkey-hash output with it does not exceed
+	// P2PKHStandardInputSize, which an uncompressed key would.
+	compressedPubKeySize = 33
+)
+
+// LogicalActions returns the number of ZIP 317 logical actions of a
+// transparent-only transaction whose inputs and outputs serialize to the
+// passed total sizes.
+func LogicalActions(txInTotalSize, txOutTotalSize int) int {
+	ins := (txInTotalSize + P2PKHStandardInputSize - 1) /
+		P2PKHStandardInputSize
+	outs := (txOutTotalSize + P2PKHStandardOutputSize - 1) /
+		P2PKHStandardOutputSize
+	if ins > outs {
+		return ins
+	}
+	return outs
+}
+
+// ConventionalFee returns the ZIP 317 conventional fee of a transaction with
+// the passed number of logical actions.
+func ConventionalFee(logicalActions int) btcutil.Amount {
+	if logicalActions < GraceActions {
+		logicalActions = GraceActions
+	}
+	return MarginalFee * btcutil.Amount(logicalActions)
+}
+
+// estimateFee returns the conventional fee of a transaction spending the
+// passed number of pay-to-pubkey-hash inputs to the passed outputs.
+// AddSignature only accepts signatures and public keys which keep a signed
+// input within P2PKHStandardInputSize, so each input is counted at that size.
+func estimateFee(numInputs int, outputs []*wire.TxOut) btcutil.Amount {
+	txOutTotalSize := 0
+	for _, out := range outputs {
+		txOutTotalSize += out.SerializeSize()
+	}
+	return ConventionalFee(LogicalActions(
+		numInputs*P2PKHStandardInputSize, txOutTotalSize))
+}
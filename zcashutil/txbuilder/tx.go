@@ -0,0 +1,114 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txbuilder
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashtxscript"
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+// Tx is a transaction created by a Builder along with the outputs it spends,
+// which are needed to sign it.
+type Tx struct {
+	// MsgTx is the transaction.  Its inputs are unsigned until a
+	// signature is added for each of them with AddSignature.
+	MsgTx *zcashwire.MsgTx
+
+	// PrevOuts holds the output spent by each input in order.
+	PrevOuts []*wire.TxOut
+
+	// Fee is the fee paid by the transaction.
+	Fee btcutil.Amount
+
+	// ChangeIndex is the index of the change output, or -1 when the
+	// transaction has no change.
+	ChangeIndex int
+
+	// BranchID is the consensus branch ID the transaction is signed for.
+	BranchID zcashcfg.BranchID
+
+	sigHashes *zcashtxscript.TxSigHashes
+}
+
+// SignatureHash returns the hash the signature of input idx commits to for
+// the passed hash type.
+func (tx *Tx) SignatureHash(idx int, hashType txscript.SigHashType) ([]byte, error) {
+	if tx.sigHashes == nil {
+		sigHashes, err := zcashtxscript.NewTxSigHashes(tx.MsgTx,
+			tx.BranchID, tx.PrevOuts)
+		if err != nil {
+			return nil, err
+		}
+		tx.sigHashes = sigHashes
+	}
+	if idx < 0 || idx >= len(tx.PrevOuts) {
+		return nil, fmt.Errorf("input index %d is out of range for %d "+
+			"inputs", idx, len(tx.PrevOuts))
+	}
+
+	prevOut := tx.PrevOuts[idx]
+	return zcashtxscript.CalcSignatureHash(prevOut.PkScript, tx.sigHashes,
+		hashType, tx.MsgTx, idx, prevOut.Value)
+}
+
+// AddSignature sets the signature script of input idx from the DER encoded
+// signature, with the hash type appended, and the compressed public key whose
+// hash the spent output pays to.  Uncompressed public keys are rejected, since
+// the fee of the transaction was computed for inputs signed with compressed
+// keys and would fall below the conventional fee.
+func (tx *Tx) AddSignature(idx int, sig, pubKey []byte) error {
+	if idx < 0 || idx >= len(tx.PrevOuts) {
+		return fmt.Errorf("input index %d is out of range for %d "+
+			"inputs", idx, len(tx.PrevOuts))
+	}
+	if len(sig) > maxSigSize {
+		return fmt.Errorf("signature of %d bytes is larger than %d bytes",
+			len(sig), maxSigSize)
+	}
+	if len(pubKey) != compressedPubKeySize ||
+		(pubKey[0] != 0x02 && pubKey[0] != 0x03) {
+
+		return fmt.Errorf("public key for input %d is not compressed", idx)
+	}
+
+	// The pubkey hash of a pay-to-pubkey-hash script follows the
+	// OP_DUP OP_HASH160 OP_DATA_20 prefix.
+	pkScript := tx.PrevOuts[idx].PkScript
+	if txscript.GetScriptClass(pkScript) != txscript.PubKeyHashTy {
+		return fmt.Errorf("input %d does not spend a pay-to-pubkey-hash "+
+			"output", idx)
+	}
+	if !bytes.Equal(btcutil.Hash160(pubKey), pkScript[3:23]) {
+		return fmt.Errorf("public key does not match the output spent "+
+			"by input %d", idx)
+	}
+
+	sigScript, err := txscript.NewScriptBuilder().AddData(sig).
+		AddData(pubKey).Script()
+	if err != nil {
+		return err
+	}
+	tx.MsgTx.TxIn[idx].SignatureScript = sigScript
+	return nil
+}
+
+// Hex returns the hex encoded serialization of the transaction, as accepted
+// by sendrawtransaction.
+func (tx *Tx) Hex() (string, error) {
+	var buf bytes.Buffer
+	buf.Grow(tx.MsgTx.SerializeSize())
+	if err := tx.MsgTx.Serialize(&buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf.Bytes()), nil
+}
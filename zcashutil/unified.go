@@ -0,0 +1,578 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashutil/bech32"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// unifiedPaddingSize is the size of the human-readable part padding appended
+// to the items of a unified encoding before it is jumbled.
+const unifiedPaddingSize = 16
+
+var (
+	// ErrUnifiedNoShielded describes an error where a unified address or
+	// viewing key does not contain any shielded item.
+	ErrUnifiedNoShielded = errors.New("unified encoding must contain a " +
+		"shielded item")
+
+	// ErrUnifiedTransparentPair describes an error where a unified
+	// address or viewing key contains both a P2PKH and a P2SH item.
+	ErrUnifiedTransparentPair = errors.New("unified encoding must not " +
+		"contain both P2PKH and P2SH items")
+
+	// errUnifiedItemOrder describes an error where the items of a unified
+	// encoding are not in strictly ascending typecode order.
+	errUnifiedItemOrder = errors.New("unified items must be in " +
+		"ascending typecode order")
+)
+
+// UnifiedTypecode identifies the kind of an item in a unified address or
+// viewing key as defined by ZIP 316.
+type UnifiedTypecode uint64
+
+// These constants define the typecodes of the receivers and viewing keys
+// known to this package.  Unified encodings may carry items with other
+// typecodes, which are preserved but not interpreted.
+const (
+	UnifiedTypeP2PKH   UnifiedTypecode = 0x00
+	UnifiedTypeP2SH    UnifiedTypecode = 0x01
+	UnifiedTypeSapling UnifiedTypecode = 0x02
+	UnifiedTypeOrchard UnifiedTypecode = 0x03
+)
+
+// Map of unified typecodes back to their constant names for pretty printing.
+var unifiedTypecodeStrings = map[UnifiedTypecode]string{
+	UnifiedTypeP2PKH:   "p2pkh",
+	UnifiedTypeP2SH:    "p2sh",
+	UnifiedTypeSapling: "sapling",
+	UnifiedTypeOrchard: "orchard",
+}
+
+// String returns the UnifiedTypecode in human-readable form.  The names match
+// the keys zcashd uses in z_listunifiedreceivers results.
+func (t UnifiedTypecode) String() string {
+	if s, ok := unifiedTypecodeStrings[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("unknown(%#x)", uint64(t))
+}
+
+// IsTransparent returns whether the typecode identifies a transparent item.
+func (t UnifiedTypecode) IsTransparent() bool {
+	return t == UnifiedTypeP2PKH || t == UnifiedTypeP2SH
+}
+
+// UnifiedItem is a single receiver of a unified address, or a single
+// component key of a unified viewing key.
+type UnifiedItem struct {
+	Typecode UnifiedTypecode
+	Data     []byte
+}
+
+// unifiedItemsByTypecode implements sort.Interface to order unified items by
+// ascending typecode.
+type unifiedItemsByTypecode []UnifiedItem
+
+func (s unifiedItemsByTypecode) Len() int           { return len(s) }
+func (s unifiedItemsByTypecode) Less(i, j int) bool { return s[i].Typecode < s[j].Typecode }
+func (s unifiedItemsByTypecode) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// unifiedKind identifies which of the unified encodings defined by ZIP 316 an
+// encoding is.
+type unifiedKind int
+
+const (
+	unifiedAddressKind unifiedKind = iota
+	unifiedFullViewingKeyKind
+	unifiedIncomingViewingKeyKind
+)
+
+// unifiedItemSizes holds the sizes of the known items of each kind.  Known
+// typecodes missing from a kind's map are not permitted for that kind.
+var unifiedItemSizes = map[unifiedKind]map[UnifiedTypecode]int{
+	unifiedAddressKind: {
+		UnifiedTypeP2PKH:   ripemd160Size,
+		UnifiedTypeP2SH:    ripemd160Size,
+		UnifiedTypeSapling: SaplingPaymentAddrSize,
+		UnifiedTypeOrchard: OrchardRawAddrSize,
+	},
+	unifiedFullViewingKeyKind: {
+		UnifiedTypeP2PKH:   65,
+		UnifiedTypeSapling: 128,
+		UnifiedTypeOrchard: 96,
+	},
+	unifiedIncomingViewingKeyKind: {
+		UnifiedTypeP2PKH:   65,
+		UnifiedTypeSapling: 64,
+		UnifiedTypeOrchard: 64,
+	},
+}
+
+// String returns the unifiedKind in human-readable form.
+func (k unifiedKind) String() string {
+	switch k {
+	case unifiedFullViewingKeyKind:
+		return "unified full viewing key"
+	case unifiedIncomingViewingKeyKind:
+		return "unified incoming viewing key"
+	}
+	return "unified address"
+}
+
+// hrp returns the human-readable part for the kind on the passed network.
+func (k unifiedKind) hrp(net *zcashcfg.Params) string {
+	switch k {
+	case unifiedFullViewingKeyKind:
+		return net.UnifiedFullViewingKeyHRP
+	case unifiedIncomingViewingKeyKind:
+		return net.UnifiedIncomingViewingKeyHRP
+	}
+	return net.UnifiedAddrHRP
+}
+
+// validateUnifiedItems checks the items, which must already be in ascending
+// typecode order, against the ZIP 316 rules for the kind.
+func validateUnifiedItems(items []UnifiedItem, kind unifiedKind) error {
+	sizes := unifiedItemSizes[kind]
+	var hasP2PKH, hasP2SH, hasShielded bool
+	for _, item := range items {
+		switch item.Typecode {
+		case UnifiedTypeP2PKH:
+			hasP2PKH = true
+		case UnifiedTypeP2SH:
+			hasP2SH = true
+		default:
+			hasShielded = true
+		}
+
+		if item.Typecode > UnifiedTypeOrchard {
+			continue
+		}
+		size, ok := sizes[item.Typecode]
+		if !ok {
+			return fmt.Errorf("%v must not contain a %v item", kind,
+				item.Typecode)
+		}
+		if len(item.Data) != size {
+			return fmt.Errorf("%v %v item must be %d bytes, not %d",
+				kind, item.Typecode, size, len(item.Data))
+		}
+	}
+
+	if hasP2PKH && hasP2SH {
+		return ErrUnifiedTransparentPair
+	}
+	if !hasShielded {
+		return ErrUnifiedNoShielded
+	}
+	return nil
+}
+
+// unifiedPadding returns the human-readable part zero-padded to 16 bytes.
+func unifiedPadding(hrp string) ([]byte, error) {
+	if len(hrp) > unifiedPaddingSize {
+		return nil, fmt.Errorf("human-readable part %q is longer than "+
+			"%d bytes", hrp, unifiedPaddingSize)
+	}
+	padding := make([]byte, unifiedPaddingSize)
+	copy(padding, hrp)
+	return padding, nil
+}
+
+// serializeUnifiedItems encodes the items in the order given, which must be
+// ascending by typecode.
+func serializeUnifiedItems(items []UnifiedItem) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, item := range items {
+		if i > 0 && item.Typecode <= items[i-1].Typecode {
+			return nil, errUnifiedItemOrder
+		}
+		err := wire.WriteVarInt(&buf, 0, uint64(item.Typecode))
+		if err != nil {
+			return nil, err
+		}
+		if err := wire.WriteVarBytes(&buf, 0, item.Data); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// parseUnifiedItems decodes raw unified items, checking that typecodes are
+// unique and in ascending order.
+func parseUnifiedItems(raw []byte) ([]UnifiedItem, error) {
+	var items []UnifiedItem
+	r := bytes.NewReader(raw)
+	for r.Len() > 0 {
+		typecode, err := wire.ReadVarInt(r, 0)
+		if err != nil {
+			return nil, err
+		}
+		data, err := wire.ReadVarBytes(r, 0, uint32(r.Len()),
+			"unified item")
+		if err != nil {
+			return nil, err
+		}
+		tc := UnifiedTypecode(typecode)
+		if len(items) > 0 && tc <= items[len(items)-1].Typecode {
+			return nil, errUnifiedItemOrder
+		}
+		items = append(items, UnifiedItem{Typecode: tc, Data: data})
+	}
+	return items, nil
+}
+
+// newUnifiedItems returns a sorted copy of the items after checking them
+// against the ZIP 316 rules for the kind.
+func newUnifiedItems(items []UnifiedItem, kind unifiedKind) ([]UnifiedItem, error) {
+	sorted := make([]UnifiedItem, len(items))
+	for i, item := range items {
+		data := make([]byte, len(item.Data))
+		copy(data, item.Data)
+		sorted[i] = UnifiedItem{Typecode: item.Typecode, Data: data}
+	}
+	sort.Sort(unifiedItemsByTypecode(sorted))
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Typecode == sorted[i-1].Typecode {
+			return nil, fmt.Errorf("duplicate %v item",
+				sorted[i].Typecode)
+		}
+	}
+	if err := validateUnifiedItems(sorted, kind); err != nil {
+		return nil, err
+	}
+	return sorted, nil
+}
+
+// encodeUnified encodes the serialized items with the ZIP 316 encoding: the
+// human-readable part padding is appended, the result is jumbled with
+// F4Jumble and then encoded with bech32m.
+func encodeUnified(hrp string, raw []byte) (string, error) {
+	padding, err := unifiedPadding(hrp)
+	if err != nil {
+		return "", err
+	}
+	msg := make([]byte, 0, len(raw)+len(padding))
+	msg = append(msg, raw...)
+	msg = append(msg, padding...)
+
+	jumbled, err := F4Jumble(msg)
+	if err != nil {
+		return "", err
+	}
+	return bech32.EncodeFromBase256(hrp, jumbled, bech32.Bech32m)
+}
+
+// decodeUnified reverses encodeUnified, returning the human-readable part and
+// the serialized items after checking the padding.
+func decodeUnified(s string) (string, []byte, error) {
+	hrp, data, version, err := bech32.DecodeToBase256(s)
+	if err != nil {
+		return "", nil, err
+	}
+	if version != bech32.Bech32m {
+		return "", nil, fmt.Errorf("unified encoding must use %v, not %v",
+			bech32.Bech32m, version)
+	}
+
+	msg, err := F4JumbleInv(data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	padding, err := unifiedPadding(hrp)
+	if err != nil {
+		return "", nil, err
+	}
+	raw := msg[:len(msg)-unifiedPaddingSize]
+	if !bytes.Equal(msg[len(raw):], padding) {
+		return "", nil, fmt.Errorf("invalid unified encoding padding")
+	}
+	return hrp, raw, nil
+}
+
+// decodeUnifiedKind decodes and validates a unified encoding of the given
+// kind, returning the network it is for along with its items.
+func decodeUnifiedKind(s string, kind unifiedKind) (*zcashcfg.Params, []UnifiedItem, error) {
+	hrp, raw, err := decodeUnified(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	net, err := zcashcfg.ParamsForUnifiedHRP(hrp)
+	if err != nil {
+		return nil, nil, err
+	}
+	if kind.hrp(net) != hrp {
+		return nil, nil, fmt.Errorf("%q is not the human-readable "+
+			"part of a %v", hrp, kind)
+	}
+
+	items, err := parseUnifiedItems(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validateUnifiedItems(items, kind); err != nil {
+		return nil, nil, err
+	}
+	return net, items, nil
+}
+
+// encodeUnifiedKind encodes the items, which must already be validated, as a
+// unified encoding of the given kind for the passed network.
+func encodeUnifiedKind(items []UnifiedItem, kind unifiedKind, net *zcashcfg.Params) string {
+	// The only possible errors are an invalid human-readable part,
+	// misordered items or too short of an encoding, none of which are
+	// possible for validated items.
+	raw, _ := serializeUnifiedItems(items)
+	s, _ := encodeUnified(kind.hrp(net), raw)
+	return s
+}
+
+// findUnifiedItem returns the data of the item with the typecode, if any.
+func findUnifiedItem(items []UnifiedItem, typecode UnifiedTypecode) ([]byte, bool) {
+	for _, item := range items {
+		if item.Typecode == typecode {
+			return item.Data, true
+		}
+	}
+	return nil, false
+}
+
+// AddressUnified is an Address for a ZIP 316 unified address, which bundles
+// receivers for several value pools into a single address.
+type AddressUnified struct {
+	// items holds the serialized receivers.  It is a string rather than
+	// a slice so the type is comparable.
+	items string
+	net   *zcashcfg.Params
+}
+
+// NewAddressUnified returns a new AddressUnified with the passed receivers.
+// The receivers may be given in any order and are checked against the ZIP 316
+// rules: there must be at least one shielded receiver, at most one of P2PKH
+// and P2SH, no duplicate typecodes and every known receiver must have the
+// correct size.
+func NewAddressUnified(receivers []UnifiedItem, net *zcashcfg.Params) (*AddressUnified, error) {
+	items, err := newUnifiedItems(receivers, unifiedAddressKind)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := serializeUnifiedItems(items)
+	if err != nil {
+		return nil, err
+	}
+	return &AddressUnified{items: string(raw), net: net}, nil
+}
+
+// decodeUnifiedAddress decodes the bech32m encoding of a unified address.
+func decodeUnifiedAddress(s string) (*AddressUnified, error) {
+	net, items, err := decodeUnifiedKind(s, unifiedAddressKind)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := serializeUnifiedItems(items)
+	if err != nil {
+		return nil, err
+	}
+	return &AddressUnified{items: string(raw), net: net}, nil
+}
+
+// EncodeAddress returns the string encoding of a unified address.  Part of
+// the Address interface.
+func (a *AddressUnified) EncodeAddress() string {
+	// The only possible errors are an invalid human-readable part or too
+	// short of an encoding, neither of which is possible for an address
+	// created by this package.
+	s, _ := encodeUnified(a.net.UnifiedAddrHRP, []byte(a.items))
+	return s
+}
+
+// ScriptAddress returns the serialized receivers of the unified address
+// before padding and jumbling.  Part of the Address interface.
+func (a *AddressUnified) ScriptAddress() []byte {
+	return []byte(a.items)
+}
+
+// IsForNet returns whether or not the unified address is associated with the
+// passed Zcash network.
+func (a *AddressUnified) IsForNet(net *zcashcfg.Params) bool {
+	return a.net.UnifiedAddrHRP == net.UnifiedAddrHRP
+}
+
+// String returns a human-readable string for the unified address.  This is
+// equivalent to calling EncodeAddress, but is provided so the type can be
+// used as a fmt.Stringer.
+func (a *AddressUnified) String() string {
+	return a.EncodeAddress()
+}
+
+// Items returns every receiver of the unified address, including those with
+// typecodes unknown to this package, in ascending typecode order.
+func (a *AddressUnified) Items() []UnifiedItem {
+	// The items were validated when the address was created.
+	items, _ := parseUnifiedItems([]byte(a.items))
+	return items
+}
+
+// Receiver returns the raw receiver with the passed typecode and whether the
+// unified address contains one.
+func (a *AddressUnified) Receiver(typecode UnifiedTypecode) ([]byte, bool) {
+	return findUnifiedItem(a.Items(), typecode)
+}
+
+// UnifiedReceivers holds the receivers of a unified address that are known to
+// this package, each as an Address that can be paid directly.  Receivers the
+// unified address does not contain are nil.  It mirrors the result of the
+// z_listunifiedreceivers RPC.
+type UnifiedReceivers struct {
+	P2PKH   *AddressPubKeyHash
+	P2SH    *AddressScriptHash
+	Sapling *AddressSapling
+
+	// Orchard receivers have no encoding of their own, so the Orchard
+	// receiver is given as a unified address containing only it.
+	Orchard *AddressUnified
+}
+
+// ListReceivers returns the known receivers of the unified address, which
+// agree with those reported by zcashd's z_listunifiedreceivers.
+func (a *AddressUnified) ListReceivers() *UnifiedReceivers {
+	var r UnifiedReceivers
+	for _, item := range a.Items() {
+		// The receiver sizes were validated when the address was
+		// created, so the constructors can not fail.
+		switch item.Typecode {
+		case UnifiedTypeP2PKH:
+			r.P2PKH, _ = NewAddressPubKeyHash(item.Data, a.net)
+		case UnifiedTypeP2SH:
+			r.P2SH, _ = NewAddressScriptHashFromHash(item.Data, a.net)
+		case UnifiedTypeSapling:
+			r.Sapling, _ = NewAddressSapling(item.Data, a.net)
+		case UnifiedTypeOrchard:
+			r.Orchard, _ = NewAddressUnified([]UnifiedItem{item}, a.net)
+		}
+	}
+	return &r
+}
+
+// UnifiedFullViewingKey is a ZIP 316 unified full viewing key, which bundles
+// full viewing keys for several value pools.
+type UnifiedFullViewingKey struct {
+	items []UnifiedItem
+	net   *zcashcfg.Params
+}
+
+// NewUnifiedFullViewingKey returns a new UnifiedFullViewingKey with the
+// passed component keys after checking them against the ZIP 316 rules.
+func NewUnifiedFullViewingKey(keys []UnifiedItem, net *zcashcfg.Params) (*UnifiedFullViewingKey, error) {
+	items, err := newUnifiedItems(keys, unifiedFullViewingKeyKind)
+	if err != nil {
+		return nil, err
+	}
+	return &UnifiedFullViewingKey{items: items, net: net}, nil
+}
+
+// DecodeUnifiedFullViewingKey decodes the bech32m encoding of a unified full
+// viewing key such as those returned by z_exportviewingkey.
+func DecodeUnifiedFullViewingKey(s string) (*UnifiedFullViewingKey, error) {
+	net, items, err := decodeUnifiedKind(s, unifiedFullViewingKeyKind)
+	if err != nil {
+		return nil, err
+	}
+	return &UnifiedFullViewingKey{items: items, net: net}, nil
+}
+
+// Encode returns the string encoding of the unified full viewing key.
+func (k *UnifiedFullViewingKey) Encode() string {
+	return encodeUnifiedKind(k.items, unifiedFullViewingKeyKind, k.net)
+}
+
+// String returns the string encoding of the unified full viewing key.
+func (k *UnifiedFullViewingKey) String() string {
+	return k.Encode()
+}
+
+// IsForNet returns whether or not the unified full viewing key is associated
+// with the passed Zcash network.
+func (k *UnifiedFullViewingKey) IsForNet(net *zcashcfg.Params) bool {
+	return k.net.UnifiedFullViewingKeyHRP == net.UnifiedFullViewingKeyHRP
+}
+
+// Items returns every component key of the unified full viewing key in
+// ascending typecode order.
+func (k *UnifiedFullViewingKey) Items() []UnifiedItem {
+	return k.items
+}
+
+// Key returns the raw component key with the passed typecode and whether the
+// unified full viewing key contains one.
+func (k *UnifiedFullViewingKey) Key(typecode UnifiedTypecode) ([]byte, bool) {
+	return findUnifiedItem(k.items, typecode)
+}
+
+// UnifiedIncomingViewingKey is a ZIP 316 unified incoming viewing key, which
+// bundles incoming viewing keys for several value pools.
+type UnifiedIncomingViewingKey struct {
+	items []UnifiedItem
+	net   *zcashcfg.Params
+}
+
+// NewUnifiedIncomingViewingKey returns a new UnifiedIncomingViewingKey with
+// the passed component keys after checking them against the ZIP 316 rules.
+func NewUnifiedIncomingViewingKey(keys []UnifiedItem, net *zcashcfg.Params) (*UnifiedIncomingViewingKey, error) {
+	items, err := newUnifiedItems(keys, unifiedIncomingViewingKeyKind)
+	if err != nil {
+		return nil, err
+	}
+	return &UnifiedIncomingViewingKey{items: items, net: net}, nil
+}
+
+// DecodeUnifiedIncomingViewingKey decodes the bech32m encoding of a unified
+// incoming viewing key.
+func DecodeUnifiedIncomingViewingKey(s string) (*UnifiedIncomingViewingKey, error) {
+	net, items, err := decodeUnifiedKind(s, unifiedIncomingViewingKeyKind)
+	if err != nil {
+		return nil, err
+	}
+	return &UnifiedIncomingViewingKey{items: items, net: net}, nil
+}
+
+// Encode returns the string encoding of the unified incoming viewing key.
+func (k *UnifiedIncomingViewingKey) Encode() string {
+	return encodeUnifiedKind(k.items, unifiedIncomingViewingKeyKind, k.net)
+}
+
+// String returns the string encoding of the unified incoming viewing key.
+func (k *UnifiedIncomingViewingKey) String() string {
+	return k.Encode()
+}
+
+// IsForNet returns whether or not the unified incoming viewing key is
+// associated with the passed Zcash network.
+func (k *UnifiedIncomingViewingKey) IsForNet(net *zcashcfg.Params) bool {
+	return k.net.UnifiedIncomingViewingKeyHRP ==
+		net.UnifiedIncomingViewingKeyHRP
+}
+
+// Items returns every component key of the unified incoming viewing key in
+// ascending typecode order.
+func (k *UnifiedIncomingViewingKey) Items() []UnifiedItem {
+	return k.items
+}
+
+// Key returns the raw component key with the passed typecode and whether the
+// unified incoming viewing key contains one.
+func (k *UnifiedIncomingViewingKey) Key(typecode UnifiedTypecode) ([]byte, bool) {
+	return findUnifiedItem(k.items, typecode)
+}
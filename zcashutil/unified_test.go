@@ -0,0 +1,342 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+)
+
+// unifiedTests are encodings of testBytes items, computed from the ZIP 316
+// specification independently of this package.
+var unifiedTests = []struct {
+	name    string
+	kind    unifiedKind
+	net     *zcashcfg.Params
+	items   []UnifiedItem
+	encoded string
+}{
+	{
+		name: "unified address",
+		kind: unifiedAddressKind,
+		net:  &zcashcfg.MainNetParams,
+		items: []UnifiedItem{
+			{UnifiedTypeP2PKH, testBytes(20, 1)},
+			{UnifiedTypeSapling, testBytes(43, 2)},
+			{UnifiedTypeOrchard, testBytes(43, 3)},
+		},
+		encoded: "u1wnk9e5cr2nxvgauzc4fkwn4xmhaskxjrpltn3674gfgt9csq6g02fdfe5nspkm" +
+			"fazpj7jjtuyyv0gf6fwumexhh3dk58x62s6qvf4xkde8yakzzfmc5l3r0rarzath" +
+			"j5fdzu6mffztdy307ap9kj0p7uc8gglcndrzpngz3a0fzd56da23ec2xs8jyztvr" +
+			"ugjze6qedydt53xsqwek9",
+	},
+	{
+		name: "unified address with unknown item",
+		kind: unifiedAddressKind,
+		net:  &zcashcfg.TestNetParams,
+		items: []UnifiedItem{
+			{UnifiedTypeOrchard, testBytes(43, 4)},
+			{0x1234, testBytes(5, 5)},
+		},
+		encoded: "utest14knckcl2f3ch2e439dz2tuluvwtzap83vphchy7ltfjp9eyuwevcrfm80v" +
+			"3dn7tpq2g74neqr5l0wfmghwd5np88z8w3uqykevlnuxkk4l3sggejrz3fwn",
+	},
+	{
+		name: "unified address with p2sh",
+		kind: unifiedAddressKind,
+		net:  &zcashcfg.RegressionNetParams,
+		items: []UnifiedItem{
+			{UnifiedTypeP2SH, testBytes(20, 6)},
+			{UnifiedTypeSapling, testBytes(43, 7)},
+		},
+		encoded: "uregtest14jlrffm5m9zpdtt9a8eq99z5tu3s8ejxfmfz0nhe7jr50g8d747te6t" +
+			"k4c53vv9nf3vyukakm4t6wwh3nf2hm3dj2rdmksde4ndahp9fkcvkntj42wyu38e" +
+			"r5nm8slkh42kwgcu74qv",
+	},
+	{
+		name: "unified full viewing key",
+		kind: unifiedFullViewingKeyKind,
+		net:  &zcashcfg.MainNetParams,
+		items: []UnifiedItem{
+			{UnifiedTypeSapling, testBytes(128, 8)},
+			{UnifiedTypeOrchard, testBytes(96, 9)},
+		},
+		encoded: "uview1dx8zlx5g0md2pg9x9ejelvn9ama8yesf58xl4xfumltawmynakzu4gmshq" +
+			"u39uj94pvz23dzhxqqx04w7n7xqxk5jcxu4v8jytgdfz0dqdn6l4wfl93ywfh2gy" +
+			"gd0dns87w5p6dep5wfuxq3ra8308ywh0s5zedhllnjy8juc5lnsvq0u7epk7zgem" +
+			"acrfpnrhkh7c5h2dksz5wg959ltrdprvmv884pnlv6twu7jx7wwcnqk9z6pzylt3" +
+			"t4l9wv8xh38e9lgqhstahxlrfq79t6kc4mm9guv473w838fyl36l9lmgz4n42vpd" +
+			"7l4y2h8ljzu0mg784a4dw2x9emwcpe2pdna4ec5s76pggf2st79zyvdpxqemjjfz" +
+			"swx6v6q0eyp9c9fzdu6",
+	},
+	{
+		name: "unified full viewing key with p2pkh",
+		kind: unifiedFullViewingKeyKind,
+		net:  &zcashcfg.TestNetParams,
+		items: []UnifiedItem{
+			{UnifiedTypeP2PKH, testBytes(65, 10)},
+			{UnifiedTypeOrchard, testBytes(96, 11)},
+		},
+		encoded: "uviewtest143gpds6pr0uhu6hw6mx9traw0458mwhzj30d204ctga654yahgt9rg" +
+			"m7xq5qh8rmr0tvjn4eqkx9l3jjvlj7zjkfrsh9lfzg20l4dru7sz9v4y0erfhm7q" +
+			"9s2tkcyr0agtxfr0z3xqyrm3xn9fcvp0ysd8plk2httpu6tqahf35j3vzu4qekwc" +
+			"l83flsgk7n8tzmw8lmx22dkknatt0x27rpr74c4kr6hyxrchgrs4ywdckgj95mf8" +
+			"66nsum3latq83rxteyddagn7g4dkxpumyxwjwv3fuzc5np2ula",
+	},
+	{
+		name: "unified incoming viewing key",
+		kind: unifiedIncomingViewingKeyKind,
+		net:  &zcashcfg.MainNetParams,
+		items: []UnifiedItem{
+			{UnifiedTypeSapling, testBytes(64, 12)},
+			{UnifiedTypeOrchard, testBytes(64, 13)},
+		},
+		encoded: "uivk1dwyuzga9fzdyx0xufu5jv5j3ctpcjk8v6aq9jdc87vak9nwq8mch8rlm653" +
+			"685w5cnv4sr773mcvsuuh6394y3g75pzaasz5mqtw0y7et9hm405ae9pyjgus4cv" +
+			"7a8duxxlzncxfg4nt4r3a765n2udnxh5jdlfunv7p8x6wumkwwxjdmd4ajsgud33" +
+			"rl3lqedurlq0txr6535fulnpgzt06050fa9zyeenhx3qsejlluhkvzs5",
+	},
+	{
+		name: "unified incoming viewing key with p2pkh",
+		kind: unifiedIncomingViewingKeyKind,
+		net:  &zcashcfg.RegressionNetParams,
+		items: []UnifiedItem{
+			{UnifiedTypeP2PKH, testBytes(65, 14)},
+			{UnifiedTypeSapling, testBytes(64, 15)},
+		},
+		encoded: "uivkregtest1v74lhuxp0tu3ntrlwyggtfvdfhvg30fm7rru329n8f4hh9u6qqc9" +
+			"evs62z3g07nyadf4pqzwplwy6tqmvmll7ncnlk6tsmlqzk4j90j7e5ta0cz5nfda" +
+			"m7kvmn2uveua8nx80whtsq9evauw00wu5h3q3466sn06yk2aj230mp2tkgcrr2nh" +
+			"u74y323hr4fyea4vhg88ygmdcxc2naz0l2at0g24jpk6zm8v5xnncfwpfjsv6s03" +
+			"k",
+	},
+}
+
+// encodeUnifiedTest encodes the items as the kind using the exported API.
+func encodeUnifiedTest(items []UnifiedItem, kind unifiedKind, net *zcashcfg.Params) (string, error) {
+	switch kind {
+	case unifiedAddressKind:
+		a, err := NewAddressUnified(items, net)
+		if err != nil {
+			return "", err
+		}
+		return a.EncodeAddress(), nil
+	case unifiedFullViewingKeyKind:
+		k, err := NewUnifiedFullViewingKey(items, net)
+		if err != nil {
+			return "", err
+		}
+		return k.Encode(), nil
+	default:
+		k, err := NewUnifiedIncomingViewingKey(items, net)
+		if err != nil {
+			return "", err
+		}
+		return k.Encode(), nil
+	}
+}
+
+// decodeUnifiedTest decodes the string as the kind using the exported API,
+// returning whether it is for the network along with its items.
+func decodeUnifiedTest(s string, kind unifiedKind, net *zcashcfg.Params) (bool, []UnifiedItem, error) {
+	switch kind {
+	case unifiedAddressKind:
+		addr, err := DecodeAddress(s)
+		if err != nil {
+			return false, nil, err
+		}
+		a, ok := addr.(*AddressUnified)
+		if !ok {
+			return false, nil, errors.New("not a unified address")
+		}
+		return a.IsForNet(net), a.Items(), nil
+	case unifiedFullViewingKeyKind:
+		k, err := DecodeUnifiedFullViewingKey(s)
+		if err != nil {
+			return false, nil, err
+		}
+		return k.IsForNet(net), k.Items(), nil
+	default:
+		k, err := DecodeUnifiedIncomingViewingKey(s)
+		if err != nil {
+			return false, nil, err
+		}
+		return k.IsForNet(net), k.Items(), nil
+	}
+}
+
+func TestUnifiedEncoding(t *testing.T) {
+	kinds := []unifiedKind{
+		unifiedAddressKind,
+		unifiedFullViewingKeyKind,
+		unifiedIncomingViewingKeyKind,
+	}
+
+	for _, test := range unifiedTests {
+		// Items are sorted by typecode when encoding.
+		reversed := make([]UnifiedItem, len(test.items))
+		for i, item := range test.items {
+			reversed[len(reversed)-1-i] = item
+		}
+		s, err := encodeUnifiedTest(reversed, test.kind, test.net)
+		if err != nil {
+			t.Errorf("%s: encode: %v", test.name, err)
+			continue
+		}
+		if s != test.encoded {
+			t.Errorf("%s: got %s, want %s", test.name, s, test.encoded)
+		}
+
+		forNet, items, err := decodeUnifiedTest(test.encoded, test.kind,
+			test.net)
+		if err != nil {
+			t.Errorf("%s: decode: %v", test.name, err)
+			continue
+		}
+		if !forNet {
+			t.Errorf("%s: not for %s", test.name, test.net.Name)
+		}
+		if !reflect.DeepEqual(items, test.items) {
+			t.Errorf("%s: got items %v, want %v", test.name, items,
+				test.items)
+		}
+
+		other := &zcashcfg.MainNetParams
+		if test.net == other {
+			other = &zcashcfg.TestNetParams
+		}
+		forNet, _, _ = decodeUnifiedTest(test.encoded, test.kind, other)
+		if forNet {
+			t.Errorf("%s: for %s", test.name, other.Name)
+		}
+
+		// Each kind of encoding has its own human-readable part.
+		for _, kind := range kinds {
+			if kind == test.kind {
+				continue
+			}
+			_, _, err := decodeUnifiedTest(test.encoded, kind, test.net)
+			if err == nil {
+				t.Errorf("%s: decoded as a %v", test.name, kind)
+			}
+		}
+	}
+}
+
+func TestUnifiedEncodingInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    unifiedKind
+		encoded string
+		err     error
+	}{
+		{
+			name: "padding of another network",
+			encoded: "utest1vrqfn8zly6yhlm9xzr3h4rvydqjggyqfpyqys2c43c9ucpj7dcg2nfusjt" +
+				"zxz8mr6w2z2jau0wsznurnfq8gwawhuhsp9h9t6s242n09",
+		},
+		{
+			name: "bech32 checksum",
+			encoded: "u1vrqfn8zly6yhlm9xzr3h4rvydqjggyqfpyqys2c43c9ucpj7dcg2nfusjtzxz8" +
+				"mr6w2z2jau0wsznurnfq8gwawhuhsp9h9t6szhdgc2",
+		},
+		{
+			name: "misordered items",
+			encoded: "u1769zq83f3gjzv26yn3z7m0vjdj79y8j7qwlekhpkz5g7q0yvj54h9tkrptqkl8" +
+				"s9yckqv8vn8h4e380n7qqxqeg35s0chee0rkv9nzkujh3j555hgm2njg7sv3szg9" +
+				"h306zkz4hutsfra7xjl75zua4ajw8vvrrrwakxsycrc565hkgp",
+			err: errUnifiedItemOrder,
+		},
+		{
+			name: "too short",
+			encoded: "u13g30gl49lmvawnz0lpawvy8cjxgvzkra8yzjswge4ac57ug4mqt3l5gnfa7zw4" +
+				"3j0q5",
+		},
+		{
+			name: "no shielded key",
+			kind: unifiedFullViewingKeyKind,
+			encoded: "uview16gqx3x57a0wzwzph4q02c340cr8vfw9gvu8mk0e8f0yan5ps9jaz0tzuzl" +
+				"t4ynvug4s6aemmqt5vlexnmvmjwjpd9d06dpxgkhl4dk7wfr4tql4mas8pcxyrew" +
+				"nhhyxq5zrr25rnxag",
+			err: ErrUnifiedNoShielded,
+		},
+	}
+
+	for _, test := range tests {
+		_, _, err := decodeUnifiedTest(test.encoded, test.kind,
+			&zcashcfg.MainNetParams)
+		if err == nil {
+			t.Errorf("%s: got no error", test.name)
+			continue
+		}
+		if test.err != nil && err != test.err {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
+func TestNewUnifiedInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		kind  unifiedKind
+		items []UnifiedItem
+		err   error
+	}{
+		{
+			name:  "empty",
+			kind:  unifiedAddressKind,
+			items: nil,
+			err:   ErrUnifiedNoShielded,
+		},
+		{
+			name: "transparent pair",
+			kind: unifiedAddressKind,
+			items: []UnifiedItem{
+				{UnifiedTypeP2PKH, testBytes(20, 0)},
+				{UnifiedTypeP2SH, testBytes(20, 0)},
+				{UnifiedTypeSapling, testBytes(43, 0)},
+			},
+			err: ErrUnifiedTransparentPair,
+		},
+		{
+			name: "duplicate typecode",
+			kind: unifiedAddressKind,
+			items: []UnifiedItem{
+				{UnifiedTypeSapling, testBytes(43, 0)},
+				{UnifiedTypeSapling, testBytes(43, 1)},
+			},
+		},
+		{
+			name: "wrong size",
+			kind: unifiedFullViewingKeyKind,
+			items: []UnifiedItem{
+				{UnifiedTypeSapling, testBytes(64, 0)},
+			},
+		},
+		{
+			name: "p2sh viewing key",
+			kind: unifiedIncomingViewingKeyKind,
+			items: []UnifiedItem{
+				{UnifiedTypeP2SH, testBytes(65, 0)},
+				{UnifiedTypeOrchard, testBytes(64, 0)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		_, err := encodeUnifiedTest(test.items, test.kind,
+			&zcashcfg.MainNetParams)
+		if err == nil {
+			t.Errorf("%s: got no error", test.name)
+			continue
+		}
+		if test.err != nil && err != test.err {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package walletdump reads and writes the wallet dump files created by the
+z_exportwallet RPC and consumed by z_importwallet.
+
+A dump starts with a commented header describing the node that created it, the
+best block at the time and the seeds the wallet derives keys from: the ZIP 32
+mnemonic seed on newer nodes and the legacy HD seed.  It is followed by one line
+per transparent key, holding the key in WIF, its creation time and whether it
+has a label or belongs to the key pool or change, and one line per Sprout
+spending key and Sapling extended spending key, with the Sapling keys derived
+from the HD seed annotated with their ZIP 32 key path.
+
+Parse returns these as typed records, which can be inspected, filtered and
+checked against a network with Validate before being written back out with
+Write.  Comments other than the header and key annotations are not preserved,
+which matches how z_importwallet ignores them.
+
+Note that a dump holds every spending key of the wallet in plain text.
+*/
+package walletdump
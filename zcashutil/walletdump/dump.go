@@ -0,0 +1,425 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletdump
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+)
+
+const (
+	// timeFormat is the format of the times in a dump, which are always
+	// in UTC.
+	timeFormat = "2006-01-02T15:04:05Z"
+
+	// endOfDump is the line that terminates a complete dump.
+	endOfDump = "# End of dump"
+
+	// saplingKeyPrefix is the start of the human-readable part of every
+	// Sapling extended spending key.
+	saplingKeyPrefix = "secret-extended-key-"
+)
+
+// ParseError describes a line of a dump that could not be parsed.
+type ParseError struct {
+	Line        int
+	Description string
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e ParseError) Error() string {
+	return fmt.Sprintf("wallet dump line %d: %s", e.Line, e.Description)
+}
+
+// Header holds the information in the comments at the start of a dump.  Fields
+// the dump does not contain are empty.
+type Header struct {
+	// Version is the name and version of the node that created the dump.
+	Version string
+
+	// Created is the time the dump was created.
+	Created time.Time
+
+	// BestBlockHeight, BestBlockHash and BestBlockTime describe the best
+	// block at the time the dump was created.
+	BestBlockHeight int32
+	BestBlockHash   string
+	BestBlockTime   time.Time
+
+	// Mnemonic is the ZIP 32 recovery phrase of the wallet, along with its
+	// language and seed fingerprint.
+	Mnemonic            string
+	MnemonicLanguage    string
+	MnemonicFingerprint string
+
+	// HDSeed is the hex encoded legacy HD seed of the wallet, along with
+	// its fingerprint.
+	HDSeed            string
+	HDSeedFingerprint string
+}
+
+// TransparentKey is a transparent private key of a dump.  At most one of
+// Label, Reserve and Change is set.
+type TransparentKey struct {
+	WIF     *btcutil.WIF
+	Created time.Time
+
+	// Label is the address book label of the key's address, which may be
+	// empty.  Nil means the address is not in the address book.
+	Label *string
+
+	// Reserve is set for keys in the key pool and Change for keys of
+	// change addresses.
+	Reserve bool
+	Change  bool
+
+	// Address is the address of the key as annotated by the node.
+	Address string
+
+	// HDKeyPath and Fingerprint are set for keys derived from a seed.
+	HDKeyPath   string
+	Fingerprint string
+}
+
+// SproutKey is a Sprout spending key of a dump.
+type SproutKey struct {
+	SpendingKey string
+	Created     time.Time
+	Address     string
+}
+
+// SaplingKey is a Sapling extended spending key of a dump.  HDKeyPath and
+// Fingerprint are empty for keys imported with z_importkey.
+type SaplingKey struct {
+	ExtendedSpendingKey string
+	Created             time.Time
+	Address             string
+	HDKeyPath           string
+	Fingerprint         string
+}
+
+// Dump is a parsed wallet dump.
+type Dump struct {
+	Header          Header
+	TransparentKeys []TransparentKey
+	SproutKeys      []SproutKey
+	SaplingKeys     []SaplingKey
+
+	// Complete is whether the dump ends with the line the node writes
+	// after the last key.  Dumps missing it may have been truncated.
+	Complete bool
+}
+
+// Parse reads a wallet dump.
+func Parse(r io.Reader) (*Dump, error) {
+	d := &Dump{}
+	section := ""
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "":
+			section = ""
+			continue
+
+		case text == endOfDump:
+			d.Complete = true
+			continue
+
+		case strings.HasPrefix(text, "#"):
+			section = d.Header.parseComment(text, section)
+			continue
+		}
+
+		if err := d.parseKey(text); err != nil {
+			return nil, ParseError{Line: line, Description: err.Error()}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// parseComment records the header information in the comment and returns the
+// section of the header following it.
+func (h *Header) parseComment(text, section string) string {
+	text = strings.TrimSpace(strings.TrimPrefix(text, "#"))
+
+	switch {
+	case strings.HasPrefix(text, "Wallet dump created by "):
+		h.Version = strings.TrimPrefix(text, "Wallet dump created by ")
+
+	case strings.HasPrefix(text, "* Created on "):
+		h.Created, _ = time.Parse(timeFormat,
+			strings.TrimPrefix(text, "* Created on "))
+
+	case strings.HasPrefix(text, "* Best block at time of backup was "):
+		// The format is "<height> (<hash>),".
+		fields := strings.Fields(strings.TrimPrefix(text,
+			"* Best block at time of backup was "))
+		if len(fields) == 2 {
+			height, err := strconv.ParseInt(fields[0], 10, 32)
+			if err == nil {
+				h.BestBlockHeight = int32(height)
+			}
+			h.BestBlockHash = strings.Trim(fields[1], "(),")
+		}
+
+	case strings.HasPrefix(text, "mined on "):
+		h.BestBlockTime, _ = time.Parse(timeFormat,
+			strings.TrimPrefix(text, "mined on "))
+
+	case strings.HasPrefix(text, "HDSeed="):
+		// Older nodes write the legacy seed on a single line.
+		for _, field := range strings.Fields(text) {
+			key, value := splitField(field)
+			switch key {
+			case "HDSeed":
+				h.HDSeed = value
+			case "fingerprint":
+				h.HDSeedFingerprint = value
+			}
+		}
+
+	case text == "Emergency Recovery Information:":
+		return "mnemonic"
+
+	case text == "Legacy HD Seed:":
+		return "legacy"
+
+	case strings.HasPrefix(text, "- "):
+		key, value := splitField(strings.TrimPrefix(text, "- "))
+		switch section + "/" + key {
+		case "mnemonic/recovery_phrase":
+			h.Mnemonic = strings.Trim(value, `"`)
+		case "mnemonic/language":
+			h.MnemonicLanguage = value
+		case "mnemonic/fingerprint":
+			h.MnemonicFingerprint = value
+		case "legacy/seed":
+			h.HDSeed = value
+		case "legacy/fingerprint":
+			h.HDSeedFingerprint = value
+		}
+	}
+	return section
+}
+
+// parseKey parses a key line and adds the key to the dump.
+func (d *Dump) parseKey(text string) error {
+	// Key lines have the form "<key> <time> [<flag>] # <annotations>".
+	// Labels may contain a '#', but their spaces are encoded.
+	var annotations []string
+	if i := strings.Index(text, " # "); i >= 0 {
+		annotations = strings.Fields(text[i+3:])
+		text = text[:i]
+	}
+	fields := strings.Fields(text)
+	if len(fields) < 2 {
+		return fmt.Errorf("missing key creation time")
+	}
+
+	created, err := time.Parse(timeFormat, fields[1])
+	if err != nil {
+		return fmt.Errorf("invalid key creation time %q", fields[1])
+	}
+
+	notes := make(map[string]string, len(annotations))
+	for _, annotation := range annotations {
+		key, value := splitField(annotation)
+		notes[key] = value
+	}
+	fingerprint := notes["fingerprint"]
+	if fingerprint == "" {
+		fingerprint = notes["seedfp"]
+	}
+
+	key := fields[0]
+	switch {
+	case strings.HasPrefix(key, saplingKeyPrefix):
+		d.SaplingKeys = append(d.SaplingKeys, SaplingKey{
+			ExtendedSpendingKey: key,
+			Created:             created,
+			Address:             notes["zaddr"],
+			HDKeyPath:           notes["hdkeypath"],
+			Fingerprint:         fingerprint,
+		})
+		return nil
+
+	case notes["zaddr"] != "":
+		d.SproutKeys = append(d.SproutKeys, SproutKey{
+			SpendingKey: key,
+			Created:     created,
+			Address:     notes["zaddr"],
+		})
+		return nil
+	}
+
+	wif, err := btcutil.DecodeWIF(key)
+	if err != nil {
+		return fmt.Errorf("invalid transparent key: %v", err)
+	}
+	tk := TransparentKey{
+		WIF:         wif,
+		Created:     created,
+		Address:     notes["addr"],
+		HDKeyPath:   notes["hdkeypath"],
+		Fingerprint: fingerprint,
+	}
+	for _, field := range fields[2:] {
+		name, value := splitField(field)
+		switch name {
+		case "label":
+			label, err := decodeDumpString(value)
+			if err != nil {
+				return err
+			}
+			tk.Label = &label
+		case "reserve":
+			tk.Reserve = value == "1"
+		case "change":
+			tk.Change = value == "1"
+		}
+	}
+	d.TransparentKeys = append(d.TransparentKeys, tk)
+	return nil
+}
+
+// Write writes the dump in the format created by z_exportwallet.
+func (d *Dump) Write(w io.Writer) error {
+	var buf bytes.Buffer
+
+	h := &d.Header
+	fmt.Fprintf(&buf, "# Wallet dump created by %s\n", h.Version)
+	fmt.Fprintf(&buf, "# * Created on %s\n", formatTime(h.Created))
+	fmt.Fprintf(&buf, "# * Best block at time of backup was %d (%s),\n",
+		h.BestBlockHeight, h.BestBlockHash)
+	fmt.Fprintf(&buf, "#   mined on %s\n", formatTime(h.BestBlockTime))
+	buf.WriteString("\n")
+
+	if h.Mnemonic != "" {
+		buf.WriteString("# Emergency Recovery Information:\n")
+		fmt.Fprintf(&buf, "# - recovery_phrase=\"%s\"\n", h.Mnemonic)
+		fmt.Fprintf(&buf, "# - language=%s\n", h.MnemonicLanguage)
+		fmt.Fprintf(&buf, "# - fingerprint=%s\n", h.MnemonicFingerprint)
+		buf.WriteString("\n")
+		if h.HDSeed != "" {
+			buf.WriteString("# Legacy HD Seed:\n")
+			fmt.Fprintf(&buf, "# - seed=%s\n", h.HDSeed)
+			fmt.Fprintf(&buf, "# - fingerprint=%s\n",
+				h.HDSeedFingerprint)
+			buf.WriteString("\n")
+		}
+	} else if h.HDSeed != "" {
+		fmt.Fprintf(&buf, "# HDSeed=%s fingerprint=%s\n", h.HDSeed,
+			h.HDSeedFingerprint)
+		buf.WriteString("\n")
+	}
+
+	for i := range d.TransparentKeys {
+		k := &d.TransparentKeys[i]
+		fmt.Fprintf(&buf, "%s %s", k.WIF.String(), formatTime(k.Created))
+		switch {
+		case k.Label != nil:
+			fmt.Fprintf(&buf, " label=%s", encodeDumpString(*k.Label))
+		case k.Reserve:
+			buf.WriteString(" reserve=1")
+		case k.Change:
+			buf.WriteString(" change=1")
+		}
+		fmt.Fprintf(&buf, " # addr=%s", k.Address)
+		writeDerivation(&buf, k.HDKeyPath, k.Fingerprint)
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+
+	for i := range d.SproutKeys {
+		k := &d.SproutKeys[i]
+		fmt.Fprintf(&buf, "%s %s # zaddr=%s\n", k.SpendingKey,
+			formatTime(k.Created), k.Address)
+	}
+	for i := range d.SaplingKeys {
+		k := &d.SaplingKeys[i]
+		fmt.Fprintf(&buf, "%s %s # zaddr=%s", k.ExtendedSpendingKey,
+			formatTime(k.Created), k.Address)
+		writeDerivation(&buf, k.HDKeyPath, k.Fingerprint)
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+	buf.WriteString(endOfDump + "\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeDerivation writes the key path annotations of keys derived from a
+// seed.
+func writeDerivation(buf *bytes.Buffer, hdKeyPath, fingerprint string) {
+	if hdKeyPath == "" {
+		return
+	}
+	fmt.Fprintf(buf, " hdkeypath=%s", hdKeyPath)
+	if fingerprint != "" {
+		fmt.Fprintf(buf, " fingerprint=%s", fingerprint)
+	}
+}
+
+// formatTime formats the time the way the node does.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// splitField splits a "key=value" field.  The value is empty when there is no
+// equals sign.
+func splitField(field string) (string, string) {
+	if i := strings.IndexByte(field, '='); i >= 0 {
+		return field[:i], field[i+1:]
+	}
+	return field, ""
+}
+
+// encodeDumpString percent-encodes the characters of a label that can not
+// appear in a dump line, as the node does.
+func encodeDumpString(s string) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= 32 || c >= 128 || c == '%' {
+			fmt.Fprintf(&buf, "%%%02x", c)
+		} else {
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String()
+}
+
+// decodeDumpString reverses encodeDumpString.
+func decodeDumpString(s string) (string, error) {
+	var buf bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '%' && i+2 < len(s) {
+			v, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			if err != nil {
+				return "", fmt.Errorf("invalid label %q", s)
+			}
+			buf.WriteByte(byte(v))
+			i += 2
+			continue
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletdump
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+)
+
+// dumpTestAddress is the address annotated on the transparent keys of the test
+// dumps.  Parse and Write do not check it.
+const dumpTestAddress = "t1Hsc1LR8yKnbbe3twRp88p6vFfC5t7DLbs"
+
+// newTestWIF returns a WIF for the private key whose bytes all have the passed
+// value.
+func newTestWIF(t *testing.T, b byte) *btcutil.WIF {
+	priv, _ := btcec.PrivKeyFromBytes(btcec.S256(),
+		bytes.Repeat([]byte{b}, 32))
+	wif, err := btcutil.NewWIF(priv, &chaincfg.MainNetParams, true)
+	if err != nil {
+		t.Fatalf("NewWIF: %v", err)
+	}
+	return wif
+}
+
+func TestParseLabel(t *testing.T) {
+	wif := newTestWIF(t, 1)
+	tests := []struct {
+		name  string
+		field string
+		label string
+	}{
+		{"plain", "label=savings", "savings"},
+		{"empty", "label=", ""},
+		{"encoded", "label=my%20label%25", "my label%"},
+		{"hash", "label=a#b", "a#b"},
+		{"leading hash", "label=#1", "#1"},
+	}
+
+	for _, test := range tests {
+		line := fmt.Sprintf("%s 2023-01-01T00:00:00Z %s # addr=%s "+
+			"hdkeypath=m/0'/0'/1' fingerprint=ab", wif, test.field,
+			dumpTestAddress)
+		d, err := Parse(strings.NewReader(line))
+		if err != nil {
+			t.Errorf("%s: Parse: %v", test.name, err)
+			continue
+		}
+		if len(d.TransparentKeys) != 1 {
+			t.Errorf("%s: got %d keys, want 1", test.name,
+				len(d.TransparentKeys))
+			continue
+		}
+		k := &d.TransparentKeys[0]
+		if k.Label == nil || *k.Label != test.label {
+			t.Errorf("%s: got label %v, want %q", test.name,
+				k.Label, test.label)
+		}
+		if k.Address != dumpTestAddress || k.HDKeyPath != "m/0'/0'/1'" ||
+			k.Fingerprint != "ab" {
+
+			t.Errorf("%s: got annotations %q %q %q", test.name,
+				k.Address, k.HDKeyPath, k.Fingerprint)
+		}
+	}
+}
+
+func TestWriteParse(t *testing.T) {
+	labels := []string{"", "a#b", "# x # y", "tab\\there", "50%", "naïve"}
+	d := &Dump{Complete: true}
+	for i := range labels {
+		d.TransparentKeys = append(d.TransparentKeys, TransparentKey{
+			WIF:     newTestWIF(t, byte(i+1)),
+			Label:   &labels[i],
+			Address: dumpTestAddress,
+		})
+	}
+	d.TransparentKeys = append(d.TransparentKeys, TransparentKey{
+		WIF:     newTestWIF(t, 100),
+		Reserve: true,
+		Address: dumpTestAddress,
+	})
+
+	var buf bytes.Buffer
+	if err := d.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	parsed, err := Parse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !parsed.Complete {
+		t.Errorf("Parse: dump is not complete")
+	}
+	if len(parsed.TransparentKeys) != len(d.TransparentKeys) {
+		t.Fatalf("Parse: got %d keys, want %d",
+			len(parsed.TransparentKeys), len(d.TransparentKeys))
+	}
+	for i, k := range parsed.TransparentKeys {
+		want := &d.TransparentKeys[i]
+		if k.WIF.String() != want.WIF.String() {
+			t.Errorf("key %d: got %s, want %s", i, k.WIF, want.WIF)
+		}
+		switch {
+		case want.Label == nil:
+			if k.Label != nil {
+				t.Errorf("key %d: got label %q, want none", i,
+					*k.Label)
+			}
+		case k.Label == nil || *k.Label != *want.Label:
+			t.Errorf("key %d: got label %v, want %q", i, k.Label,
+				*want.Label)
+		}
+		if k.Reserve != want.Reserve || k.Address != want.Address {
+			t.Errorf("key %d: got %+v, want %+v", i, k, *want)
+		}
+	}
+
+	// Writing the parsed dump gives the same dump back.
+	var rewritten bytes.Buffer
+	if err := parsed.Write(&rewritten); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if rewritten.String() != buf.String() {
+		t.Errorf("rewritten dump differs:\n%s\nwant:\n%s",
+			rewritten.String(), buf.String())
+	}
+}
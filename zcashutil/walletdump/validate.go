@@ -0,0 +1,112 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletdump
+
+import (
+	"fmt"
+
+	"github.com/arithmetric/zcashrpcclient/zcashcfg"
+	"github.com/arithmetric/zcashrpcclient/zcashutil"
+	"github.com/arithmetric/zcashrpcclient/zcashutil/bech32"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+)
+
+const (
+	// sproutSpendingKeySize is the size of a Sprout spending key.
+	sproutSpendingKeySize = 32
+
+	// saplingExtendedSpendingKeySize is the size of a Sapling extended
+	// spending key.
+	saplingExtendedSpendingKeySize = 169
+)
+
+// Validate checks that every key of the dump is encoded for the passed network
+// and that the address annotating each key is valid for it.  The address of
+// each transparent key is also checked to be the one the key controls.  A
+// dump that is not complete fails validation, since it may have been
+// truncated.
+func (d *Dump) Validate(net *zcashcfg.Params) error {
+	if !d.Complete {
+		return fmt.Errorf("wallet dump is incomplete")
+	}
+
+	for i := range d.TransparentKeys {
+		if err := d.TransparentKeys[i].validate(net); err != nil {
+			return err
+		}
+	}
+	for i := range d.SproutKeys {
+		if err := d.SproutKeys[i].validate(net); err != nil {
+			return err
+		}
+	}
+	for i := range d.SaplingKeys {
+		if err := d.SaplingKeys[i].validate(net); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate checks the transparent key against the network.
+func (k *TransparentKey) validate(net *zcashcfg.Params) error {
+	if base58.Decode(k.WIF.String())[0] != net.PrivateKeyID {
+		return fmt.Errorf("transparent key of %s is not for %s",
+			k.Address, net.Name)
+	}
+
+	pkHash := btcutil.Hash160(k.WIF.SerializePubKey())
+	addr, err := zcashutil.NewAddressPubKeyHash(pkHash, net)
+	if err != nil {
+		return err
+	}
+	if addr.EncodeAddress() != k.Address {
+		return fmt.Errorf("transparent key of %s controls %s", k.Address,
+			addr.EncodeAddress())
+	}
+	return nil
+}
+
+// validate checks the Sprout key against the network.
+func (k *SproutKey) validate(net *zcashcfg.Params) error {
+	// The two-byte prefix is split between the version and the payload.
+	payload, version, err := base58.CheckDecode(k.SpendingKey)
+	if err != nil {
+		return fmt.Errorf("Sprout key of %s is invalid: %v", k.Address, err)
+	}
+	if len(payload) != 1+sproutSpendingKeySize ||
+		[2]byte{version, payload[0]} != net.SproutSpendingKeyID {
+		return fmt.Errorf("Sprout key of %s is not for %s", k.Address,
+			net.Name)
+	}
+	return validateAddress(k.Address, net)
+}
+
+// validate checks the Sapling key against the network.
+func (k *SaplingKey) validate(net *zcashcfg.Params) error {
+	hrp, key, version, err := bech32.DecodeToBase256(k.ExtendedSpendingKey)
+	if err != nil || version != bech32.Bech32 ||
+		len(key) != saplingExtendedSpendingKeySize {
+		return fmt.Errorf("Sapling key of %s is invalid", k.Address)
+	}
+	if hrp != net.SaplingExtendedSpendingKeyHRP {
+		return fmt.Errorf("Sapling key of %s is not for %s", k.Address,
+			net.Name)
+	}
+	return validateAddress(k.Address, net)
+}
+
+// validateAddress checks that the address is valid for the network.
+func validateAddress(address string, net *zcashcfg.Params) error {
+	addr, err := zcashutil.DecodeAddress(address)
+	if err != nil {
+		return fmt.Errorf("address %s is invalid: %v", address, err)
+	}
+	if !addr.IsForNet(net) {
+		return fmt.Errorf("address %s is not for %s", address, net.Name)
+	}
+	return nil
+}
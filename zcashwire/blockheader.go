@@ -0,0 +1,171 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashwire
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// MaxSolutionSize is the maximum size of an Equihash solution.  This is the
+// size of a solution for the n=200, k=9 parameters used by mainnet and
+// testnet.
+const MaxSolutionSize = 1344
+
+// MaxBlockHeaderPayload is the maximum number of bytes a block header can be.
+// Version 4 bytes + PrevBlock and MerkleRoot and BlockCommitments hashes +
+// Timestamp 4 bytes + Bits 4 bytes + Nonce 32 bytes + Solution length and
+// bytes.
+const MaxBlockHeaderPayload = 4 + (chainhash.HashSize * 3) + 4 + 4 + 32 + 3 +
+	MaxSolutionSize
+
+// equihashInputSize is the size of the header fields that are hashed to seed
+// the Equihash solver, which is everything but the solution.
+const equihashInputSize = 4 + (chainhash.HashSize * 3) + 4 + 4 + 32
+
+// BlockHeader defines information about a Zcash block.
+type BlockHeader struct {
+	// Version of the block.
+	Version int32
+
+	// Hash of the previous block in the block chain.
+	PrevBlock chainhash.Hash
+
+	// Merkle tree reference to hash of all transactions for the block.
+	MerkleRoot chainhash.Hash
+
+	// BlockCommitments is the commitment field whose meaning depends on
+	// the network upgrade active at the height of the block.  It is
+	// reserved (zero) before Sapling, the final Sapling note commitment
+	// tree root from Sapling until Heartwood, the light client root
+	// (chain history root) from Heartwood until NU5 and the ZIP 244 block
+	// commitments hash from NU5 on.
+	BlockCommitments chainhash.Hash
+
+	// Time the block was created.  This is, unfortunately, encoded as a
+	// uint32 on the wire and therefore is limited to 2106.
+	Timestamp time.Time
+
+	// Difficulty target for the block.
+	Bits uint32
+
+	// Nonce used to generate the block.
+	Nonce [32]byte
+
+	// Solution is the Equihash solution for the block.
+	Solution []byte
+}
+
+// FinalSaplingRoot returns the root of the Sapling note commitment tree
+// committed to by the header.  It is only meaningful for blocks between the
+// Sapling and Heartwood network upgrades.
+func (h *BlockHeader) FinalSaplingRoot() chainhash.Hash {
+	return h.BlockCommitments
+}
+
+// BlockHash computes the block identifier hash for the given block header.
+func (h *BlockHeader) BlockHash() chainhash.Hash {
+	// Encode the header and double sha256 everything prior to the number of
+	// transactions.  Ignore the error returns since there is no way the
+	// encode could fail except being out of memory which would cause a
+	// run-time panic.
+	buf := bytes.NewBuffer(make([]byte, 0, h.SerializeSize()))
+	_ = h.Serialize(buf)
+	return chainhash.DoubleHashH(buf.Bytes())
+}
+
+// EquihashInput returns the serialized header without the solution, which
+// is the input to the Equihash proof-of-work.
+func (h *BlockHeader) EquihashInput() []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, equihashInputSize))
+	_ = h.serializeNoSolution(buf)
+	return buf.Bytes()
+}
+
+// Deserialize decodes a block header from r into the receiver using the
+// Zcash consensus encoding.
+func (h *BlockHeader) Deserialize(r io.Reader) error {
+	version, err := readUint32(r)
+	if err != nil {
+		return err
+	}
+	h.Version = int32(version)
+
+	err = readFields(r, h.PrevBlock[:], h.MerkleRoot[:],
+		h.BlockCommitments[:])
+	if err != nil {
+		return err
+	}
+
+	timestamp, err := readUint32(r)
+	if err != nil {
+		return err
+	}
+	h.Timestamp = time.Unix(int64(timestamp), 0)
+
+	h.Bits, err = readUint32(r)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.ReadFull(r, h.Nonce[:]); err != nil {
+		return err
+	}
+
+	h.Solution, err = wire.ReadVarBytes(r, pver, MaxSolutionSize,
+		"equihash solution")
+	return err
+}
+
+// serializeNoSolution encodes every header field but the solution to w.
+func (h *BlockHeader) serializeNoSolution(w io.Writer) error {
+	if err := writeUint32(w, uint32(h.Version)); err != nil {
+		return err
+	}
+
+	err := writeFields(w, h.PrevBlock[:], h.MerkleRoot[:],
+		h.BlockCommitments[:])
+	if err != nil {
+		return err
+	}
+
+	if err := writeUint32(w, uint32(h.Timestamp.Unix())); err != nil {
+		return err
+	}
+	if err := writeUint32(w, h.Bits); err != nil {
+		return err
+	}
+
+	_, err = w.Write(h.Nonce[:])
+	return err
+}
+
+// Serialize encodes the block header to w using the Zcash consensus
+// encoding.
+func (h *BlockHeader) Serialize(w io.Writer) error {
+	if len(h.Solution) > MaxSolutionSize {
+		str := fmt.Sprintf("equihash solution is too large [len %d, "+
+			"max %d]", len(h.Solution), MaxSolutionSize)
+		return messageError("BlockHeader.Serialize", str)
+	}
+
+	if err := h.serializeNoSolution(w); err != nil {
+		return err
+	}
+	return wire.WriteVarBytes(w, pver, h.Solution)
+}
+
+// SerializeSize returns the number of bytes it would take to serialize the
+// block header.
+func (h *BlockHeader) SerializeSize() int {
+	return equihashInputSize +
+		wire.VarIntSerializeSize(uint64(len(h.Solution))) +
+		len(h.Solution)
+}
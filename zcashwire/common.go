@@ -0,0 +1,182 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashwire
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+// pver is the protocol version passed to the btcsuite wire helpers.  The
+// variable length integer and byte encodings do not depend on it.
+const pver = 0
+
+// MessageError describes an issue with a message.  An example of some
+// potential issues are transactions with an unknown version or counts that
+// exceed the maximum allowed.
+type MessageError struct {
+	Func        string // Function name
+	Description string // Human readable description of the issue
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e *MessageError) Error() string {
+	if e.Func != "" {
+		return fmt.Sprintf("%v: %v", e.Func, e.Description)
+	}
+	return e.Description
+}
+
+// messageError creates an error for the given function and description.
+func messageError(f string, desc string) *MessageError {
+	return &MessageError{Func: f, Description: desc}
+}
+
+// readUint32 reads a little endian uint32 from r.
+func readUint32(r io.Reader) (uint32, error) {
+	var buf [4]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(buf[:]), nil
+}
+
+// readUint64 reads a little endian uint64 from r.
+func readUint64(r io.Reader) (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf[:]), nil
+}
+
+// writeUint32 writes v to w in little endian byte order.
+func writeUint32(w io.Writer, v uint32) error {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], v)
+	_, err := w.Write(buf[:])
+	return err
+}
+
+// writeUint64 writes v to w in little endian byte order.
+func writeUint64(w io.Writer, v uint64) error {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	_, err := w.Write(buf[:])
+	return err
+}
+
+// readCount reads a compact size count from r and ensures it does not exceed
+// max so a malicious count can't cause a huge allocation.
+func readCount(r io.Reader, max uint64, f, field string) (uint64, error) {
+	count, err := wire.ReadVarInt(r, pver)
+	if err != nil {
+		return 0, err
+	}
+	if count > max {
+		str := fmt.Sprintf("too many %s [count %d, max %d]", field,
+			count, max)
+		return 0, messageError(f, str)
+	}
+	return count, nil
+}
+
+// readOutPoint reads the next sequence of bytes from r as an OutPoint.
+func readOutPoint(r io.Reader, op *wire.OutPoint) error {
+	if _, err := io.ReadFull(r, op.Hash[:]); err != nil {
+		return err
+	}
+
+	var err error
+	op.Index, err = readUint32(r)
+	return err
+}
+
+// writeOutPoint encodes op to w.
+func writeOutPoint(w io.Writer, op *wire.OutPoint) error {
+	if _, err := w.Write(op.Hash[:]); err != nil {
+		return err
+	}
+	return writeUint32(w, op.Index)
+}
+
+// readTxIn reads the next sequence of bytes from r as a transaction input.
+func readTxIn(r io.Reader, ti *wire.TxIn) error {
+	err := readOutPoint(r, &ti.PreviousOutPoint)
+	if err != nil {
+		return err
+	}
+
+	ti.SignatureScript, err = wire.ReadVarBytes(r, pver, MaxTxPayload,
+		"transaction input signature script")
+	if err != nil {
+		return err
+	}
+
+	ti.Sequence, err = readUint32(r)
+	return err
+}
+
+// writeTxIn encodes ti to w.
+func writeTxIn(w io.Writer, ti *wire.TxIn) error {
+	err := writeOutPoint(w, &ti.PreviousOutPoint)
+	if err != nil {
+		return err
+	}
+
+	err = wire.WriteVarBytes(w, pver, ti.SignatureScript)
+	if err != nil {
+		return err
+	}
+
+	return writeUint32(w, ti.Sequence)
+}
+
+// readTxOut reads the next sequence of bytes from r as a transaction output.
+func readTxOut(r io.Reader, to *wire.TxOut) error {
+	value, err := readUint64(r)
+	if err != nil {
+		return err
+	}
+	to.Value = int64(value)
+
+	to.PkScript, err = wire.ReadVarBytes(r, pver, MaxTxPayload,
+		"transaction output public key script")
+	return err
+}
+
+// WriteTxOut encodes to into the Zcash protocol encoding for a transaction
+// output to w.  The encoding is shared with the transaction digests.
+func WriteTxOut(w io.Writer, to *wire.TxOut) error {
+	err := writeUint64(w, uint64(to.Value))
+	if err != nil {
+		return err
+	}
+
+	return wire.WriteVarBytes(w, pver, to.PkScript)
+}
+
+// readFields reads each of the passed fixed size fields from r in order.
+func readFields(r io.Reader, fields ...[]byte) error {
+	for _, field := range fields {
+		if _, err := io.ReadFull(r, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFields writes each of the passed fields to w in order.
+func writeFields(w io.Writer, fields ...[]byte) error {
+	for _, field := range fields {
+		if _, err := w.Write(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package zcashwire implements the Zcash consensus serialization of transactions
+and blocks.
+
+The btcsuite wire package models Bitcoin transactions, which cannot represent
+the shielded components Zcash adds.  This package provides a MsgTx type that
+understands every Zcash transaction version:
+
+  - v1 transparent-only transactions
+  - v2 transactions carrying Sprout JoinSplit descriptions (BCTV14 proofs)
+  - v3 Overwinter transactions adding the version group ID and expiry height
+  - v4 Sapling transactions adding Sapling spends and outputs and Groth16
+    JoinSplit proofs
+  - v5 NU5 transactions using the ZIP 225 layout with Orchard actions
+
+Transparent inputs and outputs are identical to Bitcoin, so the wire.TxIn,
+wire.TxOut and wire.OutPoint types are reused as is.
+
+Transaction Hashes
+
+Transactions prior to v5 are identified by the double SHA-256 of their
+serialization just like Bitcoin.  Version 5 transactions are identified by the
+ZIP 244 digest tree instead, which TxHash computes automatically.
+
+Blocks
+
+Zcash block headers extend the Bitcoin header with a commitments field, a
+32-byte nonce and a variable length Equihash solution.  BlockHeader models
+that layout and MsgBlock pairs it with the Zcash transactions of the block.
+*/
+package zcashwire
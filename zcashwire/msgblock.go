@@ -0,0 +1,105 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashwire
+
+import (
+	"io"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// minTxPayload is the minimum payload size for a transaction.  It is the
+// version and the input and output counts of an otherwise empty v1
+// transaction plus its lock time.
+const minTxPayload = 4 + 1 + 1 + 4
+
+// maxTxPerBlock is the maximum number of transactions that could possibly
+// fit into a block.
+const maxTxPerBlock = (MaxBlockPayload / minTxPayload) + 1
+
+// MsgBlock implements the Zcash block format, which is a header followed by
+// the Zcash transactions of the block.
+type MsgBlock struct {
+	Header       BlockHeader
+	Transactions []*MsgTx
+}
+
+// AddTransaction adds a transaction to the message.
+func (msg *MsgBlock) AddTransaction(tx *MsgTx) {
+	msg.Transactions = append(msg.Transactions, tx)
+}
+
+// BlockHash computes the block identifier hash for this block.
+func (msg *MsgBlock) BlockHash() chainhash.Hash {
+	return msg.Header.BlockHash()
+}
+
+// TxHashes returns a slice of hashes of all of transactions in this block.
+func (msg *MsgBlock) TxHashes() []chainhash.Hash {
+	hashList := make([]chainhash.Hash, 0, len(msg.Transactions))
+	for _, tx := range msg.Transactions {
+		hashList = append(hashList, tx.TxHash())
+	}
+	return hashList
+}
+
+// Deserialize decodes a block from r into the receiver using the Zcash
+// consensus encoding.
+func (msg *MsgBlock) Deserialize(r io.Reader) error {
+	if err := msg.Header.Deserialize(r); err != nil {
+		return err
+	}
+
+	txCount, err := readCount(r, maxTxPerBlock, "MsgBlock.Deserialize",
+		"transactions")
+	if err != nil {
+		return err
+	}
+
+	msg.Transactions = make([]*MsgTx, 0, txCount)
+	for i := uint64(0); i < txCount; i++ {
+		tx := new(MsgTx)
+		if err := tx.Deserialize(r); err != nil {
+			return err
+		}
+		msg.Transactions = append(msg.Transactions, tx)
+	}
+
+	return nil
+}
+
+// Serialize encodes the block to w using the Zcash consensus encoding.
+func (msg *MsgBlock) Serialize(w io.Writer) error {
+	if err := msg.Header.Serialize(w); err != nil {
+		return err
+	}
+
+	err := wire.WriteVarInt(w, pver, uint64(len(msg.Transactions)))
+	if err != nil {
+		return err
+	}
+
+	for _, tx := range msg.Transactions {
+		if err := tx.Serialize(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SerializeSize returns the number of bytes it would take to serialize the
+// block.
+func (msg *MsgBlock) SerializeSize() int {
+	n := msg.Header.SerializeSize() +
+		wire.VarIntSerializeSize(uint64(len(msg.Transactions)))
+
+	for _, tx := range msg.Transactions {
+		n += tx.SerializeSize()
+	}
+
+	return n
+}
@@ -0,0 +1,1056 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashwire
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// MaxBlockPayload is the maximum number of bytes a serialized Zcash
+	// block may occupy.
+	MaxBlockPayload = 2000000
+
+	// MaxTxPayload is the maximum number of bytes a serialized Zcash
+	// transaction may occupy.
+	MaxTxPayload = MaxBlockPayload
+)
+
+// Transaction versions.  Transactions with a version of OverwinterTxVersion
+// or greater have the overwintered flag set in their header.
+const (
+	// SproutTxVersion is the first transaction version able to carry
+	// JoinSplit descriptions.
+	SproutTxVersion = 2
+
+	// OverwinterTxVersion is the transaction version introduced by the
+	// Overwinter network upgrade.
+	OverwinterTxVersion = 3
+
+	// SaplingTxVersion is the transaction version introduced by the
+	// Sapling network upgrade.
+	SaplingTxVersion = 4
+
+	// NU5TxVersion is the ZIP 225 transaction version introduced by the
+	// NU5 network upgrade.
+	NU5TxVersion = 5
+)
+
+// Version group IDs that must accompany the overwintered transaction
+// versions.
+const (
+	// OverwinterVersionGroupID is the version group ID of v3 transactions.
+	OverwinterVersionGroupID = 0x03C48270
+
+	// SaplingVersionGroupID is the version group ID of v4 transactions.
+	SaplingVersionGroupID = 0x892F2085
+
+	// NU5VersionGroupID is the version group ID of v5 transactions.
+	NU5VersionGroupID = 0x26A7270A
+)
+
+// Sizes of the fixed length fields of the shielded components.
+const (
+	// BCTV14ProofSize is the size of a JoinSplit proof in v2 and v3
+	// transactions.
+	BCTV14ProofSize = 296
+
+	// Groth16ProofSize is the size of a Sapling spend, Sapling output or
+	// v4 JoinSplit proof.
+	Groth16ProofSize = 192
+
+	// JoinSplitCiphertextSize is the size of each JoinSplit note
+	// ciphertext.
+	JoinSplitCiphertextSize = 601
+
+	// EncCiphertextSize is the size of a Sapling or Orchard note
+	// ciphertext.
+	EncCiphertextSize = 580
+
+	// OutCiphertextSize is the size of a Sapling or Orchard outgoing
+	// ciphertext.
+	OutCiphertextSize = 80
+
+	// SignatureSize is the size of the RedJubjub, RedPallas and Ed25519
+	// signatures used by the shielded components.
+	SignatureSize = 64
+)
+
+// overwinteredFlag is the bit of the transaction header indicating the
+// transaction uses the Overwinter (or later) format.
+const overwinteredFlag = 1 << 31
+
+// Serialized sizes of the shielded descriptions, used to bound counts read
+// from the wire.
+const (
+	saplingSpendSizeV4   = 32*4 + Groth16ProofSize + SignatureSize
+	saplingOutputSizeV4  = 32*3 + EncCiphertextSize + OutCiphertextSize + Groth16ProofSize
+	saplingSpendSizeV5   = 32*3 + Groth16ProofSize + SignatureSize
+	saplingOutputSizeV5  = saplingOutputSizeV4
+	orchardActionSize    = 32*5 + EncCiphertextSize + OutCiphertextSize + SignatureSize
+	joinSplitSizeNoProof = 8*2 + 32*9 + JoinSplitCiphertextSize*2
+
+	// minTxInPayload is the minimum payload size of a transparent input.
+	minTxInPayload = 32 + 4 + 1 + 4
+
+	// minTxOutPayload is the minimum payload size of a transparent output.
+	minTxOutPayload = 8 + 1
+)
+
+// JoinSplit describes a Sprout JoinSplit transfer.  The proof is a BCTV14
+// proof in v2 and v3 transactions and a Groth16 proof in v4 transactions.
+type JoinSplit struct {
+	VPubOld      uint64
+	VPubNew      uint64
+	Anchor       [32]byte
+	Nullifiers   [2][32]byte
+	Commitments  [2][32]byte
+	EphemeralKey [32]byte
+	RandomSeed   [32]byte
+	Macs         [2][32]byte
+	Proof        []byte
+	Ciphertexts  [2][JoinSplitCiphertextSize]byte
+}
+
+// SaplingSpend describes a Sapling spend.  Version 5 transactions share a
+// single anchor between all spends, which is replicated into each spend when
+// deserializing.
+type SaplingSpend struct {
+	CV           [32]byte
+	Anchor       [32]byte
+	Nullifier    [32]byte
+	RK           [32]byte
+	ZKProof      [Groth16ProofSize]byte
+	SpendAuthSig [SignatureSize]byte
+}
+
+// SaplingOutput describes a Sapling output.
+type SaplingOutput struct {
+	CV            [32]byte
+	CMU           [32]byte
+	EphemeralKey  [32]byte
+	EncCiphertext [EncCiphertextSize]byte
+	OutCiphertext [OutCiphertextSize]byte
+	ZKProof       [Groth16ProofSize]byte
+}
+
+// OrchardAction describes an Orchard action, which combines a spend and an
+// output.
+type OrchardAction struct {
+	CV            [32]byte
+	Nullifier     [32]byte
+	RK            [32]byte
+	CMX           [32]byte
+	EphemeralKey  [32]byte
+	EncCiphertext [EncCiphertextSize]byte
+	OutCiphertext [OutCiphertextSize]byte
+	SpendAuthSig  [SignatureSize]byte
+}
+
+// MsgTx implements the Zcash transaction format for all consensus versions.
+//
+// Fields which do not exist in the format of a given version are ignored
+// when serializing and left at their zero value when deserializing.
+type MsgTx struct {
+	Overwintered      bool
+	Version           int32
+	VersionGroupID    uint32
+	ConsensusBranchID uint32 // v5 only
+	TxIn              []*wire.TxIn
+	TxOut             []*wire.TxOut
+	LockTime          uint32
+	ExpiryHeight      uint32 // v3 and later
+
+	// Sapling components (v4 and later).
+	ValueBalanceSapling int64
+	SaplingSpends       []*SaplingSpend
+	SaplingOutputs      []*SaplingOutput
+	BindingSigSapling   [SignatureSize]byte
+
+	// Sprout components (v2 through v4).
+	JoinSplits      []*JoinSplit
+	JoinSplitPubKey [32]byte
+	JoinSplitSig    [SignatureSize]byte
+
+	// Orchard components (v5 only).
+	OrchardActions      []*OrchardAction
+	OrchardFlags        byte
+	ValueBalanceOrchard int64
+	OrchardAnchor       [32]byte
+	OrchardProof        []byte
+	BindingSigOrchard   [SignatureSize]byte
+}
+
+// NewMsgTx returns a new Zcash transaction of the given version.  The
+// overwintered flag and version group ID are set as required by the version.
+// Version 5 transactions additionally need their ConsensusBranchID set before
+// they are serialized.
+func NewMsgTx(version int32) *MsgTx {
+	msg := &MsgTx{Version: version}
+	switch version {
+	case OverwinterTxVersion:
+		msg.Overwintered = true
+		msg.VersionGroupID = OverwinterVersionGroupID
+	case SaplingTxVersion:
+		msg.Overwintered = true
+		msg.VersionGroupID = SaplingVersionGroupID
+	case NU5TxVersion:
+		msg.Overwintered = true
+		msg.VersionGroupID = NU5VersionGroupID
+	}
+	return msg
+}
+
+// AddTxIn adds a transaction input to the message.
+func (msg *MsgTx) AddTxIn(ti *wire.TxIn) {
+	msg.TxIn = append(msg.TxIn, ti)
+}
+
+// AddTxOut adds a transaction output to the message.
+func (msg *MsgTx) AddTxOut(to *wire.TxOut) {
+	msg.TxOut = append(msg.TxOut, to)
+}
+
+// Header returns the 32-bit transaction header, which is the version with the
+// overwintered flag in the most significant bit.
+func (msg *MsgTx) Header() uint32 {
+	header := uint32(msg.Version) &^ overwinteredFlag
+	if msg.Overwintered {
+		header |= overwinteredFlag
+	}
+	return header
+}
+
+// IsCoinBase determines whether or not the transaction is a coinbase.  A
+// coinbase is a special transaction created by miners that has exactly one
+// transparent input whose previous outpoint is null.
+func (msg *MsgTx) IsCoinBase() bool {
+	if len(msg.TxIn) != 1 {
+		return false
+	}
+	prevOut := &msg.TxIn[0].PreviousOutPoint
+	return prevOut.Index == wire.MaxPrevOutIndex &&
+		prevOut.Hash == (chainhash.Hash{})
+}
+
+// TxHash generates the hash that identifies the transaction.  This is the
+// double SHA-256 of the serialization for transactions prior to v5 and the
+// ZIP 244 transaction digest for v5 transactions.
+func (msg *MsgTx) TxHash() chainhash.Hash {
+	if msg.Overwintered && msg.Version >= NU5TxVersion {
+		return msg.zip244TxID()
+	}
+
+	// Encode the transaction and calculate double sha256 on the result.
+	// Ignore the error returns since the only way the encode could fail
+	// is being out of memory or due to nil pointers, both of which would
+	// cause a run-time panic.
+	buf := bytes.NewBuffer(make([]byte, 0, msg.SerializeSize()))
+	_ = msg.Serialize(buf)
+	return chainhash.DoubleHashH(buf.Bytes())
+}
+
+// validateVersion ensures the version, overwintered flag and version group ID
+// describe a transaction format this package understands.
+func (msg *MsgTx) validateVersion(f string) error {
+	if !msg.Overwintered {
+		if msg.Version < 1 || msg.Version > SproutTxVersion {
+			str := fmt.Sprintf("unsupported transaction version %d",
+				msg.Version)
+			return messageError(f, str)
+		}
+		return nil
+	}
+
+	var groupID uint32
+	switch msg.Version {
+	case OverwinterTxVersion:
+		groupID = OverwinterVersionGroupID
+	case SaplingTxVersion:
+		groupID = SaplingVersionGroupID
+	case NU5TxVersion:
+		groupID = NU5VersionGroupID
+	default:
+		str := fmt.Sprintf("unsupported overwintered transaction "+
+			"version %d", msg.Version)
+		return messageError(f, str)
+	}
+	if msg.VersionGroupID != groupID {
+		str := fmt.Sprintf("version group ID %#08x does not match "+
+			"transaction version %d", msg.VersionGroupID, msg.Version)
+		return messageError(f, str)
+	}
+	return nil
+}
+
+// isSaplingV4 returns whether the transaction uses the v4 Sapling format.
+func (msg *MsgTx) isSaplingV4() bool {
+	return msg.Overwintered && msg.Version == SaplingTxVersion
+}
+
+// isNU5 returns whether the transaction uses the v5 ZIP 225 format.
+func (msg *MsgTx) isNU5() bool {
+	return msg.Overwintered && msg.Version == NU5TxVersion
+}
+
+// joinSplitProofSize returns the size of the JoinSplit proofs for the
+// version of the transaction.
+func (msg *MsgTx) joinSplitProofSize() int {
+	if msg.isSaplingV4() {
+		return Groth16ProofSize
+	}
+	return BCTV14ProofSize
+}
+
+// Deserialize decodes a transaction from r into the receiver using the
+// Zcash consensus encoding.  If the format is invalid the receiver is left in
+// an unknown state.
+func (msg *MsgTx) Deserialize(r io.Reader) error {
+	header, err := readUint32(r)
+	if err != nil {
+		return err
+	}
+	*msg = MsgTx{
+		Overwintered: header&overwinteredFlag != 0,
+		Version:      int32(header &^ overwinteredFlag),
+	}
+	if msg.Overwintered {
+		msg.VersionGroupID, err = readUint32(r)
+		if err != nil {
+			return err
+		}
+	}
+	if err := msg.validateVersion("MsgTx.Deserialize"); err != nil {
+		return err
+	}
+
+	if msg.isNU5() {
+		return msg.deserializeV5(r)
+	}
+
+	if err := msg.readTransparent(r); err != nil {
+		return err
+	}
+	msg.LockTime, err = readUint32(r)
+	if err != nil {
+		return err
+	}
+	if msg.Overwintered {
+		msg.ExpiryHeight, err = readUint32(r)
+		if err != nil {
+			return err
+		}
+	}
+
+	if msg.isSaplingV4() {
+		if err := msg.readSaplingV4(r); err != nil {
+			return err
+		}
+	}
+
+	if msg.Version >= SproutTxVersion {
+		if err := msg.readJoinSplits(r); err != nil {
+			return err
+		}
+	}
+
+	if msg.isSaplingV4() &&
+		len(msg.SaplingSpends)+len(msg.SaplingOutputs) > 0 {
+
+		_, err = io.ReadFull(r, msg.BindingSigSapling[:])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deserializeV5 decodes the remainder of a v5 transaction after the header
+// and version group ID.
+func (msg *MsgTx) deserializeV5(r io.Reader) error {
+	var err error
+	msg.ConsensusBranchID, err = readUint32(r)
+	if err != nil {
+		return err
+	}
+	msg.LockTime, err = readUint32(r)
+	if err != nil {
+		return err
+	}
+	msg.ExpiryHeight, err = readUint32(r)
+	if err != nil {
+		return err
+	}
+
+	if err := msg.readTransparent(r); err != nil {
+		return err
+	}
+	if err := msg.readSaplingV5(r); err != nil {
+		return err
+	}
+	return msg.readOrchard(r)
+}
+
+// readTransparent decodes the transparent inputs and outputs.
+func (msg *MsgTx) readTransparent(r io.Reader) error {
+	const f = "MsgTx.Deserialize"
+
+	count, err := readCount(r, MaxTxPayload/minTxInPayload, f,
+		"input transactions")
+	if err != nil {
+		return err
+	}
+	msg.TxIn = make([]*wire.TxIn, count)
+	for i := range msg.TxIn {
+		ti := new(wire.TxIn)
+		if err := readTxIn(r, ti); err != nil {
+			return err
+		}
+		msg.TxIn[i] = ti
+	}
+
+	count, err = readCount(r, MaxTxPayload/minTxOutPayload, f,
+		"output transactions")
+	if err != nil {
+		return err
+	}
+	msg.TxOut = make([]*wire.TxOut, count)
+	for i := range msg.TxOut {
+		to := new(wire.TxOut)
+		if err := readTxOut(r, to); err != nil {
+			return err
+		}
+		msg.TxOut[i] = to
+	}
+
+	return nil
+}
+
+// readSaplingV4 decodes the value balance, spends and outputs of a v4
+// transaction.  The binding signature follows the JoinSplits and is read
+// separately.
+func (msg *MsgTx) readSaplingV4(r io.Reader) error {
+	const f = "MsgTx.Deserialize"
+
+	valueBalance, err := readUint64(r)
+	if err != nil {
+		return err
+	}
+	msg.ValueBalanceSapling = int64(valueBalance)
+
+	count, err := readCount(r, MaxTxPayload/saplingSpendSizeV4, f,
+		"sapling spends")
+	if err != nil {
+		return err
+	}
+	msg.SaplingSpends = make([]*SaplingSpend, count)
+	for i := range msg.SaplingSpends {
+		sp := new(SaplingSpend)
+		err := readFields(r, sp.CV[:], sp.Anchor[:], sp.Nullifier[:],
+			sp.RK[:], sp.ZKProof[:], sp.SpendAuthSig[:])
+		if err != nil {
+			return err
+		}
+		msg.SaplingSpends[i] = sp
+	}
+
+	count, err = readCount(r, MaxTxPayload/saplingOutputSizeV4, f,
+		"sapling outputs")
+	if err != nil {
+		return err
+	}
+	msg.SaplingOutputs = make([]*SaplingOutput, count)
+	for i := range msg.SaplingOutputs {
+		out := new(SaplingOutput)
+		err := readFields(r, out.CV[:], out.CMU[:], out.EphemeralKey[:],
+			out.EncCiphertext[:], out.OutCiphertext[:],
+			out.ZKProof[:])
+		if err != nil {
+			return err
+		}
+		msg.SaplingOutputs[i] = out
+	}
+
+	return nil
+}
+
+// readJoinSplits decodes the JoinSplit descriptions along with the JoinSplit
+// public key and signature when there is at least one description.
+func (msg *MsgTx) readJoinSplits(r io.Reader) error {
+	proofSize := msg.joinSplitProofSize()
+	count, err := readCount(r,
+		uint64(MaxTxPayload/(joinSplitSizeNoProof+proofSize)),
+		"MsgTx.Deserialize", "joinsplits")
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+
+	msg.JoinSplits = make([]*JoinSplit, count)
+	for i := range msg.JoinSplits {
+		js := &JoinSplit{Proof: make([]byte, proofSize)}
+		js.VPubOld, err = readUint64(r)
+		if err != nil {
+			return err
+		}
+		js.VPubNew, err = readUint64(r)
+		if err != nil {
+			return err
+		}
+		err = readFields(r, js.Anchor[:], js.Nullifiers[0][:],
+			js.Nullifiers[1][:], js.Commitments[0][:],
+			js.Commitments[1][:], js.EphemeralKey[:],
+			js.RandomSeed[:], js.Macs[0][:], js.Macs[1][:], js.Proof,
+			js.Ciphertexts[0][:], js.Ciphertexts[1][:])
+		if err != nil {
+			return err
+		}
+		msg.JoinSplits[i] = js
+	}
+
+	return readFields(r, msg.JoinSplitPubKey[:], msg.JoinSplitSig[:])
+}
+
+// readSaplingV5 decodes the Sapling bundle of a v5 transaction.
+func (msg *MsgTx) readSaplingV5(r io.Reader) error {
+	const f = "MsgTx.Deserialize"
+
+	count, err := readCount(r, MaxTxPayload/saplingSpendSizeV5, f,
+		"sapling spends")
+	if err != nil {
+		return err
+	}
+	msg.SaplingSpends = make([]*SaplingSpend, count)
+	for i := range msg.SaplingSpends {
+		sp := new(SaplingSpend)
+		err := readFields(r, sp.CV[:], sp.Nullifier[:], sp.RK[:])
+		if err != nil {
+			return err
+		}
+		msg.SaplingSpends[i] = sp
+	}
+
+	count, err = readCount(r, MaxTxPayload/saplingOutputSizeV5, f,
+		"sapling outputs")
+	if err != nil {
+		return err
+	}
+	msg.SaplingOutputs = make([]*SaplingOutput, count)
+	for i := range msg.SaplingOutputs {
+		out := new(SaplingOutput)
+		err := readFields(r, out.CV[:], out.CMU[:], out.EphemeralKey[:],
+			out.EncCiphertext[:], out.OutCiphertext[:])
+		if err != nil {
+			return err
+		}
+		msg.SaplingOutputs[i] = out
+	}
+
+	if len(msg.SaplingSpends)+len(msg.SaplingOutputs) == 0 {
+		return nil
+	}
+
+	valueBalance, err := readUint64(r)
+	if err != nil {
+		return err
+	}
+	msg.ValueBalanceSapling = int64(valueBalance)
+
+	if len(msg.SaplingSpends) > 0 {
+		var anchor [32]byte
+		if _, err := io.ReadFull(r, anchor[:]); err != nil {
+			return err
+		}
+		for _, sp := range msg.SaplingSpends {
+			sp.Anchor = anchor
+		}
+	}
+	for _, sp := range msg.SaplingSpends {
+		if _, err := io.ReadFull(r, sp.ZKProof[:]); err != nil {
+			return err
+		}
+	}
+	for _, sp := range msg.SaplingSpends {
+		if _, err := io.ReadFull(r, sp.SpendAuthSig[:]); err != nil {
+			return err
+		}
+	}
+	for _, out := range msg.SaplingOutputs {
+		if _, err := io.ReadFull(r, out.ZKProof[:]); err != nil {
+			return err
+		}
+	}
+
+	_, err = io.ReadFull(r, msg.BindingSigSapling[:])
+	return err
+}
+
+// readOrchard decodes the Orchard bundle of a v5 transaction.
+func (msg *MsgTx) readOrchard(r io.Reader) error {
+	const f = "MsgTx.Deserialize"
+
+	count, err := readCount(r, MaxTxPayload/orchardActionSize, f,
+		"orchard actions")
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+
+	msg.OrchardActions = make([]*OrchardAction, count)
+	for i := range msg.OrchardActions {
+		act := new(OrchardAction)
+		err := readFields(r, act.CV[:], act.Nullifier[:], act.RK[:],
+			act.CMX[:], act.EphemeralKey[:], act.EncCiphertext[:],
+			act.OutCiphertext[:])
+		if err != nil {
+			return err
+		}
+		msg.OrchardActions[i] = act
+	}
+
+	var flags [1]byte
+	if _, err := io.ReadFull(r, flags[:]); err != nil {
+		return err
+	}
+	msg.OrchardFlags = flags[0]
+
+	valueBalance, err := readUint64(r)
+	if err != nil {
+		return err
+	}
+	msg.ValueBalanceOrchard = int64(valueBalance)
+
+	if _, err := io.ReadFull(r, msg.OrchardAnchor[:]); err != nil {
+		return err
+	}
+
+	msg.OrchardProof, err = wire.ReadVarBytes(r, pver, MaxTxPayload,
+		"orchard proof")
+	if err != nil {
+		return err
+	}
+
+	for _, act := range msg.OrchardActions {
+		if _, err := io.ReadFull(r, act.SpendAuthSig[:]); err != nil {
+			return err
+		}
+	}
+
+	_, err = io.ReadFull(r, msg.BindingSigOrchard[:])
+	return err
+}
+
+// Serialize encodes the transaction to w using the Zcash consensus encoding
+// for its version.
+func (msg *MsgTx) Serialize(w io.Writer) error {
+	const f = "MsgTx.Serialize"
+	if err := msg.validateVersion(f); err != nil {
+		return err
+	}
+
+	if err := writeUint32(w, msg.Header()); err != nil {
+		return err
+	}
+	if msg.Overwintered {
+		if err := writeUint32(w, msg.VersionGroupID); err != nil {
+			return err
+		}
+	}
+
+	if msg.isNU5() {
+		return msg.serializeV5(w)
+	}
+
+	if len(msg.OrchardActions) > 0 {
+		return messageError(f, "orchard actions require a v5 "+
+			"transaction")
+	}
+	if !msg.isSaplingV4() &&
+		len(msg.SaplingSpends)+len(msg.SaplingOutputs) > 0 {
+
+		return messageError(f, "sapling spends and outputs require "+
+			"a v4 or v5 transaction")
+	}
+	if msg.Version < SproutTxVersion && len(msg.JoinSplits) > 0 {
+		return messageError(f, "joinsplits require a v2 or later "+
+			"transaction")
+	}
+
+	if err := msg.writeTransparent(w); err != nil {
+		return err
+	}
+	if err := writeUint32(w, msg.LockTime); err != nil {
+		return err
+	}
+	if msg.Overwintered {
+		if err := writeUint32(w, msg.ExpiryHeight); err != nil {
+			return err
+		}
+	}
+
+	if msg.isSaplingV4() {
+		if err := msg.writeSaplingV4(w); err != nil {
+			return err
+		}
+	}
+
+	if msg.Version >= SproutTxVersion {
+		if err := msg.writeJoinSplits(w); err != nil {
+			return err
+		}
+	}
+
+	if msg.isSaplingV4() &&
+		len(msg.SaplingSpends)+len(msg.SaplingOutputs) > 0 {
+
+		if _, err := w.Write(msg.BindingSigSapling[:]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// serializeV5 encodes the remainder of a v5 transaction after the header and
+// version group ID.
+func (msg *MsgTx) serializeV5(w io.Writer) error {
+	if len(msg.JoinSplits) > 0 {
+		return messageError("MsgTx.Serialize", "joinsplits are not "+
+			"allowed in v5 transactions")
+	}
+
+	err := writeUint32(w, msg.ConsensusBranchID)
+	if err != nil {
+		return err
+	}
+	if err := writeUint32(w, msg.LockTime); err != nil {
+		return err
+	}
+	if err := writeUint32(w, msg.ExpiryHeight); err != nil {
+		return err
+	}
+
+	if err := msg.writeTransparent(w); err != nil {
+		return err
+	}
+	if err := msg.writeSaplingV5(w); err != nil {
+		return err
+	}
+	return msg.writeOrchard(w)
+}
+
+// writeTransparent encodes the transparent inputs and outputs.
+func (msg *MsgTx) writeTransparent(w io.Writer) error {
+	err := wire.WriteVarInt(w, pver, uint64(len(msg.TxIn)))
+	if err != nil {
+		return err
+	}
+	for _, ti := range msg.TxIn {
+		if err := writeTxIn(w, ti); err != nil {
+			return err
+		}
+	}
+
+	err = wire.WriteVarInt(w, pver, uint64(len(msg.TxOut)))
+	if err != nil {
+		return err
+	}
+	for _, to := range msg.TxOut {
+		if err := WriteTxOut(w, to); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeSaplingV4 encodes the value balance, spends and outputs of a v4
+// transaction.
+func (msg *MsgTx) writeSaplingV4(w io.Writer) error {
+	err := writeUint64(w, uint64(msg.ValueBalanceSapling))
+	if err != nil {
+		return err
+	}
+
+	err = wire.WriteVarInt(w, pver, uint64(len(msg.SaplingSpends)))
+	if err != nil {
+		return err
+	}
+	for _, sp := range msg.SaplingSpends {
+		err := writeFields(w, sp.CV[:], sp.Anchor[:], sp.Nullifier[:],
+			sp.RK[:], sp.ZKProof[:], sp.SpendAuthSig[:])
+		if err != nil {
+			return err
+		}
+	}
+
+	err = wire.WriteVarInt(w, pver, uint64(len(msg.SaplingOutputs)))
+	if err != nil {
+		return err
+	}
+	for _, out := range msg.SaplingOutputs {
+		err := writeFields(w, out.CV[:], out.CMU[:],
+			out.EphemeralKey[:], out.EncCiphertext[:],
+			out.OutCiphertext[:], out.ZKProof[:])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeJoinSplit encodes a single JoinSplit description.
+func writeJoinSplit(w io.Writer, js *JoinSplit, proofSize int) error {
+	if len(js.Proof) != proofSize {
+		str := fmt.Sprintf("joinsplit proof is %d bytes instead of %d",
+			len(js.Proof), proofSize)
+		return messageError("MsgTx.Serialize", str)
+	}
+
+	if err := writeUint64(w, js.VPubOld); err != nil {
+		return err
+	}
+	if err := writeUint64(w, js.VPubNew); err != nil {
+		return err
+	}
+	return writeFields(w, js.Anchor[:], js.Nullifiers[0][:],
+		js.Nullifiers[1][:], js.Commitments[0][:],
+		js.Commitments[1][:], js.EphemeralKey[:], js.RandomSeed[:],
+		js.Macs[0][:], js.Macs[1][:], js.Proof, js.Ciphertexts[0][:],
+		js.Ciphertexts[1][:])
+}
+
+// writeJoinSplits encodes the JoinSplit descriptions along with the JoinSplit
+// public key and signature when there is at least one description.
+func (msg *MsgTx) writeJoinSplits(w io.Writer) error {
+	err := wire.WriteVarInt(w, pver, uint64(len(msg.JoinSplits)))
+	if err != nil {
+		return err
+	}
+	if len(msg.JoinSplits) == 0 {
+		return nil
+	}
+
+	proofSize := msg.joinSplitProofSize()
+	for _, js := range msg.JoinSplits {
+		if err := writeJoinSplit(w, js, proofSize); err != nil {
+			return err
+		}
+	}
+
+	return writeFields(w, msg.JoinSplitPubKey[:], msg.JoinSplitSig[:])
+}
+
+// saplingAnchorV5 returns the anchor shared by all Sapling spends of a v5
+// transaction, or an error when the spends disagree.
+func (msg *MsgTx) saplingAnchorV5() ([32]byte, error) {
+	var anchor [32]byte
+	for i, sp := range msg.SaplingSpends {
+		if i == 0 {
+			anchor = sp.Anchor
+			continue
+		}
+		if sp.Anchor != anchor {
+			return anchor, messageError("MsgTx.Serialize",
+				"v5 sapling spends must share a single anchor")
+		}
+	}
+	return anchor, nil
+}
+
+// writeSaplingV5 encodes the Sapling bundle of a v5 transaction.
+func (msg *MsgTx) writeSaplingV5(w io.Writer) error {
+	anchor, err := msg.saplingAnchorV5()
+	if err != nil {
+		return err
+	}
+
+	err = wire.WriteVarInt(w, pver, uint64(len(msg.SaplingSpends)))
+	if err != nil {
+		return err
+	}
+	for _, sp := range msg.SaplingSpends {
+		err := writeFields(w, sp.CV[:], sp.Nullifier[:], sp.RK[:])
+		if err != nil {
+			return err
+		}
+	}
+
+	err = wire.WriteVarInt(w, pver, uint64(len(msg.SaplingOutputs)))
+	if err != nil {
+		return err
+	}
+	for _, out := range msg.SaplingOutputs {
+		err := writeFields(w, out.CV[:], out.CMU[:],
+			out.EphemeralKey[:], out.EncCiphertext[:],
+			out.OutCiphertext[:])
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(msg.SaplingSpends)+len(msg.SaplingOutputs) == 0 {
+		return nil
+	}
+
+	err = writeUint64(w, uint64(msg.ValueBalanceSapling))
+	if err != nil {
+		return err
+	}
+	if len(msg.SaplingSpends) > 0 {
+		if _, err := w.Write(anchor[:]); err != nil {
+			return err
+		}
+	}
+	for _, sp := range msg.SaplingSpends {
+		if _, err := w.Write(sp.ZKProof[:]); err != nil {
+			return err
+		}
+	}
+	for _, sp := range msg.SaplingSpends {
+		if _, err := w.Write(sp.SpendAuthSig[:]); err != nil {
+			return err
+		}
+	}
+	for _, out := range msg.SaplingOutputs {
+		if _, err := w.Write(out.ZKProof[:]); err != nil {
+			return err
+		}
+	}
+
+	_, err = w.Write(msg.BindingSigSapling[:])
+	return err
+}
+
+// writeOrchard encodes the Orchard bundle of a v5 transaction.
+func (msg *MsgTx) writeOrchard(w io.Writer) error {
+	err := wire.WriteVarInt(w, pver, uint64(len(msg.OrchardActions)))
+	if err != nil {
+		return err
+	}
+	if len(msg.OrchardActions) == 0 {
+		return nil
+	}
+
+	for _, act := range msg.OrchardActions {
+		err := writeFields(w, act.CV[:], act.Nullifier[:], act.RK[:],
+			act.CMX[:], act.EphemeralKey[:], act.EncCiphertext[:],
+			act.OutCiphertext[:])
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := w.Write([]byte{msg.OrchardFlags}); err != nil {
+		return err
+	}
+	err = writeUint64(w, uint64(msg.ValueBalanceOrchard))
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg.OrchardAnchor[:]); err != nil {
+		return err
+	}
+	if err := wire.WriteVarBytes(w, pver, msg.OrchardProof); err != nil {
+		return err
+	}
+	for _, act := range msg.OrchardActions {
+		if _, err := w.Write(act.SpendAuthSig[:]); err != nil {
+			return err
+		}
+	}
+
+	_, err = w.Write(msg.BindingSigOrchard[:])
+	return err
+}
+
+// SerializeSize returns the number of bytes it would take to serialize the
+// transaction.
+func (msg *MsgTx) SerializeSize() int {
+	// Header plus the version group ID for overwintered transactions.
+	n := 4
+	if msg.Overwintered {
+		n += 4
+	}
+
+	// Transparent inputs and outputs.
+	n += wire.VarIntSerializeSize(uint64(len(msg.TxIn))) +
+		wire.VarIntSerializeSize(uint64(len(msg.TxOut)))
+	for _, ti := range msg.TxIn {
+		n += 32 + 4 + 4 +
+			wire.VarIntSerializeSize(uint64(len(ti.SignatureScript))) +
+			len(ti.SignatureScript)
+	}
+	for _, to := range msg.TxOut {
+		n += 8 + wire.VarIntSerializeSize(uint64(len(to.PkScript))) +
+			len(to.PkScript)
+	}
+
+	// Lock time and, for overwintered transactions, the expiry height.
+	n += 4
+	if msg.Overwintered {
+		n += 4
+	}
+
+	numSpends := len(msg.SaplingSpends)
+	numOutputs := len(msg.SaplingOutputs)
+
+	if msg.isNU5() {
+		// Consensus branch ID.
+		n += 4
+
+		n += wire.VarIntSerializeSize(uint64(numSpends)) +
+			numSpends*saplingSpendSizeV5 +
+			wire.VarIntSerializeSize(uint64(numOutputs)) +
+			numOutputs*saplingOutputSizeV5
+		if numSpends+numOutputs > 0 {
+			n += 8 + SignatureSize
+		}
+		if numSpends > 0 {
+			n += 32
+		}
+
+		numActions := len(msg.OrchardActions)
+		n += wire.VarIntSerializeSize(uint64(numActions))
+		if numActions > 0 {
+			n += numActions*orchardActionSize + 1 + 8 + 32 +
+				wire.VarIntSerializeSize(uint64(len(msg.OrchardProof))) +
+				len(msg.OrchardProof) + SignatureSize
+		}
+		return n
+	}
+
+	if msg.isSaplingV4() {
+		n += 8 + wire.VarIntSerializeSize(uint64(numSpends)) +
+			numSpends*saplingSpendSizeV4 +
+			wire.VarIntSerializeSize(uint64(numOutputs)) +
+			numOutputs*saplingOutputSizeV4
+		if numSpends+numOutputs > 0 {
+			n += SignatureSize
+		}
+	}
+
+	if msg.Version >= SproutTxVersion {
+		n += wire.VarIntSerializeSize(uint64(len(msg.JoinSplits)))
+		if len(msg.JoinSplits) > 0 {
+			n += len(msg.JoinSplits)*
+				(joinSplitSizeNoProof+msg.joinSplitProofSize()) +
+				32 + SignatureSize
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,410 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashwire
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// genesisCoinbaseTx is the coinbase of the genesis block, shared by every
+// Zcash network.  Its hash is the merkle root of the genesis block.
+var genesisCoinbaseTx = "0100000001000000000000000000000000000000000000000000000000000000" +
+	"0000000000ffffffff4d04ffff071f0104455a63617368306239633465656638" +
+	"6237636334313765653530303165333530303938346236666561333536383361" +
+	"3763616331343161303433633432303634383335643334ffffffff0100000000" +
+	"00000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a679" +
+	"62e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a" +
+	"4c702b6bf11d5fac00000000"
+
+const genesisCoinbaseTxID = "c4eaa58879081de3c24a7b117ed2b28300e7ec4c4c1dff1d3f1268b7857a4ddb"
+
+// testTxRand fills the fields of the test transactions.  The shielded
+// components only need to have the right sizes to be serialized, so random
+// bytes exercise every field in the manner of the ZIP test vectors.
+type testTxRand struct {
+	*rand.Rand
+}
+
+// bytes returns n random bytes.
+func (r testTxRand) bytes(n int) []byte {
+	b := make([]byte, n)
+	r.Read(b)
+	return b
+}
+
+// hash returns a random 32 byte field.
+func (r testTxRand) hash() (h [32]byte) {
+	r.Read(h[:])
+	return h
+}
+
+// transparent adds random transparent inputs and outputs to the transaction.
+func (r testTxRand) transparent(tx *MsgTx, numIn, numOut int) {
+	for i := 0; i < numIn; i++ {
+		var hash chainhash.Hash
+		r.Read(hash[:])
+		txIn := wire.NewTxIn(wire.NewOutPoint(&hash, r.Uint32()),
+			r.bytes(1+r.Intn(120)), nil)
+		txIn.Sequence = r.Uint32()
+		tx.AddTxIn(txIn)
+	}
+	for i := 0; i < numOut; i++ {
+		tx.AddTxOut(wire.NewTxOut(r.Int63n(21e14), r.bytes(r.Intn(40))))
+	}
+}
+
+// joinSplits adds random JoinSplits with proofs of the passed size.
+func (r testTxRand) joinSplits(tx *MsgTx, n, proofSize int) {
+	for i := 0; i < n; i++ {
+		js := &JoinSplit{
+			VPubOld:      uint64(r.Int63n(21e14)),
+			VPubNew:      uint64(r.Int63n(21e14)),
+			Anchor:       r.hash(),
+			EphemeralKey: r.hash(),
+			RandomSeed:   r.hash(),
+			Proof:        r.bytes(proofSize),
+		}
+		for j := 0; j < 2; j++ {
+			js.Nullifiers[j] = r.hash()
+			js.Commitments[j] = r.hash()
+			js.Macs[j] = r.hash()
+			r.Read(js.Ciphertexts[j][:])
+		}
+		tx.JoinSplits = append(tx.JoinSplits, js)
+	}
+	tx.JoinSplitPubKey = r.hash()
+	r.Read(tx.JoinSplitSig[:])
+}
+
+// sapling adds random Sapling spends and outputs.  The spends share an
+// anchor, as v5 transactions require.
+func (r testTxRand) sapling(tx *MsgTx, numSpends, numOutputs int) {
+	anchor := r.hash()
+	for i := 0; i < numSpends; i++ {
+		sp := &SaplingSpend{
+			CV:        r.hash(),
+			Anchor:    anchor,
+			Nullifier: r.hash(),
+			RK:        r.hash(),
+		}
+		r.Read(sp.ZKProof[:])
+		r.Read(sp.SpendAuthSig[:])
+		tx.SaplingSpends = append(tx.SaplingSpends, sp)
+	}
+	for i := 0; i < numOutputs; i++ {
+		out := &SaplingOutput{
+			CV:           r.hash(),
+			CMU:          r.hash(),
+			EphemeralKey: r.hash(),
+		}
+		r.Read(out.EncCiphertext[:])
+		r.Read(out.OutCiphertext[:])
+		r.Read(out.ZKProof[:])
+		tx.SaplingOutputs = append(tx.SaplingOutputs, out)
+	}
+	tx.ValueBalanceSapling = r.Int63n(42e8) - 21e8
+	r.Read(tx.BindingSigSapling[:])
+}
+
+// orchard adds random Orchard actions.
+func (r testTxRand) orchard(tx *MsgTx, numActions int) {
+	for i := 0; i < numActions; i++ {
+		act := &OrchardAction{
+			CV:           r.hash(),
+			Nullifier:    r.hash(),
+			RK:           r.hash(),
+			CMX:          r.hash(),
+			EphemeralKey: r.hash(),
+		}
+		r.Read(act.EncCiphertext[:])
+		r.Read(act.OutCiphertext[:])
+		r.Read(act.SpendAuthSig[:])
+		tx.OrchardActions = append(tx.OrchardActions, act)
+	}
+	tx.OrchardFlags = 3
+	tx.ValueBalanceOrchard = r.Int63n(42e8) - 21e8
+	tx.OrchardAnchor = r.hash()
+	tx.OrchardProof = r.bytes(2720 + 2272*numActions)
+	r.Read(tx.BindingSigOrchard[:])
+}
+
+// msgTxTest describes a transaction of each supported format.
+type msgTxTest struct {
+	name string
+	tx   *MsgTx
+
+	// header is the hex encoding of the start of the serialization,
+	// which holds the header and version group ID.
+	header string
+}
+
+// msgTxTests returns transactions of each format, with every component the
+// format supports.
+func msgTxTests() []msgTxTest {
+	r := testTxRand{rand.New(rand.NewSource(1))}
+
+	v1 := &MsgTx{Version: 1, LockTime: r.Uint32()}
+	r.transparent(v1, 1, 2)
+
+	sprout := &MsgTx{Version: SproutTxVersion, LockTime: r.Uint32()}
+	r.transparent(sprout, 2, 1)
+	r.joinSplits(sprout, 2, BCTV14ProofSize)
+
+	sproutTransparent := &MsgTx{Version: SproutTxVersion}
+	r.transparent(sproutTransparent, 1, 1)
+
+	overwinter := NewMsgTx(OverwinterTxVersion)
+	overwinter.LockTime = r.Uint32()
+	overwinter.ExpiryHeight = r.Uint32()
+	r.transparent(overwinter, 1, 1)
+	r.joinSplits(overwinter, 1, BCTV14ProofSize)
+
+	sapling := NewMsgTx(SaplingTxVersion)
+	sapling.LockTime = r.Uint32()
+	sapling.ExpiryHeight = r.Uint32()
+	r.transparent(sapling, 2, 2)
+	r.sapling(sapling, 2, 3)
+	r.joinSplits(sapling, 1, Groth16ProofSize)
+
+	saplingTransparent := NewMsgTx(SaplingTxVersion)
+	r.transparent(saplingTransparent, 1, 2)
+
+	saplingOutputs := NewMsgTx(SaplingTxVersion)
+	r.transparent(saplingOutputs, 1, 0)
+	r.sapling(saplingOutputs, 0, 2)
+
+	nu5 := NewMsgTx(NU5TxVersion)
+	nu5.ConsensusBranchID = 0xc2d6d0b4
+	nu5.LockTime = r.Uint32()
+	nu5.ExpiryHeight = r.Uint32()
+	r.transparent(nu5, 2, 1)
+	r.sapling(nu5, 2, 2)
+	r.orchard(nu5, 2)
+
+	nu5Orchard := NewMsgTx(NU5TxVersion)
+	nu5Orchard.ConsensusBranchID = 0xc8e71055
+	r.orchard(nu5Orchard, 1)
+
+	nu5Outputs := NewMsgTx(NU5TxVersion)
+	nu5Outputs.ConsensusBranchID = 0xc2d6d0b4
+	r.transparent(nu5Outputs, 1, 1)
+	r.sapling(nu5Outputs, 0, 1)
+
+	nu5Transparent := NewMsgTx(NU5TxVersion)
+	nu5Transparent.ConsensusBranchID = 0xc2d6d0b4
+	r.transparent(nu5Transparent, 1, 1)
+
+	return []msgTxTest{
+		{"v1", v1, "01000000"},
+		{"v2 joinsplits", sprout, "02000000"},
+		{"v2 transparent", sproutTransparent, "02000000"},
+		{"v3 joinsplit", overwinter, "030000807082c403"},
+		{"v4 sapling and joinsplit", sapling, "0400008085202f89"},
+		{"v4 transparent", saplingTransparent, "0400008085202f89"},
+		{"v4 sapling outputs", saplingOutputs, "0400008085202f89"},
+		{"v5 sapling and orchard", nu5,
+			"050000800a27a726b4d0d6c2"},
+		{"v5 orchard", nu5Orchard, "050000800a27a7265510e7c8"},
+		{"v5 sapling outputs", nu5Outputs, "050000800a27a726b4d0d6c2"},
+		{"v5 transparent", nu5Transparent, "050000800a27a726b4d0d6c2"},
+	}
+}
+
+// serializeTestTx returns the serialization of the transaction.
+func serializeTestTx(t *testing.T, tx *MsgTx) []byte {
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestMsgTxGenesisCoinbase decodes the coinbase of the genesis block.
+func TestMsgTxGenesisCoinbase(t *testing.T) {
+	b, err := hex.DecodeString(genesisCoinbaseTx)
+	if err != nil {
+		t.Fatalf("DecodeString: %v", err)
+	}
+	var tx MsgTx
+	if err := tx.Deserialize(bytes.NewReader(b)); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+
+	if tx.Overwintered || tx.Version != 1 || !tx.IsCoinBase() ||
+		len(tx.TxOut) != 1 || tx.TxOut[0].Value != 0 {
+
+		t.Errorf("unexpected genesis coinbase %+v", &tx)
+	}
+	if got := tx.TxHash().String(); got != genesisCoinbaseTxID {
+		t.Errorf("TxHash: got %s, want %s", got, genesisCoinbaseTxID)
+	}
+	if tx.SerializeSize() != len(b) {
+		t.Errorf("SerializeSize: got %d, want %d", tx.SerializeSize(),
+			len(b))
+	}
+	if got := serializeTestTx(t, &tx); !bytes.Equal(got, b) {
+		t.Errorf("Serialize: got %x, want %x", got, b)
+	}
+}
+
+// TestMsgTxRoundTrip ensures transactions of each format are serialized with
+// their header, decode to the same transaction and keep their hash.
+func TestMsgTxRoundTrip(t *testing.T) {
+	for _, test := range msgTxTests() {
+		b := serializeTestTx(t, test.tx)
+		if got := hex.EncodeToString(b[:len(test.header)/2]); got != test.header {
+			t.Errorf("%s: serialization starts with %s, want %s",
+				test.name, got, test.header)
+		}
+		if test.tx.SerializeSize() != len(b) {
+			t.Errorf("%s: SerializeSize: got %d, want %d", test.name,
+				test.tx.SerializeSize(), len(b))
+		}
+
+		var tx MsgTx
+		if err := tx.Deserialize(bytes.NewReader(b)); err != nil {
+			t.Errorf("%s: Deserialize: %v", test.name, err)
+			continue
+		}
+		if got := serializeTestTx(t, &tx); !bytes.Equal(got, b) {
+			t.Errorf("%s: serialization changed by a round trip",
+				test.name)
+		}
+		if tx.TxHash() != test.tx.TxHash() {
+			t.Errorf("%s: TxHash changed by a round trip: got %v, "+
+				"want %v", test.name, tx.TxHash(),
+				test.tx.TxHash())
+		}
+
+		// The decoded transaction holds the same shielded components.
+		if len(tx.JoinSplits) != len(test.tx.JoinSplits) ||
+			len(tx.SaplingSpends) != len(test.tx.SaplingSpends) ||
+			len(tx.SaplingOutputs) != len(test.tx.SaplingOutputs) ||
+			len(tx.OrchardActions) != len(test.tx.OrchardActions) {
+
+			t.Errorf("%s: decoded a different number of shielded "+
+				"components", test.name)
+		}
+		for i, js := range test.tx.JoinSplits {
+			if !reflect.DeepEqual(tx.JoinSplits[i], js) {
+				t.Errorf("%s: joinsplit %d differs", test.name, i)
+			}
+		}
+		for i, sp := range test.tx.SaplingSpends {
+			if *tx.SaplingSpends[i] != *sp {
+				t.Errorf("%s: sapling spend %d differs",
+					test.name, i)
+			}
+		}
+		for i, out := range test.tx.SaplingOutputs {
+			if *tx.SaplingOutputs[i] != *out {
+				t.Errorf("%s: sapling output %d differs",
+					test.name, i)
+			}
+		}
+		for i, act := range test.tx.OrchardActions {
+			if *tx.OrchardActions[i] != *act {
+				t.Errorf("%s: orchard action %d differs",
+					test.name, i)
+			}
+		}
+
+		// Transactions before v5 are identified by the double SHA-256
+		// of their serialization.
+		if !test.tx.isNU5() && test.tx.TxHash() != chainhash.DoubleHashH(b) {
+			t.Errorf("%s: TxHash is not the double SHA-256 of the "+
+				"serialization", test.name)
+		}
+
+		// Every field is required, so any truncation fails to decode.
+		for n := 0; n < len(b); n++ {
+			err := tx.Deserialize(bytes.NewReader(b[:n]))
+			if err != io.EOF && err != io.ErrUnexpectedEOF {
+				t.Errorf("%s: Deserialize of %d of %d bytes: got "+
+					"%v, want EOF", test.name, n, len(b), err)
+				break
+			}
+		}
+	}
+}
+
+// TestMsgTxSerializeErrors ensures components the format of a transaction
+// cannot hold are rejected instead of being dropped.
+func TestMsgTxSerializeErrors(t *testing.T) {
+	tests := msgTxTests()
+	find := func(name string) *MsgTx {
+		for _, test := range tests {
+			if test.name == name {
+				tx := *test.tx
+				return &tx
+			}
+		}
+		t.Fatalf("no test transaction %s", name)
+		return nil
+	}
+	sapling := find("v4 sapling and joinsplit")
+	nu5 := find("v5 sapling and orchard")
+
+	v1JoinSplits := find("v1")
+	v1JoinSplits.JoinSplits = sapling.JoinSplits
+
+	v3Sapling := find("v3 joinsplit")
+	v3Sapling.SaplingOutputs = sapling.SaplingOutputs
+
+	v4Orchard := find("v4 sapling and joinsplit")
+	v4Orchard.OrchardActions = nu5.OrchardActions
+
+	v5JoinSplits := find("v5 sapling and orchard")
+	v5JoinSplits.JoinSplits = sapling.JoinSplits
+
+	v5Anchors := find("v5 sapling and orchard")
+	spend := *v5Anchors.SaplingSpends[1]
+	spend.Anchor[0] ^= 1
+	v5Anchors.SaplingSpends = []*SaplingSpend{v5Anchors.SaplingSpends[0],
+		&spend}
+
+	v4GroupID := find("v4 transparent")
+	v4GroupID.VersionGroupID = OverwinterVersionGroupID
+
+	v6 := find("v5 transparent")
+	v6.Version = 6
+
+	v3 := &MsgTx{Version: OverwinterTxVersion}
+
+	for name, tx := range map[string]*MsgTx{
+		"v1 joinsplits":         v1JoinSplits,
+		"v3 sapling":            v3Sapling,
+		"v4 orchard":            v4Orchard,
+		"v5 joinsplits":         v5JoinSplits,
+		"v5 anchors":            v5Anchors,
+		"v4 version group":      v4GroupID,
+		"v6":                    v6,
+		"v3 without overwinter": v3,
+	} {
+		var buf bytes.Buffer
+		err := tx.Serialize(&buf)
+		if _, ok := err.(*MessageError); !ok {
+			t.Errorf("%s: got error %v, want a MessageError", name, err)
+		}
+	}
+
+	// The version group ID must match the version when decoding.
+	b := serializeTestTx(t, find("v4 transparent"))
+	copy(b[4:8], serializeTestTx(t, find("v5 transparent"))[4:8])
+	var tx MsgTx
+	err := tx.Deserialize(bytes.NewReader(b))
+	if _, ok := err.(*MessageError); !ok {
+		t.Errorf("Deserialize with another version group: got %v, "+
+			"want a MessageError", err)
+	}
+}
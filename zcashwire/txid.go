@@ -0,0 +1,239 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashwire
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/arithmetric/zcashrpcclient/internal/blake2b"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// BLAKE2b personalizations of the ZIP 244 transaction identifier digest tree.
+var (
+	zip244HeadersPersonal      = []byte("ZTxIdHeadersHash")
+	zip244TransparentPersonal  = []byte("ZTxIdTranspaHash")
+	zip244PrevoutsPersonal     = []byte("ZTxIdPrevoutHash")
+	zip244SequencePersonal     = []byte("ZTxIdSequencHash")
+	zip244OutputsPersonal      = []byte("ZTxIdOutputsHash")
+	zip244SaplingPersonal      = []byte("ZTxIdSaplingHash")
+	zip244SSpendsPersonal      = []byte("ZTxIdSSpendsHash")
+	zip244SSpendCPersonal      = []byte("ZTxIdSSpendCHash")
+	zip244SSpendNPersonal      = []byte("ZTxIdSSpendNHash")
+	zip244SOutputPersonal      = []byte("ZTxIdSOutputHash")
+	zip244SOutCPersonal        = []byte("ZTxIdSOutC__Hash")
+	zip244SOutMPersonal        = []byte("ZTxIdSOutM__Hash")
+	zip244SOutNPersonal        = []byte("ZTxIdSOutN__Hash")
+	zip244OrchardPersonal      = []byte("ZTxIdOrchardHash")
+	zip244OrcActCPersonal      = []byte("ZTxIdOrcActCHash")
+	zip244OrcActMPersonal      = []byte("ZTxIdOrcActMHash")
+	zip244OrcActNPersonal      = []byte("ZTxIdOrcActNHash")
+	zip244TxHashPersonalPrefix = []byte("ZcashTxHash_")
+)
+
+// Offsets into a note ciphertext separating the compact note plaintext, the
+// memo and the remaining authentication tag as used by ZIP 244.
+const (
+	compactNoteSize = 52
+	memoEnd         = compactNoteSize + 512
+)
+
+// TxIDDigests houses the intermediate ZIP 244 digests that make up the
+// transaction identifier of a v5 transaction.  They are exposed so signature
+// hashes, which reuse most of the tree, can be computed without repeating the
+// work.
+type TxIDDigests struct {
+	Header      [32]byte
+	Prevouts    [32]byte
+	Sequence    [32]byte
+	Outputs     [32]byte
+	Transparent [32]byte
+	Sapling     [32]byte
+	Orchard     [32]byte
+}
+
+// TxIDDigests computes the ZIP 244 digest tree of the transaction.  The
+// digests are only meaningful for v5 transactions.
+func (msg *MsgTx) TxIDDigests() *TxIDDigests {
+	d := &TxIDDigests{
+		Header:   msg.headerDigest(),
+		Prevouts: PrevoutsDigest(msg.TxIn),
+		Sequence: SequenceDigest(msg.TxIn),
+		Outputs:  OutputsDigest(msg.TxOut),
+		Sapling:  msg.saplingDigest(),
+		Orchard:  msg.orchardDigest(),
+	}
+
+	if len(msg.TxIn) == 0 && len(msg.TxOut) == 0 {
+		d.Transparent = blake2b.Sum256(zip244TransparentPersonal, nil)
+	} else {
+		var buf bytes.Buffer
+		buf.Write(d.Prevouts[:])
+		buf.Write(d.Sequence[:])
+		buf.Write(d.Outputs[:])
+		d.Transparent = blake2b.Sum256(zip244TransparentPersonal,
+			buf.Bytes())
+	}
+
+	return d
+}
+
+// Hash computes the root of the digest tree from the header, transparent,
+// Sapling and Orchard digests for the passed consensus branch ID.  This is
+// the transaction identifier, or a signature hash when the transparent digest
+// has been replaced by the ZIP 244 transparent signature digest.
+func (d *TxIDDigests) Hash(consensusBranchID uint32) chainhash.Hash {
+	var buf bytes.Buffer
+	buf.Write(d.Header[:])
+	buf.Write(d.Transparent[:])
+	buf.Write(d.Sapling[:])
+	buf.Write(d.Orchard[:])
+	return chainhash.Hash(blake2b.Sum256(
+		TxHashPersonalization(consensusBranchID), buf.Bytes()))
+}
+
+// TxHashPersonalization returns the BLAKE2b personalization of the root of
+// the ZIP 244 digest tree for the passed consensus branch ID.
+func TxHashPersonalization(consensusBranchID uint32) []byte {
+	personal := make([]byte, 16)
+	copy(personal, zip244TxHashPersonalPrefix)
+	binary.LittleEndian.PutUint32(personal[12:], consensusBranchID)
+	return personal
+}
+
+// zip244TxID computes the ZIP 244 transaction identifier of a v5 transaction.
+func (msg *MsgTx) zip244TxID() chainhash.Hash {
+	return msg.TxIDDigests().Hash(msg.ConsensusBranchID)
+}
+
+// headerDigest computes the ZIP 244 digest of the transaction header fields.
+func (msg *MsgTx) headerDigest() [32]byte {
+	var buf [20]byte
+	binary.LittleEndian.PutUint32(buf[0:], msg.Header())
+	binary.LittleEndian.PutUint32(buf[4:], msg.VersionGroupID)
+	binary.LittleEndian.PutUint32(buf[8:], msg.ConsensusBranchID)
+	binary.LittleEndian.PutUint32(buf[12:], msg.LockTime)
+	binary.LittleEndian.PutUint32(buf[16:], msg.ExpiryHeight)
+	return blake2b.Sum256(zip244HeadersPersonal, buf[:])
+}
+
+// PrevoutsDigest computes the ZIP 244 digest of the outpoints of the passed
+// transparent inputs.
+func PrevoutsDigest(txIn []*wire.TxIn) [32]byte {
+	var buf bytes.Buffer
+	for _, ti := range txIn {
+		writeOutPoint(&buf, &ti.PreviousOutPoint)
+	}
+	return blake2b.Sum256(zip244PrevoutsPersonal, buf.Bytes())
+}
+
+// SequenceDigest computes the ZIP 244 digest of the sequence numbers of the
+// passed transparent inputs.
+func SequenceDigest(txIn []*wire.TxIn) [32]byte {
+	var buf bytes.Buffer
+	for _, ti := range txIn {
+		writeUint32(&buf, ti.Sequence)
+	}
+	return blake2b.Sum256(zip244SequencePersonal, buf.Bytes())
+}
+
+// OutputsDigest computes the ZIP 244 digest of the passed transparent
+// outputs.
+func OutputsDigest(txOut []*wire.TxOut) [32]byte {
+	var buf bytes.Buffer
+	for _, to := range txOut {
+		WriteTxOut(&buf, to)
+	}
+	return blake2b.Sum256(zip244OutputsPersonal, buf.Bytes())
+}
+
+// saplingDigest computes the ZIP 244 digest of the Sapling bundle.
+func (msg *MsgTx) saplingDigest() [32]byte {
+	if len(msg.SaplingSpends) == 0 && len(msg.SaplingOutputs) == 0 {
+		return blake2b.Sum256(zip244SaplingPersonal, nil)
+	}
+
+	var spends [32]byte
+	if len(msg.SaplingSpends) == 0 {
+		spends = blake2b.Sum256(zip244SSpendsPersonal, nil)
+	} else {
+		var compact, noncompact bytes.Buffer
+		for _, sp := range msg.SaplingSpends {
+			compact.Write(sp.Nullifier[:])
+			noncompact.Write(sp.CV[:])
+			noncompact.Write(sp.Anchor[:])
+			noncompact.Write(sp.RK[:])
+		}
+		c := blake2b.Sum256(zip244SSpendCPersonal, compact.Bytes())
+		n := blake2b.Sum256(zip244SSpendNPersonal, noncompact.Bytes())
+		spends = blake2b.Sum256(zip244SSpendsPersonal,
+			append(c[:], n[:]...))
+	}
+
+	var outputs [32]byte
+	if len(msg.SaplingOutputs) == 0 {
+		outputs = blake2b.Sum256(zip244SOutputPersonal, nil)
+	} else {
+		var compact, memos, noncompact bytes.Buffer
+		for _, out := range msg.SaplingOutputs {
+			compact.Write(out.CMU[:])
+			compact.Write(out.EphemeralKey[:])
+			compact.Write(out.EncCiphertext[:compactNoteSize])
+			memos.Write(out.EncCiphertext[compactNoteSize:memoEnd])
+			noncompact.Write(out.CV[:])
+			noncompact.Write(out.EncCiphertext[memoEnd:])
+			noncompact.Write(out.OutCiphertext[:])
+		}
+		c := blake2b.Sum256(zip244SOutCPersonal, compact.Bytes())
+		m := blake2b.Sum256(zip244SOutMPersonal, memos.Bytes())
+		n := blake2b.Sum256(zip244SOutNPersonal, noncompact.Bytes())
+
+		var buf bytes.Buffer
+		buf.Write(c[:])
+		buf.Write(m[:])
+		buf.Write(n[:])
+		outputs = blake2b.Sum256(zip244SOutputPersonal, buf.Bytes())
+	}
+
+	var buf bytes.Buffer
+	buf.Write(spends[:])
+	buf.Write(outputs[:])
+	writeUint64(&buf, uint64(msg.ValueBalanceSapling))
+	return blake2b.Sum256(zip244SaplingPersonal, buf.Bytes())
+}
+
+// orchardDigest computes the ZIP 244 digest of the Orchard bundle.
+func (msg *MsgTx) orchardDigest() [32]byte {
+	if len(msg.OrchardActions) == 0 {
+		return blake2b.Sum256(zip244OrchardPersonal, nil)
+	}
+
+	var compact, memos, noncompact bytes.Buffer
+	for _, act := range msg.OrchardActions {
+		compact.Write(act.Nullifier[:])
+		compact.Write(act.CMX[:])
+		compact.Write(act.EphemeralKey[:])
+		compact.Write(act.EncCiphertext[:compactNoteSize])
+		memos.Write(act.EncCiphertext[compactNoteSize:memoEnd])
+		noncompact.Write(act.CV[:])
+		noncompact.Write(act.RK[:])
+		noncompact.Write(act.EncCiphertext[memoEnd:])
+		noncompact.Write(act.OutCiphertext[:])
+	}
+	c := blake2b.Sum256(zip244OrcActCPersonal, compact.Bytes())
+	m := blake2b.Sum256(zip244OrcActMPersonal, memos.Bytes())
+	n := blake2b.Sum256(zip244OrcActNPersonal, noncompact.Bytes())
+
+	var buf bytes.Buffer
+	buf.Write(c[:])
+	buf.Write(m[:])
+	buf.Write(n[:])
+	buf.WriteByte(msg.OrchardFlags)
+	writeUint64(&buf, uint64(msg.ValueBalanceOrchard))
+	buf.Write(msg.OrchardAnchor[:])
+	return blake2b.Sum256(zip244OrchardPersonal, buf.Bytes())
+}
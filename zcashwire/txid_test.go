@@ -0,0 +1,168 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashwire
+
+import (
+	"strings"
+	"testing"
+)
+
+// copyTestTx returns a deep copy of the transaction.
+func copyTestTx(tx *MsgTx) *MsgTx {
+	c := *tx
+	c.TxIn = nil
+	for _, txIn := range tx.TxIn {
+		in := *txIn
+		in.SignatureScript = append([]byte(nil), txIn.SignatureScript...)
+		c.TxIn = append(c.TxIn, &in)
+	}
+	c.TxOut = nil
+	for _, txOut := range tx.TxOut {
+		out := *txOut
+		c.TxOut = append(c.TxOut, &out)
+	}
+	c.SaplingSpends = nil
+	for _, sp := range tx.SaplingSpends {
+		spend := *sp
+		c.SaplingSpends = append(c.SaplingSpends, &spend)
+	}
+	c.SaplingOutputs = nil
+	for _, out := range tx.SaplingOutputs {
+		output := *out
+		c.SaplingOutputs = append(c.SaplingOutputs, &output)
+	}
+	c.OrchardActions = nil
+	for _, act := range tx.OrchardActions {
+		action := *act
+		c.OrchardActions = append(c.OrchardActions, &action)
+	}
+	c.OrchardProof = append([]byte(nil), tx.OrchardProof...)
+	return &c
+}
+
+// TestTxHashV5 ensures the ZIP 244 identifier of a v5 transaction commits to
+// its effects but not to the signatures and proofs authorizing them, unlike
+// the identifiers of earlier versions.
+func TestTxHashV5(t *testing.T) {
+	var nu5, sapling *MsgTx
+	for _, test := range msgTxTests() {
+		switch test.name {
+		case "v5 sapling and orchard":
+			nu5 = test.tx
+		case "v4 sapling and joinsplit":
+			sapling = test.tx
+		}
+	}
+
+	authorizing := map[string]func(tx *MsgTx){
+		"signature script": func(tx *MsgTx) {
+			tx.TxIn[0].SignatureScript[0] ^= 1
+		},
+		"sapling proof": func(tx *MsgTx) {
+			tx.SaplingSpends[0].ZKProof[0] ^= 1
+		},
+		"sapling spend signature": func(tx *MsgTx) {
+			tx.SaplingSpends[1].SpendAuthSig[0] ^= 1
+		},
+		"sapling output proof": func(tx *MsgTx) {
+			tx.SaplingOutputs[0].ZKProof[0] ^= 1
+		},
+		"sapling binding signature": func(tx *MsgTx) {
+			tx.BindingSigSapling[0] ^= 1
+		},
+		"orchard proof": func(tx *MsgTx) {
+			tx.OrchardProof[0] ^= 1
+		},
+		"orchard spend signature": func(tx *MsgTx) {
+			tx.OrchardActions[1].SpendAuthSig[0] ^= 1
+		},
+		"orchard binding signature": func(tx *MsgTx) {
+			tx.BindingSigOrchard[0] ^= 1
+		},
+	}
+	effecting := map[string]func(tx *MsgTx){
+		"consensus branch": func(tx *MsgTx) {
+			tx.ConsensusBranchID = 0xc8e71055
+		},
+		"lock time":     func(tx *MsgTx) { tx.LockTime++ },
+		"expiry height": func(tx *MsgTx) { tx.ExpiryHeight++ },
+		"prevout": func(tx *MsgTx) {
+			tx.TxIn[1].PreviousOutPoint.Index++
+		},
+		"sequence": func(tx *MsgTx) { tx.TxIn[0].Sequence++ },
+		"output":   func(tx *MsgTx) { tx.TxOut[0].Value++ },
+		"sapling value balance": func(tx *MsgTx) {
+			tx.ValueBalanceSapling++
+		},
+		"sapling anchor": func(tx *MsgTx) {
+			for _, sp := range tx.SaplingSpends {
+				sp.Anchor[0] ^= 1
+			}
+		},
+		"sapling nullifier": func(tx *MsgTx) {
+			tx.SaplingSpends[1].Nullifier[0] ^= 1
+		},
+		"sapling rk": func(tx *MsgTx) {
+			tx.SaplingSpends[0].RK[0] ^= 1
+		},
+		"sapling memo": func(tx *MsgTx) {
+			tx.SaplingOutputs[1].EncCiphertext[compactNoteSize] ^= 1
+		},
+		"sapling tag": func(tx *MsgTx) {
+			tx.SaplingOutputs[1].EncCiphertext[memoEnd] ^= 1
+		},
+		"sapling out ciphertext": func(tx *MsgTx) {
+			tx.SaplingOutputs[0].OutCiphertext[0] ^= 1
+		},
+		"orchard flags": func(tx *MsgTx) { tx.OrchardFlags = 1 },
+		"orchard value balance": func(tx *MsgTx) {
+			tx.ValueBalanceOrchard++
+		},
+		"orchard anchor": func(tx *MsgTx) { tx.OrchardAnchor[0] ^= 1 },
+		"orchard nullifier": func(tx *MsgTx) {
+			tx.OrchardActions[0].Nullifier[0] ^= 1
+		},
+		"orchard cmx": func(tx *MsgTx) {
+			tx.OrchardActions[1].CMX[0] ^= 1
+		},
+		"orchard note": func(tx *MsgTx) {
+			tx.OrchardActions[1].EncCiphertext[0] ^= 1
+		},
+	}
+
+	txid := nu5.TxHash()
+	for name, mutate := range authorizing {
+		tx := copyTestTx(nu5)
+		mutate(tx)
+		if tx.TxHash() != txid {
+			t.Errorf("v5 %s: TxHash changed", name)
+		}
+
+		// Earlier versions commit to their whole serialization.
+		if !strings.HasPrefix(name, "orchard") {
+			v4 := copyTestTx(sapling)
+			mutate(v4)
+			if v4.TxHash() == sapling.TxHash() {
+				t.Errorf("v4 %s: TxHash did not change", name)
+			}
+		}
+	}
+	for name, mutate := range effecting {
+		tx := copyTestTx(nu5)
+		mutate(tx)
+		if tx.TxHash() == txid {
+			t.Errorf("v5 %s: TxHash did not change", name)
+		}
+	}
+
+	// The identifier is the root of the digest tree for the branch of
+	// the transaction.
+	if got := nu5.TxIDDigests().Hash(nu5.ConsensusBranchID); got != txid {
+		t.Errorf("TxIDDigests().Hash: got %v, want %v", got, txid)
+	}
+	if got := nu5.TxIDDigests().Hash(0xc8e71055); got == txid {
+		t.Errorf("TxIDDigests().Hash does not commit to the branch")
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashrpcclient
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/arithmetric/zcashrpcclient/internal/zmq"
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// These constants define the topics of the events zcashd publishes over
+// ZeroMQ.
+const (
+	zmqTopicHashBlock = "hashblock"
+	zmqTopicHashTx    = "hashtx"
+	zmqTopicRawBlock  = "rawblock"
+	zmqTopicRawTx     = "rawtx"
+)
+
+// zmqTopics are the topics subscribed to on each ZMQ host.
+var zmqTopics = []string{
+	zmqTopicHashBlock,
+	zmqTopicHashTx,
+	zmqTopicRawBlock,
+	zmqTopicRawTx,
+}
+
+// zmqDialTimeout is the amount of time to wait for a connection to a ZMQ host
+// to be established.
+const zmqDialTimeout = 10 * time.Second
+
+// zmqEvent is an event received from a ZMQ host, or the notice that the
+// connection to the host was established when connected is set.
+type zmqEvent struct {
+	host      string
+	connected bool
+	topic     string
+	body      []byte
+	sequence  uint32
+}
+
+// zmqSequenceKey identifies the sequence numbers of a topic published by a ZMQ
+// host.  zcashd numbers the events of each topic independently.
+type zmqSequenceKey struct {
+	host  string
+	topic string
+}
+
+// zmqHandler invokes the notification handlers for the events received from
+// the ZMQ hosts.  It must be run as a goroutine.
+//
+// zcashd only publishes the new best block, so the blocks connected and
+// disconnected by it are resolved by polling the server with the RPC
+// connection.  The server is also polled to resync whenever events may have
+// been missed, which is after (re)connecting to a ZMQ host and when the
+// sequence numbers of a topic skip.
+func (c *Client) zmqHandler() {
+	events := make(chan *zmqEvent)
+	for _, host := range c.config.ZMQHosts {
+		c.wg.Add(1)
+		go c.zmqSubscribeHandler(host, events)
+	}
+
+	var ticker <-chan time.Time
+	if c.config.PollInterval > 0 {
+		t := time.NewTicker(c.config.PollInterval)
+		defer t.Stop()
+		ticker = t.C
+	}
+
+	var state pollState
+	sequences := make(map[zmqSequenceKey]uint32)
+out:
+	for {
+		select {
+		case event := <-events:
+			c.handleZMQEvent(&state, sequences, event)
+
+		case <-ticker:
+			c.poll(&state)
+
+		case <-c.shutdown:
+			break out
+		}
+	}
+	c.wg.Done()
+	log.Tracef("RPC client ZMQ handler done for %s", c.config.Host)
+}
+
+// handleZMQEvent invokes the notification handlers for an event received from
+// a ZMQ host.
+func (c *Client) handleZMQEvent(state *pollState,
+	sequences map[zmqSequenceKey]uint32, event *zmqEvent) {
+
+	// The events published while the host was disconnected are unknown,
+	// so its sequence numbers are reset and the server is resynced.
+	if event.connected {
+		for key := range sequences {
+			if key.host == event.host {
+				delete(sequences, key)
+			}
+		}
+		c.poll(state)
+		return
+	}
+
+	key := zmqSequenceKey{host: event.host, topic: event.topic}
+	last, ok := sequences[key]
+	sequences[key] = event.sequence
+	resynced := false
+	if ok && event.sequence != last+1 {
+		log.Warnf("Missed %d %s events from %s, resyncing",
+			event.sequence-last-1, event.topic, event.host)
+		c.poll(state)
+		resynced = true
+	}
+
+	switch event.topic {
+	case zmqTopicHashBlock, zmqTopicRawBlock:
+		var hash chainhash.Hash
+		if event.topic == zmqTopicHashBlock {
+			if len(event.body) != chainhash.HashSize {
+				log.Warnf("Received invalid %s event from %s",
+					event.topic, event.host)
+				return
+			}
+			hash = zmqEventHash(event.body)
+		} else {
+			var header zcashwire.BlockHeader
+			err := header.Deserialize(bytes.NewReader(event.body))
+			if err != nil {
+				log.Warnf("Received invalid %s event from %s: %v",
+					event.topic, event.host, err)
+				return
+			}
+			hash = header.BlockHash()
+		}
+
+		// Both topics are published for each block, so the second
+		// event finds the block already polled.
+		state.skipped = nil
+		n := len(state.blocks)
+		if n != 0 && state.blocks[n-1].hash == hash {
+			return
+		}
+		if !resynced {
+			c.poll(state)
+		}
+
+	case zmqTopicHashTx:
+		if len(event.body) != chainhash.HashSize {
+			log.Warnf("Received invalid %s event from %s",
+				event.topic, event.host)
+			return
+		}
+		hash := zmqEventHash(event.body)
+		c.handleZMQTx(state, &hash, nil)
+
+	case zmqTopicRawTx:
+		var tx zcashwire.MsgTx
+		err := tx.Deserialize(bytes.NewReader(event.body))
+		if err != nil {
+			log.Warnf("Received invalid %s event from %s: %v",
+				event.topic, event.host, err)
+			return
+		}
+		hash := tx.TxHash()
+		c.handleZMQTx(state, &hash, &tx)
+	}
+}
+
+// zmqEventHash returns the hash published by a hashblock or hashtx event, whose
+// body holds it in the byte order it is displayed in.
+func zmqEventHash(body []byte) chainhash.Hash {
+	var hash chainhash.Hash
+	for i := range hash {
+		hash[i] = body[chainhash.HashSize-1-i]
+	}
+	return hash
+}
+
+// handleZMQTx invokes the notification handlers for a transaction published
+// by a ZMQ host.  zcashd publishes the transactions of connected blocks, after
+// removing them from the memory pool, as well as those accepted to it, so the
+// memory pool is fetched to tell them apart.  It replaces the memory pool seen
+// by the previous poll, and the transactions accepted since then are notified
+// along with the published one, whose events are skipped when they follow.
+func (c *Client) handleZMQTx(state *pollState, hash *chainhash.Hash,
+	tx *zcashwire.MsgTx) {
+
+	if _, ok := state.mempool[*hash]; ok {
+		return
+	}
+	if _, ok := state.skipped[*hash]; ok {
+		return
+	}
+
+	hashes, err := c.GetRawMempool()
+	if err != nil {
+		log.Warnf("Unable to poll the memory pool: %v", err)
+		return
+	}
+	first := state.mempool == nil
+	accepted := state.updateMempool(hashes)
+	if _, ok := state.mempool[*hash]; !ok {
+		log.Tracef("Skipping transaction %v which is not in the "+
+			"memory pool", hash)
+		if state.skipped == nil {
+			state.skipped = make(map[chainhash.Hash]struct{})
+		}
+		state.skipped[*hash] = struct{}{}
+	} else if first {
+		accepted = []*chainhash.Hash{hash}
+	}
+	if len(accepted) == 0 {
+		return
+	}
+
+	for _, acceptedHash := range accepted {
+		if *acceptedHash == *hash {
+			c.notifyTxAccepted(acceptedHash, tx)
+		} else {
+			c.notifyTxAccepted(acceptedHash, nil)
+		}
+	}
+	if c.ntfnHandlers.OnRecvTx != nil {
+		if err := c.pollReceived(state); err != nil {
+			log.Warnf("Unable to poll for received transactions: %v",
+				err)
+		}
+	}
+}
+
+// zmqSubscribeHandler receives the events published by a ZMQ host and sends
+// them to the passed channel.  The connection to the host is reestablished
+// when it is lost, unless automatic reconnection is disabled.  It must be run
+// as a goroutine.
+func (c *Client) zmqSubscribeHandler(host string, events chan<- *zmqEvent) {
+	var retryCount int64
+out:
+	for {
+		conn, err := zmq.Subscribe(host, zmqTopics, zmqDialTimeout)
+		if err != nil {
+			log.Infof("Failed to connect to ZMQ host %s: %v", host, err)
+			if c.config.DisableAutoReconnect {
+				break out
+			}
+
+			// Scale the retry interval by the number of retries so
+			// there is a backoff up to a max of 1 minute.
+			retryCount++
+			scaledInterval := connectionRetryInterval.Nanoseconds() * retryCount
+			scaledDuration := time.Duration(scaledInterval)
+			if scaledDuration > time.Minute {
+				scaledDuration = time.Minute
+			}
+			log.Infof("Retrying connection to ZMQ host %s in %s", host,
+				scaledDuration)
+			select {
+			case <-time.After(scaledDuration):
+				continue out
+			case <-c.shutdown:
+				break out
+			}
+		}
+		retryCount = 0
+		log.Infof("Established connection to ZMQ host %s", host)
+
+		// Close the connection on shutdown to unblock the reads.
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-c.shutdown:
+				conn.Close()
+			case <-done:
+			}
+		}()
+
+		select {
+		case events <- &zmqEvent{host: host, connected: true}:
+			err = c.zmqReadEvents(host, conn, events)
+		case <-c.shutdown:
+		}
+		close(done)
+		conn.Close()
+
+		select {
+		case <-c.shutdown:
+			break out
+		default:
+		}
+		log.Infof("Lost connection to ZMQ host %s: %v", host, err)
+		if c.config.DisableAutoReconnect {
+			break out
+		}
+	}
+	c.wg.Done()
+	log.Tracef("RPC client ZMQ subscriber done for %s", host)
+}
+
+// zmqReadEvents reads the events published by a ZMQ host and sends them to the
+// passed channel until reading fails or the client is shut down.
+func (c *Client) zmqReadEvents(host string, conn *zmq.Conn,
+	events chan<- *zmqEvent) error {
+
+	for {
+		parts, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		// Each event is made of its topic, body, and sequence number.
+		if len(parts) != 3 || len(parts[2]) != 4 {
+			log.Warnf("Received invalid event from ZMQ host %s", host)
+			continue
+		}
+		event := &zmqEvent{
+			host:     host,
+			topic:    string(parts[0]),
+			body:     parts[1],
+			sequence: binary.LittleEndian.Uint32(parts[2]),
+		}
+
+		select {
+		case events <- event:
+		case <-c.shutdown:
+			return ErrClientShutdown
+		}
+	}
+}
@@ -0,0 +1,371 @@
+// Copyright (c) 2016 arithmetric
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zcashrpcclient
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arithmetric/zcashrpcclient/internal/zmq"
+	"github.com/arithmetric/zcashrpcclient/zcashwire"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+)
+
+// zmqTestTx is the transaction the test chain server returns for every
+// getrawtransaction request.  It has a single output of 1000 zatoshis.
+const zmqTestTx = "0100000001000000000000000000000000000000000000000000000000" +
+	"000000000000000000000000000000000001e8030000000000000000000000"
+
+// zmqTestServer is a chain server answering the requests the client issues to
+// resolve the events published by zcashd.
+type zmqTestServer struct {
+	mtx       sync.Mutex
+	chain     []chainhash.Hash
+	prevHash  map[chainhash.Hash]chainhash.Hash
+	height    map[chainhash.Hash]int
+	mempool   []chainhash.Hash
+	mempoolCh chan struct{}
+	polls     int
+}
+
+// newZMQTestServer returns a test chain server whose best chain holds the
+// blocks with the passed labels.
+func newZMQTestServer(labels ...string) *zmqTestServer {
+	s := &zmqTestServer{
+		prevHash:  make(map[chainhash.Hash]chainhash.Hash),
+		height:    make(map[chainhash.Hash]int),
+		mempoolCh: make(chan struct{}, 100),
+	}
+	s.setChain(labels...)
+	return s
+}
+
+// zmqTestHash returns the hash standing for the block or transaction with the
+// passed label.
+func zmqTestHash(label string) chainhash.Hash {
+	return chainhash.DoubleHashH([]byte(label))
+}
+
+// setChain replaces the best chain with the blocks with the passed labels.
+// Blocks which leave the best chain stay known to the server.
+func (s *zmqTestServer) setChain(labels ...string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.chain = s.chain[:0]
+	for _, label := range labels {
+		s.appendBlock(zmqTestHash(label))
+	}
+}
+
+// appendBlock connects the block with the passed hash to the best chain.  It
+// must be called with the mutex held.
+func (s *zmqTestServer) appendBlock(hash chainhash.Hash) {
+	if n := len(s.chain); n > 0 {
+		s.prevHash[hash] = s.chain[n-1]
+	}
+	s.height[hash] = len(s.chain)
+	s.chain = append(s.chain, hash)
+}
+
+// setMempool replaces the memory pool with the transactions with the passed
+// labels.
+func (s *zmqTestServer) setMempool(labels ...string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.mempool = s.mempool[:0]
+	for _, label := range labels {
+		s.mempool = append(s.mempool, zmqTestHash(label))
+	}
+}
+
+// ServeHTTP answers a JSON-RPC request.
+func (s *zmqTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+		ID     interface{}       `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var param interface{}
+	if len(req.Params) != 0 {
+		json.Unmarshal(req.Params[0], &param)
+	}
+
+	s.mtx.Lock()
+	var result interface{}
+	switch req.Method {
+	case "getbestblockhash":
+		result = s.chain[len(s.chain)-1].String()
+		s.polls++
+
+	case "getblockhash":
+		height, _ := param.(float64)
+		result = s.chain[int(height)].String()
+
+	case "getblockheader":
+		hash, _ := chainhash.NewHashFromStr(param.(string))
+		header := map[string]interface{}{
+			"hash":   hash.String(),
+			"height": s.height[*hash],
+			"time":   1500000000 + s.height[*hash],
+		}
+		if prevHash, ok := s.prevHash[*hash]; ok {
+			header["previousblockhash"] = prevHash.String()
+		}
+		result = header
+
+	case "getrawmempool":
+		hashes := make([]string, 0, len(s.mempool))
+		for _, hash := range s.mempool {
+			hashes = append(hashes, hash.String())
+		}
+		result = hashes
+		select {
+		case s.mempoolCh <- struct{}{}:
+		default:
+		}
+
+	case "getrawtransaction":
+		result = zmqTestTx
+	}
+	s.mtx.Unlock()
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"result": result,
+		"error":  nil,
+		"id":     req.ID,
+	})
+}
+
+// zmqTestNotes records the notifications of a client as readable strings.
+type zmqTestNotes struct {
+	labels map[chainhash.Hash]string
+	notes  chan string
+}
+
+// label returns the label of a hash of the test chain server.
+func (n *zmqTestNotes) label(hash *chainhash.Hash) string {
+	if label, ok := n.labels[*hash]; ok {
+		return label
+	}
+	return hash.String()
+}
+
+// handlers returns notification handlers recording to the notes.
+func (n *zmqTestNotes) handlers() *NotificationHandlers {
+	return &NotificationHandlers{
+		OnBlockConnected: func(hash *chainhash.Hash, height int32, t time.Time) {
+			n.notes <- fmt.Sprintf("connected %d %s", height,
+				n.label(hash))
+		},
+		OnBlockDisconnected: func(hash *chainhash.Hash, height int32, t time.Time) {
+			n.notes <- fmt.Sprintf("disconnected %d %s", height,
+				n.label(hash))
+		},
+		OnTxAccepted: func(hash *chainhash.Hash, amount btcutil.Amount) {
+			n.notes <- fmt.Sprintf("accepted %s %d", n.label(hash),
+				int64(amount))
+		},
+	}
+}
+
+// expect checks the next notifications are the passed ones, and that no other
+// follows them shortly.
+func (n *zmqTestNotes) expect(t *testing.T, step string, want ...string) {
+	for _, w := range want {
+		select {
+		case got := <-n.notes:
+			if got != w {
+				t.Fatalf("%s: got notification %q, want %q", step,
+					got, w)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: timed out waiting for %q", step, w)
+		}
+	}
+	select {
+	case got := <-n.notes:
+		t.Fatalf("%s: got unexpected notification %q", step, got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// zmqTestPublish publishes an event of the topic with the passed body and
+// sequence number.
+func zmqTestPublish(t *testing.T, p *zmq.Publisher, topic string, body []byte,
+	sequence uint32) {
+
+	var seq [4]byte
+	binary.LittleEndian.PutUint32(seq[:], sequence)
+	if err := p.Publish([]byte(topic), body, seq[:]); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+}
+
+// zmqTestHashBody returns the body of a hashtx or hashblock event, which holds
+// the hash in the byte order it is displayed in.
+func zmqTestHashBody(hash chainhash.Hash) []byte {
+	body := make([]byte, chainhash.HashSize)
+	for i := range body {
+		body[i] = hash[chainhash.HashSize-1-i]
+	}
+	return body
+}
+
+// waitZMQSubscriber waits until the client subscribed to the publisher and
+// recorded the state of the server, which it polls once connected.
+func waitZMQSubscriber(t *testing.T, p *zmq.Publisher, s *zmqTestServer) {
+	deadline := time.Now().Add(10 * time.Second)
+	for p.Subscribers([]byte(zmqTopicRawTx)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for the client to subscribe")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case <-s.mempoolCh:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the client to poll")
+	}
+}
+
+func TestZMQNotifications(t *testing.T) {
+	raw, err := hex.DecodeString(zmqTestTx)
+	if err != nil {
+		t.Fatalf("hex.DecodeString: %v", err)
+	}
+	var tx zcashwire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	rawHash := tx.TxHash()
+
+	server := newZMQTestServer("genesis", "a1")
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	notes := &zmqTestNotes{
+		labels: map[chainhash.Hash]string{rawHash: "raw"},
+		notes:  make(chan string, 100),
+	}
+	for _, label := range []string{"a2", "b2", "b3", "coinbase",
+		"missed", "gap"} {
+
+		notes.labels[zmqTestHash(label)] = label
+	}
+
+	pub, err := zmq.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	addr := pub.Addr().String()
+	defer func() { pub.Close() }()
+
+	client, err := New(&ConnConfig{
+		Host:         strings.TrimPrefix(httpServer.URL, "http://"),
+		User:         "user",
+		Pass:         "pass",
+		HTTPPostMode: true,
+		DisableTLS:   true,
+		ZMQHosts:     []string{addr},
+	}, notes.handlers())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() {
+		client.Shutdown()
+		client.WaitForShutdown()
+	}()
+	waitZMQSubscriber(t, pub, server)
+
+	// A transaction accepted to the memory pool is notified once although
+	// both its rawtx and hashtx events are published.
+	server.mtx.Lock()
+	server.mempool = []chainhash.Hash{rawHash}
+	server.mtx.Unlock()
+	zmqTestPublish(t, pub, zmqTopicRawTx, raw, 0)
+	zmqTestPublish(t, pub, zmqTopicHashTx, zmqTestHashBody(rawHash), 0)
+	notes.expect(t, "mempool transaction", "accepted raw 1000")
+
+	// The transactions of a connected block are published before the
+	// block, once they left the memory pool, and are not notified as
+	// accepted.
+	server.setChain("genesis", "a1", "a2")
+	server.setMempool()
+	zmqTestPublish(t, pub, zmqTopicHashTx,
+		zmqTestHashBody(zmqTestHash("coinbase")), 1)
+	zmqTestPublish(t, pub, zmqTopicRawTx, raw, 1)
+	zmqTestPublish(t, pub, zmqTopicHashTx,
+		zmqTestHashBody(zmqTestHash("coinbase")), 2)
+	zmqTestPublish(t, pub, zmqTopicHashBlock,
+		zmqTestHashBody(zmqTestHash("a2")), 0)
+	notes.expect(t, "connected block", "connected 2 a2")
+
+	// A block published on both the hashblock and rawblock topics is only
+	// polled once.
+	header := zcashwire.BlockHeader{
+		Version:   4,
+		PrevBlock: zmqTestHash("a2"),
+		Timestamp: time.Unix(1500000003, 0),
+	}
+	var rawBlock bytes.Buffer
+	if err := header.Serialize(&rawBlock); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	rawBlock.WriteByte(0)
+	blockHash := header.BlockHash()
+	notes.labels[blockHash] = "a3"
+	server.mtx.Lock()
+	server.appendBlock(blockHash)
+	server.polls = 0
+	server.mtx.Unlock()
+	zmqTestPublish(t, pub, zmqTopicHashBlock, zmqTestHashBody(blockHash), 1)
+	zmqTestPublish(t, pub, zmqTopicRawBlock, rawBlock.Bytes(), 0)
+	notes.expect(t, "block topics", "connected 3 a3")
+	server.mtx.Lock()
+	polls := server.polls
+	server.mtx.Unlock()
+	if polls != 1 {
+		t.Fatalf("block topics: got %d polls, want 1", polls)
+	}
+
+	// Skipping sequence numbers resyncs the memory pool, which notifies
+	// the transactions whose events were missed.
+	server.setMempool("missed", "gap")
+	zmqTestPublish(t, pub, zmqTopicHashTx,
+		zmqTestHashBody(zmqTestHash("gap")), 5)
+	notes.expect(t, "sequence gap", "accepted missed 1000",
+		"accepted gap 1000")
+
+	// The events published while the client is disconnected are missed,
+	// so it resyncs once reconnected.
+	pub.Close()
+	server.setChain("genesis", "a1", "b2", "b3")
+	pub, err = zmq.Listen(addr)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	waitZMQSubscriber(t, pub, server)
+	notes.expect(t, "reconnect", "disconnected 3 a3", "disconnected 2 a2",
+		"connected 2 b2", "connected 3 b3")
+
+	// The sequence numbers of the new connection start over.
+	zmqTestPublish(t, pub, zmqTopicHashBlock,
+		zmqTestHashBody(zmqTestHash("b3")), 0)
+	notes.expect(t, "after reconnect")
+}